@@ -0,0 +1,105 @@
+package aead
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Authenticated encryption of wallet files, payment IDs and memos with keys of crypto.AESKeySize bytes.
+// A sealed message is nonce || ciphertext || tag, the nonce is random and the tag has 16 bytes.
+// DeriveKey expands a shared secret with HKDF-SHA256, for instance the ECDH derivation of
+// SharedSecret, which is the cryptonote key derivation 8*r*A used for the outputs.
+
+// Suite is the AEAD scheme of a key
+type Suite byte
+
+const (
+	AES256GCM        Suite = 1
+	ChaCha20Poly1305 Suite = 2
+
+	NonceSize = 12
+	TagSize   = 16
+	Overhead  = NonceSize + TagSize
+)
+
+func (suite Suite) String() string {
+	switch suite {
+	case AES256GCM:
+		return "AES-256-GCM"
+	case ChaCha20Poly1305:
+		return "ChaCha20-Poly1305"
+	}
+	return "unknown"
+}
+
+// New returns the AEAD of the suite, the key has crypto.AESKeySize bytes
+func New(suite Suite, key []byte) (cipher.AEAD, error) {
+	if len(key) != crypto.AESKeySize {
+		return nil, errors.New("New invalid key size")
+	}
+	switch suite {
+	case AES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case ChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	}
+	return nil, errors.New("New unknown suite")
+}
+
+// Seal encrypts and authenticates plaintext and additionalData under a random nonce
+func Seal(suite Suite, key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	return SealWithRand(rand.Reader, suite, key, plaintext, additionalData)
+}
+
+// SealWithRand is Seal with the nonce read from rng
+func SealWithRand(rng io.Reader, suite Suite, key []byte, plaintext []byte, additionalData []byte) ([]byte, error) {
+	c, err := New(suite, key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := crypto.RandBytesFrom(rng, NonceSize)
+	if err != nil {
+		return nil, err
+	}
+	return c.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open checks and decrypts a message of Seal
+func Open(suite Suite, key []byte, sealed []byte, additionalData []byte) ([]byte, error) {
+	c, err := New(suite, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < Overhead {
+		return nil, errors.New("Open message too short")
+	}
+	plaintext, err := c.Open(nil, sealed[:NonceSize], sealed[NonceSize:], additionalData)
+	if err != nil {
+		return nil, errors.New("Open authentication failed")
+	}
+	return plaintext, nil
+}
+
+// DeriveKey returns the key of HKDF-SHA256 with the secret, salt and info
+func DeriveKey(secret []byte, salt []byte, info []byte) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("DeriveKey secret is empty")
+	}
+	key := make([]byte, crypto.AESKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,64 @@
+package aead
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 5869 test case 1, the first crypto.AESKeySize bytes of the output
+func TestDeriveKey(t *testing.T) {
+	secret, _ := hex.DecodeString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt, _ := hex.DecodeString("000102030405060708090a0b0c")
+	info, _ := hex.DecodeString("f0f1f2f3f4f5f6f7f8f9")
+	key, err := DeriveKey(secret, salt, info)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf", hex.EncodeToString(key))
+
+	_, err = DeriveKey(nil, salt, info)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestSealOpen(t *testing.T) {
+	key := crypto.RandBytes(crypto.AESKeySize)
+	plaintext := []byte("payment id 0123456789abcdef")
+	data := []byte("output 3")
+	for _, suite := range []Suite{AES256GCM, ChaCha20Poly1305} {
+		sealed, err := Seal(suite, key, plaintext, data)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, len(plaintext)+Overhead, len(sealed))
+
+		res, err := Open(suite, key, sealed, data)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, plaintext, res)
+
+		// the nonce is random
+		other, _ := Seal(suite, key, plaintext, data)
+		assert.NotEqual(t, sealed, other)
+
+		_, err = Open(suite, key, sealed, []byte("output 4"))
+		assert.NotEqual(t, nil, err)
+		_, err = Open(suite, crypto.RandBytes(crypto.AESKeySize), sealed, data)
+		assert.NotEqual(t, nil, err)
+		for _, i := range []int{0, NonceSize, len(sealed) - 1} {
+			tampered := append([]byte{}, sealed...)
+			tampered[i] ^= 1
+			_, err = Open(suite, key, tampered, data)
+			assert.NotEqual(t, nil, err)
+		}
+		_, err = Open(suite, key, sealed[:Overhead-1], data)
+		assert.NotEqual(t, nil, err)
+	}
+
+	// the suites differ
+	sealed, _ := Seal(AES256GCM, key, plaintext, nil)
+	_, err := Open(ChaCha20Poly1305, key, sealed, nil)
+	assert.NotEqual(t, nil, err)
+
+	_, err = Seal(AES256GCM, key[:16], plaintext, nil)
+	assert.NotEqual(t, nil, err)
+	_, err = Seal(Suite(0), key, plaintext, nil)
+	assert.NotEqual(t, nil, err)
+}
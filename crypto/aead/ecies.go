@@ -0,0 +1,102 @@
+package aead
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// ECIES over the cryptonote key derivation, to attach encrypted memos to outputs.
+// The sender picks r, sends R = r*G, and both sides compute the derivation D = 8*r*A = 8*a*R.
+// key = HKDF-SHA256(secret = D, salt = R || A, info = "aead_ecies" || suite)
+// The ciphertext is suite || R || nonce || ciphertext || tag.
+// A transaction can reuse its public key as R by deriving the key with SharedSecret and DeriveKey itself.
+
+const (
+	cStringECIES = "aead_ecies"
+	headerSize   = 1 + crypto.Ed25519KeySize
+)
+
+// SharedSecret returns the key derivation 8*priv*pub of curve25519.CheckedKeyDerivation
+func SharedSecret(pub *crypto.Point, priv *crypto.Scalar) ([]byte, error) {
+	if pub == nil || priv == nil {
+		return nil, errors.New("SharedSecret nil key")
+	}
+	var pubKey, privKey C25519.Key
+	copy(pubKey[:], pub.ToBytes())
+	copy(privKey[:], priv.ToBytes())
+	derivation, err := C25519.CheckedKeyDerivation(&pubKey, &privKey)
+	if err != nil {
+		return nil, err
+	}
+	if derivation == C25519.Identity {
+		return nil, errors.New("SharedSecret the public key has a small order")
+	}
+	return derivation[:], nil
+}
+
+func eciesKey(suite Suite, derivation []byte, r *crypto.Point, pub *crypto.Point) ([]byte, error) {
+	salt := crypto.AppendPointsToBytesArray(nil, []*crypto.Point{r, pub})
+	return DeriveKey(derivation, salt, append([]byte(cStringECIES), byte(suite)))
+}
+
+// Encrypt encrypts plaintext to pubKey with AES-256-GCM
+func Encrypt(pubKey *crypto.Point, plaintext []byte) ([]byte, error) {
+	return EncryptWithRand(rand.Reader, AES256GCM, pubKey, plaintext, nil)
+}
+
+// EncryptWithRand encrypts plaintext to pubKey with the suite and authenticates additionalData,
+// the ephemeral key and the nonce are read from rng
+func EncryptWithRand(rng io.Reader, suite Suite, pubKey *crypto.Point, plaintext []byte, additionalData []byte) ([]byte, error) {
+	r, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
+	derivation, err := SharedSecret(pubKey, r)
+	if err != nil {
+		return nil, err
+	}
+	rPoint := new(crypto.Point).ScalarMultBase(r)
+	key, err := eciesKey(suite, derivation, rPoint, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := SealWithRand(rng, suite, key, plaintext, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, 0, headerSize+len(sealed))
+	res = append(res, byte(suite))
+	res = append(res, rPoint.ToBytes()...)
+	return append(res, sealed...), nil
+}
+
+// Decrypt decrypts a ciphertext of Encrypt with the private key
+func Decrypt(privKey *crypto.Scalar, ciphertext []byte) ([]byte, error) {
+	return DecryptWithData(privKey, ciphertext, nil)
+}
+
+// DecryptWithData decrypts a ciphertext of EncryptWithRand and checks additionalData
+func DecryptWithData(privKey *crypto.Scalar, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < headerSize+Overhead {
+		return nil, errors.New("Decrypt ciphertext too short")
+	}
+	suite := Suite(ciphertext[0])
+	rPoint, err := new(crypto.Point).FromBytes(ciphertext[1:headerSize])
+	if err != nil {
+		return nil, err
+	}
+	derivation, err := SharedSecret(rPoint, privKey)
+	if err != nil {
+		return nil, err
+	}
+	key, err := eciesKey(suite, derivation, rPoint, new(crypto.Point).ScalarMultBase(privKey))
+	if err != nil {
+		return nil, err
+	}
+	return Open(suite, key, ciphertext[headerSize:], additionalData)
+}
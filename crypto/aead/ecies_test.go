@@ -0,0 +1,76 @@
+package aead
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedSecret(t *testing.T) {
+	a := crypto.RandomScalar()
+	r := crypto.RandomScalar()
+	s1, err := SharedSecret(new(crypto.Point).ScalarMultBase(a), r)
+	assert.Equal(t, nil, err)
+	s2, err := SharedSecret(new(crypto.Point).ScalarMultBase(r), a)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, s1, s2)
+
+	// 8*r*P is the identity for the points of small order
+	_, err = SharedSecret(new(crypto.Point).Identity(), r)
+	assert.NotEqual(t, nil, err)
+	_, err = SharedSecret(new(crypto.Point).ScalarMultBase(a), new(crypto.Scalar).FromUint64(0))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestECIES(t *testing.T) {
+	sk := crypto.RandomScalar()
+	pk := new(crypto.Point).ScalarMultBase(sk)
+	memo := []byte("invoice 42")
+
+	ct, err := Encrypt(pk, memo)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, headerSize+len(memo)+Overhead, len(ct))
+	res, err := Decrypt(sk, ct)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, memo, res)
+
+	_, err = Decrypt(crypto.RandomScalar(), ct)
+	assert.NotEqual(t, nil, err)
+
+	data := []byte("tx hash")
+	for _, suite := range []Suite{AES256GCM, ChaCha20Poly1305} {
+		ct, err := EncryptWithRand(rand.Reader, suite, pk, memo, data)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, byte(suite), ct[0])
+		res, err := DecryptWithData(sk, ct, data)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, memo, res)
+
+		_, err = Decrypt(sk, ct)
+		assert.NotEqual(t, nil, err)
+
+		// another ephemeral key or suite does not decrypt
+		other, _ := EncryptWithRand(rand.Reader, suite, pk, memo, data)
+		tampered := append(append([]byte{}, ct[:headerSize]...), other[headerSize:]...)
+		_, err = DecryptWithData(sk, tampered, data)
+		assert.NotEqual(t, nil, err)
+		tampered = append([]byte{}, ct...)
+		tampered[0] = byte(AES256GCM + ChaCha20Poly1305 - suite)
+		_, err = DecryptWithData(sk, tampered, data)
+		assert.NotEqual(t, nil, err)
+	}
+
+	// an empty memo is valid, a truncated ciphertext is not
+	ct, err = Encrypt(pk, nil)
+	assert.Equal(t, nil, err)
+	res, err = Decrypt(sk, ct)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(res))
+	_, err = Decrypt(sk, ct[:len(ct)-1])
+	assert.NotEqual(t, nil, err)
+
+	_, err = Encrypt(new(crypto.Point).Identity(), memo)
+	assert.NotEqual(t, nil, err)
+}
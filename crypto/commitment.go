@@ -0,0 +1,121 @@
+package crypto
+
+import "errors"
+
+// Commitment is a Pedersen commitment value*G + blind*H to a uint64 value,
+// as made for the outputs of a transaction and proven in range by the bullet proof
+type Commitment struct {
+	point Point
+}
+
+// Commit returns the commitment to value with the blinding factor blind
+func Commit(value uint64, blind *Scalar) *Commitment {
+	c := new(Commitment)
+	c.point.AddPedersenBase(new(Scalar).FromUint64(value), blind)
+	return c
+}
+
+// NewCommitmentFromPoint wraps a commitment received as a point, such as a bullet proof commitment
+func NewCommitmentFromPoint(p *Point) *Commitment {
+	c := new(Commitment)
+	c.point.Set(p)
+	return c
+}
+
+func (c Commitment) Point() *Point {
+	return new(Point).Set(&c.point)
+}
+
+func (c *Commitment) Set(a *Commitment) *Commitment {
+	if c == nil {
+		c = new(Commitment)
+	}
+	c.point = a.point
+	return c
+}
+
+func (c Commitment) ToBytes() []byte {
+	return c.point.ToBytes()
+}
+
+func (c *Commitment) FromBytes(b []byte) (*Commitment, error) {
+	if c == nil {
+		c = new(Commitment)
+	}
+	if _, err := c.point.FromBytes(b); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Add returns the commitment to the sum of the values with the sum of the blinds
+func (c *Commitment) Add(a, b *Commitment) *Commitment {
+	if c == nil {
+		c = new(Commitment)
+	}
+	c.point.Add(&a.point, &b.point)
+	return c
+}
+
+// Sub returns the commitment to the difference of the values with the difference of the blinds
+func (c *Commitment) Sub(a, b *Commitment) *Commitment {
+	if c == nil {
+		c = new(Commitment)
+	}
+	c.point.Sub(&a.point, &b.point)
+	return c
+}
+
+// Open checks that c is the commitment to value with the blinding factor blind
+func (c Commitment) Open(value uint64, blind *Scalar) bool {
+	return IsPointEqual(&c.point, &Commit(value, blind).point)
+}
+
+func IsCommitmentEqual(a, b *Commitment) bool {
+	return IsPointEqual(&a.point, &b.point)
+}
+
+// sumCommitments returns the sum of the commitments, the identity if there is none
+func sumCommitments(cs []*Commitment) *Commitment {
+	sum := new(Commitment)
+	sum.point.Identity()
+	for _, c := range cs {
+		sum.Add(sum, c)
+	}
+	return sum
+}
+
+// VerifyBalance checks that the inputs commit to the same amount as the outputs plus the fee,
+// sum(inputs) == sum(outputs) + fee*G, which holds when the values and the blinds both balance.
+// The fee is public so it is committed with a zero blind
+func VerifyBalance(inputs []*Commitment, outputs []*Commitment, fee uint64) bool {
+	if len(inputs) == 0 || len(outputs) == 0 {
+		return false
+	}
+	for _, c := range append(append([]*Commitment{}, inputs...), outputs...) {
+		if c == nil {
+			return false
+		}
+	}
+
+	spent := sumCommitments(outputs)
+	spent.Add(spent, Commit(fee, new(Scalar).FromUint64(0)))
+	return IsCommitmentEqual(sumCommitments(inputs), spent)
+}
+
+// BalanceBlind returns the blinding factor of the last output, such that
+// the blinds of the outputs sum to the blinds of the inputs.
+// outputBlinds are the blinds of the other outputs
+func BalanceBlind(inputBlinds []*Scalar, outputBlinds []*Scalar) (*Scalar, error) {
+	if len(inputBlinds) == 0 {
+		return nil, errors.New("BalanceBlind input blinds must not be empty")
+	}
+	blind := new(Scalar).FromUint64(0)
+	for _, b := range inputBlinds {
+		blind.Add(blind, b)
+	}
+	for _, b := range outputBlinds {
+		blind.Sub(blind, b)
+	}
+	return blind, nil
+}
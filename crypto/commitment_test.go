@@ -0,0 +1,58 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitment_Open(t *testing.T) {
+	blind := RandomScalar()
+	c := Commit(1000, blind)
+
+	assert.Equal(t, true, c.Open(1000, blind))
+	assert.Equal(t, false, c.Open(1001, blind))
+	assert.Equal(t, false, c.Open(1000, RandomScalar()))
+	assert.Equal(t, true, IsPointEqual(c.Point(), new(Point).AddPedersenBase(new(Scalar).FromUint64(1000), blind)))
+
+	c2, err := new(Commitment).FromBytes(c.ToBytes())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, IsCommitmentEqual(c, c2))
+}
+
+func TestCommitment_AddSub(t *testing.T) {
+	blind1 := RandomScalar()
+	blind2 := RandomScalar()
+	c1 := Commit(300, blind1)
+	c2 := Commit(200, blind2)
+
+	sum := new(Commitment).Add(c1, c2)
+	assert.Equal(t, true, sum.Open(500, new(Scalar).Add(blind1, blind2)))
+
+	diff := new(Commitment).Sub(c1, c2)
+	assert.Equal(t, true, diff.Open(100, new(Scalar).Sub(blind1, blind2)))
+}
+
+func TestVerifyBalance(t *testing.T) {
+	inputBlinds := []*Scalar{RandomScalar(), RandomScalar()}
+	inputs := []*Commitment{Commit(700, inputBlinds[0]), Commit(400, inputBlinds[1])}
+
+	// 700 + 400 = 600 + 490 + fee 10
+	outputBlinds := []*Scalar{RandomScalar()}
+	lastBlind, err := BalanceBlind(inputBlinds, outputBlinds)
+	assert.Equal(t, nil, err)
+	outputBlinds = append(outputBlinds, lastBlind)
+	outputs := []*Commitment{Commit(600, outputBlinds[0]), Commit(490, outputBlinds[1])}
+
+	assert.Equal(t, true, VerifyBalance(inputs, outputs, 10))
+	assert.Equal(t, false, VerifyBalance(inputs, outputs, 11))
+	assert.Equal(t, false, VerifyBalance(inputs, outputs[:1], 10))
+	assert.Equal(t, false, VerifyBalance(nil, outputs, 10))
+
+	// values balance but the blinds do not
+	outputs[1] = Commit(490, RandomScalar())
+	assert.Equal(t, false, VerifyBalance(inputs, outputs, 10))
+
+	_, err = BalanceBlind(nil, outputBlinds)
+	assert.NotEqual(t, nil, err)
+}
@@ -2,12 +2,16 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build amd64
+// +build amd64 arm64 ppc64 ppc64le mips64 mips64le riscv64 s390x
+// +build !fe32
 
 package curve25519
 
 // Field arithmetic in radix 2^51 representation. This code is a port of the
 // public domain amd64-51-30k version of ed25519 from SUPERCOP.
+// FeMul and FeSquare are implemented in assembly on amd64, and with math/bits
+// on the other 64-bit platforms or with the purego tag.
+// The fe32 tag selects the 32-bit ref10 field on any platform.
 
 // FieldElement represents an element of the field GF(2^255-19). An element t
 // represents the integer t[0] + t[1]*2^51 + t[2]*2^102 + t[3]*2^153 +
@@ -61,14 +65,6 @@ func FeNeg(out, a *FieldElement) {
 	FeSub(out, &t, a)
 }
 
-//go:noescape
-// FeMul calculates out = a * b.
-func FeMul(out, a, b *FieldElement)
-
-//go:noescape
-// FeSquare calculates out = a * a.
-func FeSquare(out, a *FieldElement)
-
 // FeSquare2 calculates out = 2 * a * a.
 func FeSquare2(out, a *FieldElement) {
 	FeSquare(out, a)
@@ -0,0 +1,15 @@
+// Copyright (c) 2017 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build !purego,!fe32
+
+package curve25519
+
+//go:noescape
+// FeMul calculates out = a * b.
+func FeMul(out, a, b *FieldElement)
+
+//go:noescape
+// FeSquare calculates out = a * a.
+func FeSquare(out, a *FieldElement)
@@ -0,0 +1,142 @@
+// +build amd64 arm64 ppc64 ppc64le mips64 mips64le riscv64 s390x
+// +build !amd64 purego
+// +build !fe32
+
+package curve25519
+
+import "math/bits"
+
+// Portable FeMul and FeSquare for the radix 2^51 field, the 64x64 -> 128 bit
+// products are computed with bits.Mul64 which is an intrinsic on 64-bit platforms.
+// As in the amd64 assembly, the input limbs must be below 2^54.
+
+// uint128 holds a 128 bit product as two 64 bit words
+type uint128 struct {
+	lo, hi uint64
+}
+
+func mul64(a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	return uint128{lo, hi}
+}
+
+// addMul64 returns v + a * b
+func addMul64(v uint128, a, b uint64) uint128 {
+	hi, lo := bits.Mul64(a, b)
+	lo, c := bits.Add64(lo, v.lo, 0)
+	hi, _ = bits.Add64(hi, v.hi, c)
+	return uint128{lo, hi}
+}
+
+// add64 returns v + a
+func add64(v uint128, a uint64) uint128 {
+	lo, c := bits.Add64(v.lo, a, 0)
+	return uint128{lo, v.hi + c}
+}
+
+// shiftRightBy51 returns v >> 51, v must be below 2^115
+func shiftRightBy51(v uint128) uint64 {
+	return (v.hi << 13) | (v.lo >> 51)
+}
+
+// FeMul calculates out = a * b.
+func FeMul(out, a, b *FieldElement) {
+	a0, a1, a2, a3, a4 := a[0], a[1], a[2], a[3], a[4]
+	b0, b1, b2, b3, b4 := b[0], b[1], b[2], b[3], b[4]
+
+	// 2^255 = 19 mod p, the products above 2^255 are folded back multiplied by 19
+	b1_19 := b1 * 19
+	b2_19 := b2 * 19
+	b3_19 := b3 * 19
+	b4_19 := b4 * 19
+
+	r0 := mul64(a0, b0)
+	r0 = addMul64(r0, a1, b4_19)
+	r0 = addMul64(r0, a2, b3_19)
+	r0 = addMul64(r0, a3, b2_19)
+	r0 = addMul64(r0, a4, b1_19)
+
+	r1 := mul64(a0, b1)
+	r1 = addMul64(r1, a1, b0)
+	r1 = addMul64(r1, a2, b4_19)
+	r1 = addMul64(r1, a3, b3_19)
+	r1 = addMul64(r1, a4, b2_19)
+
+	r2 := mul64(a0, b2)
+	r2 = addMul64(r2, a1, b1)
+	r2 = addMul64(r2, a2, b0)
+	r2 = addMul64(r2, a3, b4_19)
+	r2 = addMul64(r2, a4, b3_19)
+
+	r3 := mul64(a0, b3)
+	r3 = addMul64(r3, a1, b2)
+	r3 = addMul64(r3, a2, b1)
+	r3 = addMul64(r3, a3, b0)
+	r3 = addMul64(r3, a4, b4_19)
+
+	r4 := mul64(a0, b4)
+	r4 = addMul64(r4, a1, b3)
+	r4 = addMul64(r4, a2, b2)
+	r4 = addMul64(r4, a3, b1)
+	r4 = addMul64(r4, a4, b0)
+
+	feCarryWide(out, r0, r1, r2, r3, r4)
+}
+
+// FeSquare calculates out = a * a.
+func FeSquare(out, a *FieldElement) {
+	a0, a1, a2, a3, a4 := a[0], a[1], a[2], a[3], a[4]
+
+	a0_2 := a0 * 2
+	a1_2 := a1 * 2
+	a3_19 := a3 * 19
+	a4_19 := a4 * 19
+	a3_38 := a3 * 38
+	a4_38 := a4 * 38
+
+	// r0 = a0^2 + 38 (a1 a4 + a2 a3)
+	r0 := mul64(a0, a0)
+	r0 = addMul64(r0, a1, a4_38)
+	r0 = addMul64(r0, a2, a3_38)
+
+	// r1 = 2 a0 a1 + 38 a2 a4 + 19 a3^2
+	r1 := mul64(a0_2, a1)
+	r1 = addMul64(r1, a2, a4_38)
+	r1 = addMul64(r1, a3, a3_19)
+
+	// r2 = 2 a0 a2 + a1^2 + 38 a3 a4
+	r2 := mul64(a0_2, a2)
+	r2 = addMul64(r2, a1, a1)
+	r2 = addMul64(r2, a3, a4_38)
+
+	// r3 = 2 a0 a3 + 2 a1 a2 + 19 a4^2
+	r3 := mul64(a0_2, a3)
+	r3 = addMul64(r3, a1_2, a2)
+	r3 = addMul64(r3, a4, a4_19)
+
+	// r4 = 2 a0 a4 + 2 a1 a3 + a2^2
+	r4 := mul64(a0_2, a4)
+	r4 = addMul64(r4, a1_2, a3)
+	r4 = addMul64(r4, a2, a2)
+
+	feCarryWide(out, r0, r1, r2, r3, r4)
+}
+
+// feCarryWide reduces the 128 bit coefficients r0..r4, each below 2^115,
+// to limbs of at most 51 bits (52 bits for out[1])
+func feCarryWide(out *FieldElement, r0, r1, r2, r3, r4 uint128) {
+	r1 = add64(r1, shiftRightBy51(r0))
+	r2 = add64(r2, shiftRightBy51(r1))
+	r3 = add64(r3, shiftRightBy51(r2))
+	r4 = add64(r4, shiftRightBy51(r3))
+
+	// the carry of r4 can reach 2^64, so it is multiplied by 19 in 128 bits
+	c := mul64(shiftRightBy51(r4), 19)
+	c = add64(c, r0.lo&maskLow51Bits)
+
+	out[0] = c.lo & maskLow51Bits
+	out[1] = r1.lo&maskLow51Bits + shiftRightBy51(c)
+	out[2] = r2.lo & maskLow51Bits
+	out[3] = r3.lo & maskLow51Bits
+	out[4] = r4.lo & maskLow51Bits
+}
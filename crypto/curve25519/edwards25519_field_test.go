@@ -0,0 +1,93 @@
+package curve25519
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+var fieldP, _ = new(big.Int).SetString("57896044618658097711785492504343953926634992332820282019728792003956564819949", 10)
+
+func randomFieldElement(t *testing.T) (*FieldElement, *big.Int) {
+	var k Key
+	if _, err := rand.Read(k[:]); err != nil {
+		t.Fatal(err)
+	}
+	k[31] &= 0x7f
+
+	fe := new(FieldElement)
+	FeFromBytes(fe, &k)
+	return fe, keyToBig(&k)
+}
+
+func keyToBig(k *Key) *big.Int {
+	var be [32]byte
+	for i := range k {
+		be[31-i] = k[i]
+	}
+	return new(big.Int).Mod(new(big.Int).SetBytes(be[:]), fieldP)
+}
+
+func feToBig(fe *FieldElement) *big.Int {
+	var k Key
+	FeToBytes(&k, fe)
+	return keyToBig(&k)
+}
+
+// the field is checked against math/big, whichever implementation is selected by the build tags
+func TestFieldElement_MulSquare(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		a, aBig := randomFieldElement(t)
+		b, bBig := randomFieldElement(t)
+
+		// unreduced sums as inputs, as done by the group operations
+		var aa, bb FieldElement
+		FeAdd(&aa, a, a)
+		FeAdd(&bb, b, a)
+		aaBig := new(big.Int).Add(aBig, aBig)
+		bbBig := new(big.Int).Add(bBig, aBig)
+
+		var res FieldElement
+		FeMul(&res, &aa, &bb)
+		expected := new(big.Int).Mul(aaBig, bbBig)
+		if feToBig(&res).Cmp(expected.Mod(expected, fieldP)) != 0 {
+			t.Fatalf("expected FeMul to match math/big")
+		}
+
+		FeSquare(&res, &bb)
+		expected = new(big.Int).Mul(bbBig, bbBig)
+		if feToBig(&res).Cmp(expected.Mod(expected, fieldP)) != 0 {
+			t.Fatalf("expected FeSquare to match math/big")
+		}
+
+		FeSquare2(&res, a)
+		expected = new(big.Int).Mul(aBig, aBig)
+		expected.Lsh(expected, 1)
+		if feToBig(&res).Cmp(expected.Mod(expected, fieldP)) != 0 {
+			t.Fatalf("expected FeSquare2 to match math/big")
+		}
+
+		FeSub(&res, a, b)
+		expected = new(big.Int).Sub(aBig, bBig)
+		if feToBig(&res).Cmp(expected.Mod(expected, fieldP)) != 0 {
+			t.Fatalf("expected FeSub to match math/big")
+		}
+
+		if aBig.Sign() != 0 {
+			FeInvert(&res, a)
+			expected = new(big.Int).ModInverse(aBig, fieldP)
+			if feToBig(&res).Cmp(expected) != 0 {
+				t.Fatalf("expected FeInvert to match math/big")
+			}
+		}
+	}
+}
+
+func BenchmarkFeMul(b *testing.B) {
+	var x, y FieldElement
+	FeOne(&x)
+	FeAdd(&y, &x, &x)
+	for i := 0; i < b.N; i++ {
+		FeMul(&x, &x, &y)
+	}
+}
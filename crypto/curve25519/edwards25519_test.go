@@ -142,7 +142,9 @@ func TestMultiScalarMultKey(t *testing.T) {
 		}
 
 		resultPrime := MultiScalarMultKey(pointLs, scalarLs)
-		ok := subtle.ConstantTimeCompare(res.MarshalText(), resultPrime.MarshalText()) == 1
+		resText, _ := res.MarshalText()
+		resultPrimeText, _ := resultPrime.MarshalText()
+		ok := subtle.ConstantTimeCompare(resText, resultPrimeText) == 1
 		if !ok {
 			t.Fatalf("expected Multi Scalar Mul correct !")
 		}
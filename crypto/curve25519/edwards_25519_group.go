@@ -443,6 +443,51 @@ func GeDoubleScalarMultVartime(r *ProjectiveGroupElement, a *Key, A *ExtendedGro
 	GeDoubleScalarMultPrecompVartime2(r, a, &Ai, b, &GBASE_Cached)
 }
 
+// GeDoubleScalarMultBaseVartime sets r = a*G + b*A in variable time.
+// Both scalars are recoded by slide into width 5 NAF, the odd multiples of G
+// are taken from the affine table bi, so they are added with mixed additions.
+// It must only be used when a, b and A are public, as in signature verification
+func GeDoubleScalarMultBaseVartime(r *ProjectiveGroupElement, a *Key, A *ExtendedGroupElement, b *Key) {
+	var aSlide, bSlide [256]int8
+	var Ai [8]CachedGroupElement // A,3A,5A,7A,9A,11A,13A,15A
+	var t CompletedGroupElement
+	var u ExtendedGroupElement
+	var i int
+
+	slide(&aSlide, a)
+	slide(&bSlide, b)
+	GePrecompute(&Ai, A)
+
+	r.Zero()
+	for i = 255; i >= 0; i-- {
+		if aSlide[i] != 0 || bSlide[i] != 0 {
+			break
+		}
+	}
+
+	for ; i >= 0; i-- {
+		r.Double(&t)
+
+		if aSlide[i] > 0 {
+			t.ToExtended(&u)
+			geMixedAdd(&t, &u, &bi[aSlide[i]/2])
+		} else if aSlide[i] < 0 {
+			t.ToExtended(&u)
+			geMixedSub(&t, &u, &bi[(-aSlide[i])/2])
+		}
+
+		if bSlide[i] > 0 {
+			t.ToExtended(&u)
+			geAdd(&t, &u, &Ai[bSlide[i]/2])
+		} else if bSlide[i] < 0 {
+			t.ToExtended(&u)
+			geSub(&t, &u, &Ai[(-bSlide[i])/2])
+		}
+
+		t.ToProjective(r)
+	}
+}
+
 // GeDoubleScalarMultVartime sets r = a*A + b*B
 // where a = a[0]+256*a[1]+...+256^31 a[31].
 // and b = b[0]+256*b[1]+...+256^31 b[31].
@@ -519,14 +564,14 @@ func PreComputedGroupElementCMove(t, u *PreComputedGroupElement, b int32) {
 	FeCMove(&t.xy2d, &u.xy2d, b)
 }
 
-func selectPoint(t *PreComputedGroupElement, pos int32, b int32) {
+func selectPoint(t *PreComputedGroupElement, row *[8]PreComputedGroupElement, b int32) {
 	var minusT PreComputedGroupElement
 	bNegative := negative(b)
 	bAbs := b - (((-bNegative) & b) << 1)
 
 	t.Zero()
 	for i := int32(0); i < 8; i++ {
-		PreComputedGroupElementCMove(t, &base[pos][i], equal(bAbs, i+1))
+		PreComputedGroupElementCMove(t, &row[i], equal(bAbs, i+1))
 	}
 	FeCopy(&minusT.yPlusX, &t.yMinusX)
 	FeCopy(&minusT.yMinusX, &t.yPlusX)
@@ -541,6 +586,15 @@ func selectPoint(t *PreComputedGroupElement, pos int32, b int32) {
 // Preconditions:
 //   a[31] <= 127
 func GeScalarMultBase(h *ExtendedGroupElement, a *Key) {
+	GeScalarMultFixedBase(h, a, (*FIXED_BASE_TABLE)(&base))
+}
+
+// GeScalarMultFixedBase computes h = a*A in constant time, where table has
+// been filled for A by GenFixedBaseTable.
+//
+// Preconditions:
+//   a[31] <= 127
+func GeScalarMultFixedBase(h *ExtendedGroupElement, a *Key, table *FIXED_BASE_TABLE) {
 	var e [64]int8
 
 	for i, v := range a {
@@ -563,7 +617,7 @@ func GeScalarMultBase(h *ExtendedGroupElement, a *Key) {
 	var t PreComputedGroupElement
 	var r CompletedGroupElement
 	for i := int32(1); i < 64; i += 2 {
-		selectPoint(&t, i/2, int32(e[i]))
+		selectPoint(&t, &table[i/2], int32(e[i]))
 		geMixedAdd(&r, h, &t)
 		r.ToExtended(h)
 	}
@@ -580,7 +634,7 @@ func GeScalarMultBase(h *ExtendedGroupElement, a *Key) {
 	r.ToExtended(h)
 
 	for i := int32(0); i < 64; i += 2 {
-		selectPoint(&t, i/2, int32(e[i]))
+		selectPoint(&t, &table[i/2], int32(e[i]))
 		geMixedAdd(&r, h, &t)
 		r.ToExtended(h)
 	}
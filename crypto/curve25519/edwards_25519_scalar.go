@@ -2283,3 +2283,64 @@ func ScIsZero(s *Key) bool {
 		s[18]|s[19]|s[20]|s[21]|s[22]|s[23]|s[24]|s[25]|s[26]|
 		s[27]|s[28]|s[29]|s[30]|s[31])-1)>>8)+1 == 0
 }
+
+// scSquareMultiply sets y = y^(2^squarings) * x mod l.
+func scSquareMultiply(y *Key, squarings int, x *Key) {
+	for i := 0; i < squarings; i++ {
+		ScMul(y, y, y)
+	}
+	ScMul(y, y, x)
+}
+
+// ScInvert sets s = a^(l-2) mod l, which is the inverse of a modulo l when a
+// is not zero (and zero when it is).
+//
+// The exponent l-2 is public, so the addition chain below performs the same
+// sequence of 250 squarings and 34 multiplications whatever the value of a.
+// The chain is the one used by curve25519-dalek for scalar inversion.
+func ScInvert(s, a *Key) {
+	var _1, _10, _100, _11, _101, _111, _1001, _1011, _1111 Key
+
+	_1 = *a
+	ScMul(&_10, &_1, &_1)
+	ScMul(&_100, &_10, &_10)
+	ScMul(&_11, &_10, &_1)
+	ScMul(&_101, &_10, &_11)
+	ScMul(&_111, &_10, &_101)
+	ScMul(&_1001, &_10, &_111)
+	ScMul(&_1011, &_10, &_1001)
+	ScMul(&_1111, &_100, &_1011)
+
+	var y Key
+	ScMul(&y, &_1111, &_1)
+
+	scSquareMultiply(&y, 123+3, &_101)
+	scSquareMultiply(&y, 2+2, &_11)
+	scSquareMultiply(&y, 1+4, &_1111)
+	scSquareMultiply(&y, 1+4, &_1111)
+	scSquareMultiply(&y, 4, &_1001)
+	scSquareMultiply(&y, 2, &_11)
+	scSquareMultiply(&y, 1+4, &_1111)
+	scSquareMultiply(&y, 1+3, &_101)
+	scSquareMultiply(&y, 3+3, &_101)
+	scSquareMultiply(&y, 3, &_111)
+	scSquareMultiply(&y, 1+4, &_1111)
+	scSquareMultiply(&y, 2+3, &_111)
+	scSquareMultiply(&y, 2+2, &_11)
+	scSquareMultiply(&y, 1+4, &_1011)
+	scSquareMultiply(&y, 2+4, &_1011)
+	scSquareMultiply(&y, 6+4, &_1001)
+	scSquareMultiply(&y, 2+2, &_11)
+	scSquareMultiply(&y, 3+2, &_11)
+	scSquareMultiply(&y, 3+2, &_11)
+	scSquareMultiply(&y, 1+4, &_1001)
+	scSquareMultiply(&y, 1+3, &_111)
+	scSquareMultiply(&y, 2+4, &_1111)
+	scSquareMultiply(&y, 1+4, &_1011)
+	scSquareMultiply(&y, 3, &_101)
+	scSquareMultiply(&y, 2+4, &_1111)
+	scSquareMultiply(&y, 3, &_101)
+	scSquareMultiply(&y, 1+2, &_11)
+
+	*s = y
+}
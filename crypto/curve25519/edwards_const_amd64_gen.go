@@ -9,7 +9,7 @@
 // a parser. It uses correct syntax but relies on gofmt for proper formatting.
 // To regenerate the 64-bit constants table exactly, use:
 //
-// `go run const_amd64_gen.go | gofmt > const_amd64.go`
+// `go run edwards_const_amd64_gen.go | gofmt > edwards_const_fe64.go`
 //
 // YES I AM SORRY.
 package main
@@ -23,7 +23,8 @@ func main() {
 	fmt.Printf("// Copyright (c) 2017 The Go Authors. All rights reserved.\n")
 	fmt.Printf("// Use of this source code is governed by a BSD-style\n")
 	fmt.Printf("// license that can be found in the LICENSE file.\n\n")
-	fmt.Printf("// +build amd64\n\n")
+	fmt.Printf("// +build amd64 arm64 ppc64 ppc64le mips64 mips64le riscv64 s390x\n")
+	fmt.Printf("// +build !fe32\n\n")
 	fmt.Printf("package curve25519\n\n")
 
 	FeToBytes32(&buf, &d)
@@ -2,7 +2,8 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// +build amd64
+// +build amd64 arm64 ppc64 ppc64le mips64 mips64le riscv64 s390x
+// +build !fe32
 
 package curve25519
 
@@ -14,7 +14,7 @@
 // STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF
 // THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
 
-// +build !amd64
+// +build !amd64,!arm64,!ppc64,!ppc64le,!mips64,!mips64le,!riscv64,!s390x fe32
 
 package curve25519
 
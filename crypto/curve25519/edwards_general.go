@@ -24,7 +24,7 @@
 // Edwards curve that is isomorphic to curve25519. See
 // http://ed25519.cr.yp.to/.
 
-// +build !amd64
+// +build !amd64,!arm64,!ppc64,!ppc64le,!mips64,!mips64le,!riscv64,!s390x fe32
 
 package curve25519
 
@@ -0,0 +1,12 @@
+package curve25519
+
+import "errors"
+
+// Sentinel errors of the checked functions, the errors they return wrap them,
+// so compare with errors.Is
+var (
+	InvalidPointErr   = errors.New("invalid point")
+	InvalidScalarErr  = errors.New("invalid scalar")
+	LengthMismatchErr = errors.New("length mismatch")
+	InvalidKeySizeErr = errors.New("invalid key size")
+)
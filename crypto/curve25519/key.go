@@ -57,21 +57,20 @@ func (p *Key) ToBytes() (result [KeyLength]byte) {
 }
 
 // convert a hex string to a key
+// it panics on invalid input, use CheckedHexToKey for untrusted strings
 func HexToKey(h string) (result Key) {
-	byteSlice, _ := hex.DecodeString(h)
-	if len(byteSlice) != 32 {
+	result, err := CheckedHexToKey(h)
+	if err != nil {
 		panic("Incorrect key size")
 	}
-	copy(result[:], byteSlice)
 	return
 }
 
 func HexToHash(h string) (result Hash) {
-	byteSlice, _ := hex.DecodeString(h)
-	if len(byteSlice) != 32 {
+	result, err := CheckedHexToHash(h)
+	if err != nil {
 		panic("Incorrect key size")
 	}
-	copy(result[:], byteSlice)
 	return
 }
 
@@ -141,12 +140,22 @@ func (p *Key) HashToPointSimple() (result Key) {
 }
 
 // this uses random number generator from the OS
+// it panics if the OS fails to provide randomness, rather than returning a weak scalar
 func RandomScalar() (result *Key) {
-	result = new(Key)
+	result, err := RandomScalarFrom(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return
+}
+
+// RandomScalarFrom reads 64 bytes from r and reduces them to a uniformly random scalar
+func RandomScalarFrom(r io.Reader) (result *Key, err error) {
 	var reduceFrom [KeyLength * 2]byte
-	tmp := make([]byte, KeyLength*2)
-	rand.Read(tmp)
-	copy(reduceFrom[:], tmp)
+	if _, err = io.ReadFull(r, reduceFrom[:]); err != nil {
+		return nil, err
+	}
+	result = new(Key)
 	ScReduce(result, &reduceFrom)
 	return
 }
@@ -285,6 +294,15 @@ func ScalarMultH(scalar *Key) (result *Key) {
 	return
 }
 
+// does a * A where a is a scalar and A is a fixed point given by its FIXED_BASE_TABLE
+func ScalarMultFixedBase(table *FIXED_BASE_TABLE, scalar *Key) (result *Key) {
+	var resultPoint ExtendedGroupElement
+	GeScalarMultFixedBase(&resultPoint, scalar, table)
+	result = new(Key)
+	resultPoint.ToBytes(result)
+	return
+}
+
 // add two points together
 func AddKeys(sum, k1, k2 *Key) {
 	a := k1.ToExtended()
@@ -298,15 +316,29 @@ func AddKeys(sum, k1, k2 *Key) {
 	return
 }
 
-// compute a*G + b*B
+// compute a*G + b*B in variable time
 func AddKeys2(result, a, b, B *Key) {
 	BPoint := B.ToExtended()
 	var RPoint ProjectiveGroupElement
-	GeDoubleScalarMultVartime(&RPoint, b, BPoint, a)
+	GeDoubleScalarMultBaseVartime(&RPoint, a, BPoint, b)
 	RPoint.ToBytes(result)
 	return
 }
 
+// compute a*G + b*B in constant time, where B is a fixed point given by its FIXED_BASE_TABLE
+func AddKeysFixedBase(result *Key, a *Key, b *Key, B_Table *FIXED_BASE_TABLE) {
+	var aG, bB ExtendedGroupElement
+	GeScalarMultBase(&aG, a)
+	GeScalarMultFixedBase(&bB, b, B_Table)
+
+	var bBCached CachedGroupElement
+	var c CompletedGroupElement
+	bB.ToCached(&bBCached)
+	geAdd(&c, &aG, &bBCached)
+	c.ToExtended(&aG)
+	aG.ToBytes(result)
+}
+
 //addKeys3
 //aAbB = a*A + b*B where a, b are scalars, A, B are curve points
 //B must be input after applying "precomp"
@@ -374,26 +406,13 @@ func RandomPubKey() (result *Key) {
 // private is B's private keys
 // HOPE the above is  clean and clear
 
+// it panics on invalid keys, use CheckedKeyDerivation for keys from the network
 func KeyDerivation(pub *Key, priv *Key) (KeyDerivation Key) {
-	var point ExtendedGroupElement
-	var point2 ProjectiveGroupElement
-	var point3 CompletedGroupElement
-
-	if !priv.Private_Key_Valid() {
-		panic("Invalid private key.")
-	}
-	tmp := *pub
-	if !point.FromBytes(&tmp) {
-		panic("Invalid public key.")
+	KeyDerivation, err := CheckedKeyDerivation(pub, priv)
+	if err != nil {
+		panic(err)
 	}
-
-	tmp = *priv
-	GeScalarMult(&point2, &tmp, &point)
-	GeMul8(&point3, &point2)
-	point3.ToProjective(&point2)
-
-	point2.ToBytes(&tmp)
-	return tmp
+	return
 }
 
 // the origincal c implementation needs to be checked for varint overflow
@@ -415,39 +434,25 @@ func (k *Key) KeyDerivationToScalar(outputIndex uint64) (scalar *Key) {
 // generate ephermal keys  from a key derivation
 // base key is the B's public spend key or A's private spend key
 // outputIndex is the position of output within that specific transaction
+// it panics on an invalid base key, see CheckedKeyDerivation_To_PublicKey
 func (kd *Key) KeyDerivation_To_PublicKey(outputIndex uint64, baseKey Key) Key {
-
-	var point1, point2 ExtendedGroupElement
-	var point3 CachedGroupElement
-	var point4 CompletedGroupElement
-	var point5 ProjectiveGroupElement
-
-	tmp := baseKey
-	if !point1.FromBytes(&tmp) {
-		panic("Invalid public key.")
+	res, err := kd.CheckedKeyDerivation_To_PublicKey(outputIndex, baseKey)
+	if err != nil {
+		panic(err)
 	}
-	scalar := kd.KeyDerivationToScalar(outputIndex)
-	GeScalarMultBase(&point2, scalar)
-	point2.ToCached(&point3)
-	geAdd(&point4, &point1, &point3)
-	point4.ToProjective(&point5)
-	point5.ToBytes(&tmp)
-	return tmp
+	return res
 }
 
 // generate ephermal keys  from a key derivation
 // base key is the A's private spend key
 // outputIndex is the position of output within that specific transaction
+// it panics on an invalid base key, see CheckedKeyDerivation_To_PrivateKey
 func (kd *Key) KeyDerivation_To_PrivateKey(outputIndex uint64, baseKey Key) Key {
-	if !baseKey.Private_Key_Valid() {
-		panic("Invalid private key.")
+	res, err := kd.CheckedKeyDerivation_To_PrivateKey(outputIndex, baseKey)
+	if err != nil {
+		panic(err)
 	}
-	scalar := kd.KeyDerivationToScalar(outputIndex)
-
-	tmp := baseKey
-	ScAdd(&tmp, &tmp, scalar)
-
-	return tmp
+	return res
 }
 
 // NewKeyImage creates a new KeyImage from the given public and private keys.
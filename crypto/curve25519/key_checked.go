@@ -0,0 +1,134 @@
+package curve25519
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// The Checked functions return an error wrapping one of the sentinel errors instead of panicking,
+// or of computing with a key that does not decode. Use them with the keys of the network.
+
+// CheckedHexToKey decodes a key of 64 hex digits
+func CheckedHexToKey(h string) (result Key, err error) {
+	b, err := hex.DecodeString(h)
+	if err != nil || len(b) != KeyLength {
+		return result, fmt.Errorf("%w: hex key %q", InvalidKeySizeErr, h)
+	}
+	copy(result[:], b)
+	return result, nil
+}
+
+// CheckedHexToHash decodes a hash of 64 hex digits
+func CheckedHexToHash(h string) (result Hash, err error) {
+	b, err := hex.DecodeString(h)
+	if err != nil || len(b) != HashLength {
+		return result, fmt.Errorf("%w: hex hash %q", InvalidKeySizeErr, h)
+	}
+	copy(result[:], b)
+	return result, nil
+}
+
+// CheckedKeyDerivation returns 8*priv*pub, the key derivation of KeyDerivation
+func CheckedKeyDerivation(pub *Key, priv *Key) (result Key, err error) {
+	var point ExtendedGroupElement
+	var point2 ProjectiveGroupElement
+	var point3 CompletedGroupElement
+
+	if pub == nil || priv == nil {
+		return result, fmt.Errorf("%w: nil key", InvalidPointErr)
+	}
+	if !priv.Private_Key_Valid() {
+		return result, fmt.Errorf("%w: private key", InvalidScalarErr)
+	}
+	tmp := *pub
+	if !point.FromBytes(&tmp) {
+		return result, fmt.Errorf("%w: public key", InvalidPointErr)
+	}
+
+	tmp = *priv
+	GeScalarMult(&point2, &tmp, &point)
+	GeMul8(&point3, &point2)
+	point3.ToProjective(&point2)
+	point2.ToBytes(&result)
+	return result, nil
+}
+
+// CheckedKeyDerivation_To_PublicKey returns baseKey + Hs(kd || outputIndex)*G
+func (kd *Key) CheckedKeyDerivation_To_PublicKey(outputIndex uint64, baseKey Key) (result Key, err error) {
+	var point1, point2 ExtendedGroupElement
+	var point3 CachedGroupElement
+	var point4 CompletedGroupElement
+	var point5 ProjectiveGroupElement
+
+	if !point1.FromBytes(&baseKey) {
+		return result, fmt.Errorf("%w: base public key", InvalidPointErr)
+	}
+	scalar := kd.KeyDerivationToScalar(outputIndex)
+	GeScalarMultBase(&point2, scalar)
+	point2.ToCached(&point3)
+	geAdd(&point4, &point1, &point3)
+	point4.ToProjective(&point5)
+	point5.ToBytes(&result)
+	return result, nil
+}
+
+// CheckedKeyDerivation_To_PrivateKey returns baseKey + Hs(kd || outputIndex)
+func (kd *Key) CheckedKeyDerivation_To_PrivateKey(outputIndex uint64, baseKey Key) (result Key, err error) {
+	if !baseKey.Private_Key_Valid() {
+		return result, fmt.Errorf("%w: base private key", InvalidScalarErr)
+	}
+	scalar := kd.KeyDerivationToScalar(outputIndex)
+	result = baseKey
+	ScAdd(&result, &result, scalar)
+	return result, nil
+}
+
+// checkScalars checks that the scalars can be recoded by SignedRadix16
+func checkScalars(scalars []*Key) error {
+	for i, s := range scalars {
+		if s == nil || s[31] > 127 {
+			return fmt.Errorf("%w: scalar %d", InvalidScalarErr, i)
+		}
+	}
+	return nil
+}
+
+// CheckedScalarMultKey returns scalar * point
+func CheckedScalarMultKey(point *Key, scalar *Key) (*Key, error) {
+	var P ExtendedGroupElement
+	if point == nil || !P.FromBytes(point) {
+		return nil, fmt.Errorf("%w: point", InvalidPointErr)
+	}
+	if err := checkScalars([]*Key{scalar}); err != nil {
+		return nil, err
+	}
+	return ScalarMultKey(point, scalar), nil
+}
+
+// CheckedMultiScalarMultKey returns the sum of scalars[i] * points[i]
+func CheckedMultiScalarMultKey(points []*Key, scalars []*Key) (*Key, error) {
+	if len(points) != len(scalars) {
+		return nil, fmt.Errorf("%w: %d points and %d scalars", LengthMismatchErr, len(points), len(scalars))
+	}
+	var P ExtendedGroupElement
+	for i, point := range points {
+		if point == nil || !P.FromBytes(point) {
+			return nil, fmt.Errorf("%w: point %d", InvalidPointErr, i)
+		}
+	}
+	if err := checkScalars(scalars); err != nil {
+		return nil, err
+	}
+	return MultiScalarMultKey(points, scalars), nil
+}
+
+// CheckedMultiScalarMultKeyCached returns the sum of scalars[i] * A_i from the tables of the A_i
+func CheckedMultiScalarMultKeyCached(AiLs [][8]CachedGroupElement, scalars []*Key) (*Key, error) {
+	if len(AiLs) != len(scalars) {
+		return nil, fmt.Errorf("%w: %d tables and %d scalars", LengthMismatchErr, len(AiLs), len(scalars))
+	}
+	if err := checkScalars(scalars); err != nil {
+		return nil, err
+	}
+	return MultiScalarMultKeyCached(AiLs, scalars), nil
+}
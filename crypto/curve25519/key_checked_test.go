@@ -0,0 +1,121 @@
+package curve25519
+
+import (
+	"errors"
+	"testing"
+)
+
+// testInvalidPoint returns a key that does not decode to a point
+func testInvalidPoint(t *testing.T) *Key {
+	var P ExtendedGroupElement
+	for i := 2; i < 256; i++ {
+		key := Key{byte(i)}
+		if !P.FromBytes(&key) {
+			return &key
+		}
+	}
+	t.Fatalf("no invalid point found")
+	return nil
+}
+
+func TestCheckedHexToKey(t *testing.T) {
+	h := "0100000000000000000000000000000000000000000000000000000000000000"
+	key, err := CheckedHexToKey(h)
+	if err != nil || key != Identity {
+		t.Fatalf("expected the identity, got %x %v", key, err)
+	}
+	if HexToKey(h) != key {
+		t.Fatalf("expected HexToKey to match")
+	}
+	for _, h := range []string{"", "01", h + "00", h[:63] + "z"} {
+		if _, err := CheckedHexToKey(h); !errors.Is(err, InvalidKeySizeErr) {
+			t.Fatalf("hex key %q: expected InvalidKeySizeErr, got %v", h, err)
+		}
+		if _, err := CheckedHexToHash(h); !errors.Is(err, InvalidKeySizeErr) {
+			t.Fatalf("hex hash %q: expected InvalidKeySizeErr, got %v", h, err)
+		}
+	}
+}
+
+func TestCheckedKeyDerivation(t *testing.T) {
+	a := RandomScalar()
+	r := RandomScalar()
+	A := ScalarmultBase(a)
+	R := ScalarmultBase(r)
+
+	d1, err := CheckedKeyDerivation(A, r)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	d2, err := CheckedKeyDerivation(R, a)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if d1 != d2 || d1 != KeyDerivation(A, r) {
+		t.Fatalf("expected the derivations to match")
+	}
+
+	if _, err := CheckedKeyDerivation(testInvalidPoint(t), a); !errors.Is(err, InvalidPointErr) {
+		t.Fatalf("expected InvalidPointErr, got %v", err)
+	}
+	if _, err := CheckedKeyDerivation(nil, a); !errors.Is(err, InvalidPointErr) {
+		t.Fatalf("expected InvalidPointErr, got %v", err)
+	}
+	unreduced := Key{}
+	for i := range unreduced {
+		unreduced[i] = 0xff
+	}
+	if _, err := CheckedKeyDerivation(A, &unreduced); !errors.Is(err, InvalidScalarErr) {
+		t.Fatalf("expected InvalidScalarErr, got %v", err)
+	}
+
+	pub, err := d1.CheckedKeyDerivation_To_PublicKey(3, *A)
+	if err != nil || pub != d1.KeyDerivation_To_PublicKey(3, *A) {
+		t.Fatalf("expected the public keys to match, %v", err)
+	}
+	priv, err := d1.CheckedKeyDerivation_To_PrivateKey(3, *a)
+	if err != nil || *ScalarmultBase(&priv) != pub {
+		t.Fatalf("expected the private key of the public key, %v", err)
+	}
+	if _, err := d1.CheckedKeyDerivation_To_PublicKey(3, *testInvalidPoint(t)); !errors.Is(err, InvalidPointErr) {
+		t.Fatalf("expected InvalidPointErr, got %v", err)
+	}
+	if _, err := d1.CheckedKeyDerivation_To_PrivateKey(3, unreduced); !errors.Is(err, InvalidScalarErr) {
+		t.Fatalf("expected InvalidScalarErr, got %v", err)
+	}
+}
+
+func TestCheckedMultiScalarMultKey(t *testing.T) {
+	points := []*Key{RandomPubKey(), RandomPubKey()}
+	scalars := []*Key{RandomScalar(), RandomScalar()}
+
+	res, err := CheckedMultiScalarMultKey(points, scalars)
+	if err != nil || *res != *MultiScalarMultKey(points, scalars) {
+		t.Fatalf("expected the sums to match, %v", err)
+	}
+	res, err = CheckedScalarMultKey(points[0], scalars[0])
+	if err != nil || *res != *ScalarMultKey(points[0], scalars[0]) {
+		t.Fatalf("expected the products to match, %v", err)
+	}
+
+	if _, err := CheckedMultiScalarMultKey(points, scalars[:1]); !errors.Is(err, LengthMismatchErr) {
+		t.Fatalf("expected LengthMismatchErr, got %v", err)
+	}
+	if _, err := CheckedMultiScalarMultKey([]*Key{points[0], testInvalidPoint(t)}, scalars); !errors.Is(err, InvalidPointErr) {
+		t.Fatalf("expected InvalidPointErr, got %v", err)
+	}
+	if _, err := CheckedMultiScalarMultKey([]*Key{points[0], nil}, scalars); !errors.Is(err, InvalidPointErr) {
+		t.Fatalf("expected InvalidPointErr, got %v", err)
+	}
+	if _, err := CheckedMultiScalarMultKey(points, []*Key{scalars[0], nil}); !errors.Is(err, InvalidScalarErr) {
+		t.Fatalf("expected InvalidScalarErr, got %v", err)
+	}
+	large := *scalars[0]
+	large[31] = 0x80
+	if _, err := CheckedScalarMultKey(points[0], &large); !errors.Is(err, InvalidScalarErr) {
+		t.Fatalf("expected InvalidScalarErr, got %v", err)
+	}
+	if _, err := CheckedMultiScalarMultKeyCached(nil, scalars); !errors.Is(err, LengthMismatchErr) {
+		t.Fatalf("expected LengthMismatchErr, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+package curve25519
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Inclusion proofs of the trees of BuildMerkleTreeStore.
+// Level 0 holds the n leaves, and every level has ceil(width/2) nodes: the parent of 2i and 2i+1 is
+// HashMerkleBranches(node[2i], node[2i+1]), and the last node of an odd level is hashed with itself.
+// The branch of a leaf has one sibling per level, its direction follows from the index.
+//
+// Duplicating the last node makes [a b c] and [a b c c] share their root (CVE-2012-2459), so a branch
+// also carries the number of leaves, and the verifiers require the sibling to be the node itself
+// exactly at the last position of an odd level and to differ from it anywhere else.
+// MerkleTreeMutated detects the leaf lists with such duplicate pairs, which must be rejected
+// before their root is trusted.
+
+// MerkleNode is a sibling of a branch, Left is true if the sibling is the left child
+type MerkleNode struct {
+	Hash Hash
+	Left bool
+}
+
+// MerkleBranch is the inclusion proof of a leaf in a tree of Count leaves
+type MerkleBranch struct {
+	Count    int
+	Siblings []MerkleNode
+}
+
+// MerkleMultiBranch is the inclusion proof of several leaves, Hashes are the siblings that the
+// leaves do not give, level by level and from left to right
+type MerkleMultiBranch struct {
+	Count  int
+	Hashes []Hash
+}
+
+// merkleLevels returns the levels of the tree, from the leaves to the root
+func merkleLevels(hashes []Hash) [][]Hash {
+	levels := [][]Hash{hashes}
+	for level := hashes; len(level) > 1; {
+		next := make([]Hash, (len(level)+1)/2)
+		for i := range next {
+			right := &level[2*i]
+			if 2*i+1 < len(level) {
+				right = &level[2*i+1]
+			}
+			next[i] = *HashMerkleBranches(&level[2*i], right)
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// merkleDepth returns the number of levels above the leaves of a tree of count leaves
+func merkleDepth(count int) int {
+	depth := 0
+	for width := count; width > 1; width = (width + 1) / 2 {
+		depth++
+	}
+	return depth
+}
+
+// MerkleTreeMutated returns true if some level has a pair of equal nodes, in which case
+// the root is also the root of another list of leaves
+func MerkleTreeMutated(hashes []Hash) bool {
+	for _, level := range merkleLevels(hashes) {
+		for i := 0; i+1 < len(level); i += 2 {
+			if level[i] == level[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MerkleProof returns the branch of the leaf index, its root is MerkleRoot(hashes)
+func MerkleProof(hashes []Hash, index int) (*MerkleBranch, error) {
+	if index < 0 || index >= len(hashes) {
+		return nil, fmt.Errorf("Merkle leaf index %d out of range", index)
+	}
+	proof := &MerkleBranch{Count: len(hashes)}
+	levels := merkleLevels(hashes)
+	for _, level := range levels[:len(levels)-1] {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		proof.Siblings = append(proof.Siblings, MerkleNode{Hash: level[sibling], Left: index&1 == 1})
+		index /= 2
+	}
+	return proof, nil
+}
+
+// VerifyMerkleProof checks that leaf is the leaf index of the tree of root
+func VerifyMerkleProof(root Hash, leaf Hash, index int, proof *MerkleBranch) bool {
+	if proof == nil || index < 0 || index >= proof.Count || len(proof.Siblings) != merkleDepth(proof.Count) {
+		return false
+	}
+	node := leaf
+	width := proof.Count
+	for _, sibling := range proof.Siblings {
+		if sibling.Left != (index&1 == 1) {
+			return false
+		}
+		last := index^1 >= width
+		if last != (sibling.Hash == node) {
+			return false
+		}
+		if sibling.Left {
+			node = *HashMerkleBranches(&sibling.Hash, &node)
+		} else {
+			node = *HashMerkleBranches(&node, &sibling.Hash)
+		}
+		index /= 2
+		width = (width + 1) / 2
+	}
+	return node == root
+}
+
+// sortedLeaves returns the indices in increasing order with their leaves, an error on duplicates
+func sortedLeaves(leaves []Hash, indices []int, count int) ([]int, map[int]Hash, error) {
+	if len(indices) == 0 {
+		return nil, nil, fmt.Errorf("Merkle no leaf to prove")
+	}
+	known := make(map[int]Hash, len(indices))
+	for i, index := range indices {
+		if index < 0 || index >= count {
+			return nil, nil, fmt.Errorf("Merkle leaf index %d out of range", index)
+		}
+		if _, ok := known[index]; ok {
+			return nil, nil, fmt.Errorf("Merkle duplicate leaf index %d", index)
+		}
+		if leaves != nil {
+			known[index] = leaves[i]
+		} else {
+			known[index] = Hash{}
+		}
+	}
+	sorted := append([]int{}, indices...)
+	sort.Ints(sorted)
+	return sorted, known, nil
+}
+
+// parentIndices returns the distinct parents of the sorted indices
+func parentIndices(sorted []int) []int {
+	parents := make([]int, 0, len(sorted))
+	for _, index := range sorted {
+		if len(parents) == 0 || parents[len(parents)-1] != index/2 {
+			parents = append(parents, index/2)
+		}
+	}
+	return parents
+}
+
+// MerkleMultiProof returns the proof of the leaves of indices, it is shorter than their branches
+// since the shared nodes are given once
+func MerkleMultiProof(hashes []Hash, indices []int) (*MerkleMultiBranch, error) {
+	sorted, known, err := sortedLeaves(nil, indices, len(hashes))
+	if err != nil {
+		return nil, err
+	}
+	proof := &MerkleMultiBranch{Count: len(hashes)}
+	levels := merkleLevels(hashes)
+	for _, level := range levels[:len(levels)-1] {
+		for _, index := range sorted {
+			sibling := index ^ 1
+			if _, ok := known[sibling]; ok || sibling >= len(level) {
+				continue
+			}
+			proof.Hashes = append(proof.Hashes, level[sibling])
+		}
+		sorted = parentIndices(sorted)
+		known = make(map[int]Hash, len(sorted))
+		for _, index := range sorted {
+			known[index] = Hash{}
+		}
+	}
+	return proof, nil
+}
+
+// VerifyMerkleMultiProof checks that leaves[i] is the leaf indices[i] of the tree of root
+func VerifyMerkleMultiProof(root Hash, leaves []Hash, indices []int, proof *MerkleMultiBranch) bool {
+	if proof == nil || len(leaves) != len(indices) {
+		return false
+	}
+	sorted, known, err := sortedLeaves(leaves, indices, proof.Count)
+	if err != nil {
+		return false
+	}
+	next := 0
+	for width, depth := proof.Count, merkleDepth(proof.Count); depth > 0; depth-- {
+		parents := make(map[int]Hash, len(sorted))
+		for _, index := range sorted {
+			if _, ok := parents[index/2]; ok {
+				continue
+			}
+			node := known[index]
+			sibling, ok := known[index^1]
+			switch {
+			case index^1 >= width:
+				sibling = node
+			case !ok:
+				if next == len(proof.Hashes) {
+					return false
+				}
+				sibling = proof.Hashes[next]
+				next++
+				if sibling == node {
+					return false
+				}
+			case sibling == node:
+				return false
+			}
+			if index&1 == 1 {
+				parents[index/2] = *HashMerkleBranches(&sibling, &node)
+			} else {
+				parents[index/2] = *HashMerkleBranches(&node, &sibling)
+			}
+		}
+		sorted = parentIndices(sorted)
+		known = parents
+		width = (width + 1) / 2
+	}
+	return next == len(proof.Hashes) && known[0] == root
+}
@@ -0,0 +1,145 @@
+package curve25519
+
+import "testing"
+
+func testMerkleLeaves(n int) []Hash {
+	hashes := make([]Hash, n)
+	for i := range hashes {
+		hashes[i] = Keccak256([]byte{byte(i), byte(i >> 8)})
+	}
+	return hashes
+}
+
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		hashes := testMerkleLeaves(n)
+		root := MerkleRoot(hashes)
+		levels := merkleLevels(hashes)
+		if levels[len(levels)-1][0] != root {
+			t.Fatalf("%d leaves: the levels do not give the root of BuildMerkleTreeStore", n)
+		}
+		for index := range hashes {
+			proof, err := MerkleProof(hashes, index)
+			if err != nil {
+				t.Fatalf("%d leaves: %v", n, err)
+			}
+			if !VerifyMerkleProof(root, hashes[index], index, proof) {
+				t.Fatalf("%d leaves: expected the branch of %d to verify", n, index)
+			}
+			if VerifyMerkleProof(root, hashes[(index+1)%n], index, proof) && n > 1 {
+				t.Fatalf("%d leaves: expected another leaf to be rejected", n)
+			}
+			if VerifyMerkleProof(root, hashes[index], index^1, proof) {
+				t.Fatalf("%d leaves: expected another index to be rejected", n)
+			}
+			if len(proof.Siblings) > 0 {
+				proof.Siblings[0].Left = !proof.Siblings[0].Left
+				if VerifyMerkleProof(root, hashes[index], index, proof) {
+					t.Fatalf("%d leaves: expected a wrong direction to be rejected", n)
+				}
+			}
+		}
+	}
+
+	if _, err := MerkleProof(testMerkleLeaves(3), 3); err == nil {
+		t.Fatalf("expected an index out of range to be rejected")
+	}
+	if VerifyMerkleProof(Hash{}, Hash{}, 0, nil) {
+		t.Fatalf("expected a nil proof to be rejected")
+	}
+}
+
+// the leaves [a b c] and [a b c c] have the same root
+func TestMerkleProof_DuplicateLeaf(t *testing.T) {
+	hashes := testMerkleLeaves(3)
+	mutated := append(append([]Hash{}, hashes...), hashes[2])
+	root := MerkleRoot(hashes)
+	if MerkleRoot(mutated) != root {
+		t.Fatalf("expected the mutated leaves to have the same root")
+	}
+	if MerkleTreeMutated(hashes) || !MerkleTreeMutated(mutated) {
+		t.Fatalf("expected only the duplicate leaves to be detected")
+	}
+	if !MerkleTreeMutated(append(testMerkleLeaves(5), testMerkleLeaves(5)[4])) {
+		t.Fatalf("expected duplicate inner nodes to be detected")
+	}
+
+	// the branch of the duplicate leaf does not verify
+	proof, _ := MerkleProof(mutated, 3)
+	if VerifyMerkleProof(root, hashes[2], 3, proof) {
+		t.Fatalf("expected the branch of the duplicate leaf to be rejected")
+	}
+	multi, _ := MerkleMultiProof(mutated, []int{3})
+	if VerifyMerkleMultiProof(root, hashes[2:], []int{3}, multi) {
+		t.Fatalf("expected the multi proof of the duplicate leaf to be rejected")
+	}
+
+	// a branch cannot claim more leaves
+	proof, _ = MerkleProof(hashes, 2)
+	proof.Count = 4
+	if VerifyMerkleProof(root, hashes[2], 2, proof) {
+		t.Fatalf("expected a wrong number of leaves to be rejected")
+	}
+}
+
+func TestMerkleMultiProof(t *testing.T) {
+	for n := 1; n <= 17; n++ {
+		hashes := testMerkleLeaves(n)
+		root := MerkleRoot(hashes)
+		subsets := [][]int{{0}, {n - 1}, {n - 1, 0}, {n / 2, n / 3, n - 1}}
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		subsets = append(subsets, all)
+		for _, subset := range subsets {
+			// small trees repeat some indices
+			var indices []int
+			seen := map[int]bool{}
+			for _, index := range subset {
+				if !seen[index] {
+					seen[index] = true
+					indices = append(indices, index)
+				}
+			}
+			leaves := make([]Hash, len(indices))
+			for i, index := range indices {
+				leaves[i] = hashes[index]
+			}
+			proof, err := MerkleMultiProof(hashes, indices)
+			if err != nil {
+				t.Fatalf("%d leaves: %v", n, err)
+			}
+			if !VerifyMerkleMultiProof(root, leaves, indices, proof) {
+				t.Fatalf("%d leaves: expected the proof of %v to verify", n, indices)
+			}
+			if len(indices) == n && len(proof.Hashes) != 0 {
+				t.Fatalf("%d leaves: expected no hash to prove all the leaves", n)
+			}
+			leaves[0][0] ^= 1
+			if VerifyMerkleMultiProof(root, leaves, indices, proof) {
+				t.Fatalf("%d leaves: expected a wrong leaf to be rejected", n)
+			}
+			leaves[0][0] ^= 1
+			proof.Hashes = append(proof.Hashes, Hash{})
+			if VerifyMerkleMultiProof(root, leaves, indices, proof) {
+				t.Fatalf("%d leaves: expected an extra hash to be rejected", n)
+			}
+		}
+	}
+
+	hashes := testMerkleLeaves(8)
+	if _, err := MerkleMultiProof(hashes, []int{1, 1}); err == nil {
+		t.Fatalf("expected duplicate indices to be rejected")
+	}
+	if _, err := MerkleMultiProof(hashes, nil); err == nil {
+		t.Fatalf("expected an empty proof to be rejected")
+	}
+	proof, _ := MerkleMultiProof(hashes, []int{1, 6})
+	if VerifyMerkleMultiProof(MerkleRoot(hashes), hashes[1:2], []int{1, 6}, proof) {
+		t.Fatalf("expected missing leaves to be rejected")
+	}
+	if len(proof.Hashes) != 4 {
+		t.Fatalf("expected 4 hashes, got %d", len(proof.Hashes))
+	}
+}
@@ -29,6 +29,11 @@ type SUPER_PRECOMPUTE_TABLE [32]PRECOMPUTE_TABLE
 
 type FAST_TABLE [256]PreComputedGroupElement
 
+// FIXED_BASE_TABLE has the same layout as the table used by GeScalarMultBase,
+// table[i][j] = (j+1) * 256^i * A, which allows constant time fixed base
+// multiplication of any point A. Each table is roughly 30 KB
+type FIXED_BASE_TABLE [32][8]PreComputedGroupElement
+
 var x FAST_TABLE
 
 //import "fmt"
@@ -75,6 +80,42 @@ func GenPrecompute(table *PRECOMPUTE_TABLE, A Key) {
 
 }
 
+// GenFixedBaseTable fills table with the multiples of A used by
+// GeScalarMultFixedBase. Entries are stored in affine form, which costs one
+// field inversion per entry, so tables should be built once and reused
+func GenFixedBaseTable(table *FIXED_BASE_TABLE, A Key) {
+	var row, cur ExtendedGroupElement
+	var cached CachedGroupElement
+	var c CompletedGroupElement
+	var affine ExtendedGroupElement
+	var t ProjectiveGroupElement
+	var tmp Key
+
+	row.FromBytes(&A)
+	for i := 0; i < 32; i++ {
+		// cur = (j+1) * row
+		row.ToCached(&cached)
+		cur = row
+		for j := 0; j < 8; j++ {
+			cur.ToBytes(&tmp)
+			affine.FromBytes(&tmp)
+			affine.ToPreComputed(&table[i][j])
+
+			geAdd(&c, &cur, &cached)
+			c.ToExtended(&cur)
+		}
+
+		// row = 256 * row
+		row.ToProjective(&t)
+		for k := 0; k < 7; k++ {
+			t.Double(&c)
+			c.ToProjective(&t)
+		}
+		t.Double(&c)
+		c.ToExtended(&row)
+	}
+}
+
 const BITS_PER_BYTE = (8)
 
 // it finds the highest bit that is high
@@ -0,0 +1,147 @@
+package curve25519
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// this file includes the binary format of the precompute tables, so that they
+// can be stored on disk or embedded instead of being computed at startup
+//
+// layout:  magic (4) | version (1) | kind (1) | base point (32) | entries | keccak256 of all previous bytes (32)
+// every cached element is stored as its 4 field elements in canonical form
+
+const PrecomputeFileVersion = 1
+
+const (
+	precomputeKindTable      = 1
+	precomputeKindSuperTable = 2
+)
+
+var precomputeMagic = []byte("ICPT")
+
+const precomputeHeaderSize = 4 + 1 + 1 + KeyLength
+const cachedElementSize = 4 * KeyLength
+
+func encodeCached(out []byte, c *CachedGroupElement) []byte {
+	var k Key
+	for _, fe := range []*FieldElement{&c.yPlusX, &c.yMinusX, &c.Z, &c.T2d} {
+		FeToBytes(&k, fe)
+		out = append(out, k[:]...)
+	}
+	return out
+}
+
+func decodeCached(c *CachedGroupElement, in []byte) {
+	var k Key
+	for i, fe := range []*FieldElement{&c.yPlusX, &c.yMinusX, &c.Z, &c.T2d} {
+		copy(k[:], in[i*KeyLength:(i+1)*KeyLength])
+		FeFromBytes(fe, &k)
+	}
+}
+
+func encodePrecomputeFile(kind byte, A *Key, entries []*PRECOMPUTE_TABLE) []byte {
+	size := precomputeHeaderSize + len(entries)*len(PRECOMPUTE_TABLE{})*cachedElementSize + HashLength
+	res := make([]byte, 0, size)
+	res = append(res, precomputeMagic...)
+	res = append(res, PrecomputeFileVersion, kind)
+	res = append(res, A[:]...)
+	for _, table := range entries {
+		for i := range table {
+			res = encodeCached(res, &table[i])
+		}
+	}
+	hash := Keccak256(res)
+	return append(res, hash[:]...)
+}
+
+// checkPrecomputeFile validates the header and the content hash of data,
+// and returns the entries part of it
+func checkPrecomputeFile(kind byte, A *Key, nTables int, data []byte) ([]byte, error) {
+	size := precomputeHeaderSize + nTables*len(PRECOMPUTE_TABLE{})*cachedElementSize + HashLength
+	if len(data) != size {
+		return nil, fmt.Errorf("Invalid precompute table size")
+	}
+	if !bytes.Equal(data[:4], precomputeMagic) {
+		return nil, fmt.Errorf("Invalid precompute table magic")
+	}
+	if data[4] != PrecomputeFileVersion {
+		return nil, fmt.Errorf("Unsupported precompute table version %d", data[4])
+	}
+	if data[5] != kind {
+		return nil, fmt.Errorf("Invalid precompute table kind %d", data[5])
+	}
+	if !bytes.Equal(data[6:precomputeHeaderSize], A[:]) {
+		return nil, fmt.Errorf("Precompute table is for another base point")
+	}
+	hash := Keccak256(data[:size-HashLength])
+	if !bytes.Equal(hash[:], data[size-HashLength:]) {
+		return nil, fmt.Errorf("Precompute table hash mismatch")
+	}
+	return data[precomputeHeaderSize : size-HashLength], nil
+}
+
+// EncodePrecompute serializes a table generated by GenPrecompute for the point A
+func EncodePrecompute(table *PRECOMPUTE_TABLE, A Key) []byte {
+	return encodePrecomputeFile(precomputeKindTable, &A, []*PRECOMPUTE_TABLE{table})
+}
+
+// DecodePrecompute loads a table serialized by EncodePrecompute, table is left untouched on error
+func DecodePrecompute(table *PRECOMPUTE_TABLE, A Key, data []byte) error {
+	entries, err := checkPrecomputeFile(precomputeKindTable, &A, 1, data)
+	if err != nil {
+		return err
+	}
+	for i := range table {
+		decodeCached(&table[i], entries[i*cachedElementSize:])
+	}
+	return nil
+}
+
+// EncodeSuperPrecompute serializes a table generated by GenSuperPrecompute for the point A
+func EncodeSuperPrecompute(stable *SUPER_PRECOMPUTE_TABLE, A Key) []byte {
+	entries := make([]*PRECOMPUTE_TABLE, len(stable))
+	for i := range stable {
+		entries[i] = &stable[i]
+	}
+	return encodePrecomputeFile(precomputeKindSuperTable, &A, entries)
+}
+
+// DecodeSuperPrecompute loads a table serialized by EncodeSuperPrecompute, stable is left untouched on error
+func DecodeSuperPrecompute(stable *SUPER_PRECOMPUTE_TABLE, A Key, data []byte) error {
+	entries, err := checkPrecomputeFile(precomputeKindSuperTable, &A, len(stable), data)
+	if err != nil {
+		return err
+	}
+	tableSize := len(PRECOMPUTE_TABLE{}) * cachedElementSize
+	for i := range stable {
+		for j := range stable[i] {
+			decodeCached(&stable[i][j], entries[i*tableSize+j*cachedElementSize:])
+		}
+	}
+	return nil
+}
+
+// GenPrecomputeFromBytes loads the table of A from data,
+// and falls back to GenPrecompute if data is not a valid table of A.
+// It returns whether the table was loaded
+func GenPrecomputeFromBytes(table *PRECOMPUTE_TABLE, A Key, data []byte) bool {
+	if DecodePrecompute(table, A, data) == nil {
+		return true
+	}
+	GenPrecompute(table, A)
+	return false
+}
+
+// GenSuperPrecomputeFromBytes loads the super table of A from data,
+// and falls back to GenPrecompute and GenSuperPrecompute if data is not a valid table of A.
+// It returns whether the table was loaded
+func GenSuperPrecomputeFromBytes(stable *SUPER_PRECOMPUTE_TABLE, A Key, data []byte) bool {
+	if DecodeSuperPrecompute(stable, A, data) == nil {
+		return true
+	}
+	var table PRECOMPUTE_TABLE
+	GenPrecompute(&table, A)
+	GenSuperPrecompute(stable, &table)
+	return false
+}
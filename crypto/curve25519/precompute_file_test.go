@@ -0,0 +1,67 @@
+package curve25519
+
+import "testing"
+
+func TestPrecomputeFile(t *testing.T) {
+	A := *(RandomScalar()).PublicKey()
+	var table, loaded PRECOMPUTE_TABLE
+	GenPrecompute(&table, A)
+
+	data := EncodePrecompute(&table, A)
+	if !GenPrecomputeFromBytes(&loaded, A, data) {
+		t.Fatalf("expected the encoded table to be loaded")
+	}
+
+	s := RandomScalar()
+	var r1, r2 ExtendedGroupElement
+	var k1, k2 Key
+	ScalarMultPrecompute(&r1, s, &table)
+	ScalarMultPrecompute(&r2, s, &loaded)
+	r1.ToBytes(&k1)
+	r2.ToBytes(&k2)
+	if k1 != k2 {
+		t.Fatalf("expected the loaded table to give the same result")
+	}
+
+	// a corrupted table or a table of another point is rejected
+	data[len(data)/2] ^= 1
+	if DecodePrecompute(&loaded, A, data) == nil {
+		t.Fatalf("expected a corrupted table to be rejected")
+	}
+	data[len(data)/2] ^= 1
+	B := *(RandomScalar()).PublicKey()
+	if DecodePrecompute(&loaded, B, data) == nil {
+		t.Fatalf("expected a table of another point to be rejected")
+	}
+	if GenPrecomputeFromBytes(&loaded, A, data[:len(data)-1]) {
+		t.Fatalf("expected a truncated table to be recomputed")
+	}
+}
+
+func TestSuperPrecomputeFile(t *testing.T) {
+	A := *(RandomScalar()).PublicKey()
+	var table PRECOMPUTE_TABLE
+	var stable, loaded SUPER_PRECOMPUTE_TABLE
+	GenPrecompute(&table, A)
+	GenSuperPrecompute(&stable, &table)
+
+	data := EncodeSuperPrecompute(&stable, A)
+	if err := DecodeSuperPrecompute(&loaded, A, data); err != nil {
+		t.Fatalf("expected the encoded super table to be loaded: %v", err)
+	}
+
+	s := RandomScalar()
+	var r1, r2 ExtendedGroupElement
+	var k1, k2 Key
+	ScalarMultSuperPrecompute(&r1, s, &stable)
+	ScalarMultSuperPrecompute(&r2, s, &loaded)
+	r1.ToBytes(&k1)
+	r2.ToBytes(&k2)
+	if k1 != k2 {
+		t.Fatalf("expected the loaded super table to give the same result")
+	}
+
+	if DecodePrecompute(&table, A, data) == nil {
+		t.Fatalf("expected a super table not to be loaded as a table")
+	}
+}
@@ -19,12 +19,21 @@ package curve25519
 import "golang.org/x/crypto/scrypt"
 
 // quick scrypt wrapper
+// it panics if scrypt fails, CheckedScrypt_1024_1_1_256 returns the error instead
 func Scrypt_1024_1_1_256(data []byte) (result Hash) {
+	result, err := CheckedScrypt_1024_1_1_256(data)
+	if err != nil {
+		panic("scrypt failed") // maybe due to RAM
+	}
+	return
+}
 
+// CheckedScrypt_1024_1_1_256 is Scrypt_1024_1_1_256 returning the error of scrypt
+func CheckedScrypt_1024_1_1_256(data []byte) (result Hash, err error) {
 	dk, err := scrypt.Key(data, data, 1024, 1, 1, 32) // 32 byte  = 256 bits
 	if err != nil {
-		panic("scrypt failed") // maybe due to RAM
+		return result, err
 	}
 	copy(result[:], dk)
-	return
+	return result, nil
 }
@@ -0,0 +1,187 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Twisted ElGamal encryption of uint64 values (Chen et al., eprint 2019/319)
+// Keys:       sk random, PK = sk*H
+// Encryption: C = v*G + r*H, D = r*PK, the ciphertext is (C, D)
+// Decryption: v*G = C - sk^-1 * D, v is found by baby-step giant-step for bounded values
+// C is a Pedersen commitment under G/H with the blind r, so the bullet proofs prove
+// the range of an encrypted value with the witness (v, r) and no change to the range proof.
+// Ciphertexts under the same public key are additively homomorphic.
+
+const CiphertextSize = 2 * crypto.Ed25519KeySize
+
+type PrivateKey struct {
+	sk *crypto.Scalar
+	pk *crypto.Point
+}
+
+type Ciphertext struct {
+	c *crypto.Point
+	d *crypto.Point
+}
+
+func GenerateKey() *PrivateKey {
+	priv, err := GenerateKeyWithRand(rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return priv
+}
+
+// GenerateKeyWithRand reads the private key from rng
+func GenerateKeyWithRand(rng io.Reader) (*PrivateKey, error) {
+	sk, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
+	return NewPrivateKey(sk)
+}
+
+func NewPrivateKey(sk *crypto.Scalar) (*PrivateKey, error) {
+	if sk == nil || !sk.ScalarValid() || crypto.CompareScalar(sk, new(crypto.Scalar).FromUint64(0)) == 0 {
+		return nil, errors.New("NewPrivateKey invalid private key")
+	}
+	return &PrivateKey{
+		sk: new(crypto.Scalar).Set(sk),
+		pk: new(crypto.Point).ScalarMultH(sk),
+	}, nil
+}
+
+func (priv PrivateKey) PublicKey() *crypto.Point {
+	return new(crypto.Point).Set(priv.pk)
+}
+
+// Encrypt encrypts value under pk, the blind r is returned for the range proof of the ciphertext
+func Encrypt(pk *crypto.Point, value uint64) (*Ciphertext, *crypto.Scalar, error) {
+	r := crypto.RandomScalar()
+	ct, err := EncryptWithBlind(pk, value, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ct, r, nil
+}
+
+// EncryptWithBlind encrypts value under pk with the blind r
+func EncryptWithBlind(pk *crypto.Point, value uint64, r *crypto.Scalar) (*Ciphertext, error) {
+	if pk == nil || !pk.PointValid() || pk.IsIdentity() {
+		return nil, errors.New("Encrypt invalid public key")
+	}
+	if r == nil || !r.ScalarValid() {
+		return nil, errors.New("Encrypt invalid blind")
+	}
+	return &Ciphertext{
+		c: new(crypto.Point).AddPedersenBase(new(crypto.Scalar).FromUint64(value), r),
+		d: new(crypto.Point).ScalarMult(pk, r),
+	}, nil
+}
+
+// DecryptPoint returns v*G, which does not need the value to be bounded
+func (priv PrivateKey) DecryptPoint(ct *Ciphertext) *crypto.Point {
+	skInv := new(crypto.Scalar).Invert(priv.sk)
+	return new(crypto.Point).Sub(ct.c, new(crypto.Point).ScalarMult(ct.d, skInv))
+}
+
+// Decrypt returns the value of the ciphertext, which must be in the range of the table
+func (priv PrivateKey) Decrypt(ct *Ciphertext, table *DecryptionTable) (uint64, error) {
+	if ct == nil || ct.c == nil || ct.d == nil {
+		return 0, errors.New("Decrypt ciphertext is nil")
+	}
+	if table == nil {
+		return 0, errors.New("Decrypt table is nil")
+	}
+	return table.Lookup(priv.DecryptPoint(ct))
+}
+
+// Commitment returns C, the commitment to the value with the blind of the encryption,
+// which is the commitment of a bullet proof of the value
+func (ct Ciphertext) Commitment() *crypto.Commitment {
+	return crypto.NewCommitmentFromPoint(ct.c)
+}
+
+// Handle returns D, the part of the ciphertext bound to the public key
+func (ct Ciphertext) Handle() *crypto.Point {
+	return new(crypto.Point).Set(ct.d)
+}
+
+func (ct *Ciphertext) Set(a *Ciphertext) *Ciphertext {
+	if ct == nil {
+		ct = new(Ciphertext)
+	}
+	ct.c = new(crypto.Point).Set(a.c)
+	ct.d = new(crypto.Point).Set(a.d)
+	return ct
+}
+
+// Add returns the encryption of the sum of the values, the blinds add up as well
+func (ct *Ciphertext) Add(a, b *Ciphertext) *Ciphertext {
+	if ct == nil {
+		ct = new(Ciphertext)
+	}
+	ct.c = new(crypto.Point).Add(a.c, b.c)
+	ct.d = new(crypto.Point).Add(a.d, b.d)
+	return ct
+}
+
+// Sub returns the encryption of the difference of the values, with the difference of the blinds
+func (ct *Ciphertext) Sub(a, b *Ciphertext) *Ciphertext {
+	if ct == nil {
+		ct = new(Ciphertext)
+	}
+	ct.c = new(crypto.Point).Sub(a.c, b.c)
+	ct.d = new(crypto.Point).Sub(a.d, b.d)
+	return ct
+}
+
+// AddValue adds a public value, such as a deposit, without changing the blind
+func (ct *Ciphertext) AddValue(a *Ciphertext, value uint64) *Ciphertext {
+	if ct == nil {
+		ct = new(Ciphertext)
+	}
+	v := new(crypto.Point).ScalarMultBase(new(crypto.Scalar).FromUint64(value))
+	ct.c = new(crypto.Point).Add(a.c, v)
+	ct.d = new(crypto.Point).Set(a.d)
+	return ct
+}
+
+// SubValue subtracts a public value, such as a fee, without changing the blind
+func (ct *Ciphertext) SubValue(a *Ciphertext, value uint64) *Ciphertext {
+	if ct == nil {
+		ct = new(Ciphertext)
+	}
+	v := new(crypto.Point).ScalarMultBase(new(crypto.Scalar).FromUint64(value))
+	ct.c = new(crypto.Point).Sub(a.c, v)
+	ct.d = new(crypto.Point).Set(a.d)
+	return ct
+}
+
+func (ct Ciphertext) Bytes() []byte {
+	if ct.c == nil || ct.d == nil {
+		return []byte{}
+	}
+	return crypto.AppendPointsToBytesArray(make([]byte, 0, CiphertextSize), []*crypto.Point{ct.c, ct.d})
+}
+
+func (ct *Ciphertext) SetBytes(bytes []byte) error {
+	if len(bytes) != CiphertextSize {
+		return errors.New("invalid ciphertext size")
+	}
+	c, err := new(crypto.Point).FromBytes(bytes[:crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	d, err := new(crypto.Point).FromBytes(bytes[crypto.Ed25519KeySize:])
+	if err != nil {
+		return err
+	}
+	ct.c = c
+	ct.d = d
+	return nil
+}
@@ -0,0 +1,132 @@
+package elgamal
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/bulletproof"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestElGamal_EncryptDecrypt(t *testing.T) {
+	table, err := NewDecryptionTable(20)
+	assert.Equal(t, nil, err)
+	priv := GenerateKey()
+
+	for _, value := range []uint64{0, 1, 1023, 1024, 1025, 654321, 1<<20 - 1} {
+		ct, _, err := Encrypt(priv.PublicKey(), value)
+		assert.Equal(t, nil, err)
+		res, err := priv.Decrypt(ct, table)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, value, res)
+
+		received := new(Ciphertext)
+		assert.Equal(t, nil, received.SetBytes(ct.Bytes()))
+		assert.Equal(t, ct.Bytes(), received.Bytes())
+	}
+
+	// values out of the range of the table are not found, but the point is still decrypted
+	ct, _, _ := Encrypt(priv.PublicKey(), 1<<20)
+	_, err = priv.Decrypt(ct, table)
+	assert.NotEqual(t, nil, err)
+	vG := new(crypto.Point).ScalarMultBase(new(crypto.Scalar).FromUint64(1 << 20))
+	assert.Equal(t, true, crypto.IsPointEqual(vG, priv.DecryptPoint(ct)))
+
+	// another key does not decrypt
+	ct, _, _ = Encrypt(priv.PublicKey(), 77)
+	res, err := GenerateKey().Decrypt(ct, table)
+	assert.Equal(t, true, err != nil || res != 77)
+
+	_, err = NewDecryptionTable(MaxTableBits + 1)
+	assert.NotEqual(t, nil, err)
+	_, err = NewPrivateKey(new(crypto.Scalar).FromUint64(0))
+	assert.NotEqual(t, nil, err)
+	_, _, err = Encrypt(new(crypto.Point).Identity(), 1)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestElGamal_Homomorphic(t *testing.T) {
+	table, _ := NewDecryptionTable(16)
+	priv := GenerateKey()
+	pk := priv.PublicKey()
+
+	balance, r1, _ := Encrypt(pk, 5000)
+	deposit, r2, _ := Encrypt(pk, 1200)
+	transfer, r3, _ := Encrypt(pk, 3100)
+
+	sum := new(Ciphertext).Add(balance, deposit)
+	res, err := priv.Decrypt(sum, table)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, uint64(6200), res)
+
+	diff := new(Ciphertext).Sub(sum, transfer)
+	res, _ = priv.Decrypt(diff, table)
+	assert.Equal(t, uint64(3100), res)
+
+	// the blinds follow the values, so the result is the encryption with the combined blind
+	blind := new(crypto.Scalar).Add(r1, r2)
+	blind.Sub(blind, r3)
+	expected, _ := EncryptWithBlind(pk, 3100, blind)
+	assert.Equal(t, expected.Bytes(), diff.Bytes())
+
+	// public values keep the blind
+	fee := new(Ciphertext).SubValue(diff, 100)
+	res, _ = priv.Decrypt(fee, table)
+	assert.Equal(t, uint64(3000), res)
+	assert.Equal(t, true, crypto.IsPointEqual(diff.Handle(), fee.Handle()))
+	res, _ = priv.Decrypt(new(Ciphertext).AddValue(fee, 36), table)
+	assert.Equal(t, uint64(3036), res)
+
+	// the receiver copies do not alias the inputs
+	copied := new(Ciphertext).Set(balance)
+	copied.Add(copied, deposit)
+	res, _ = priv.Decrypt(balance, table)
+	assert.Equal(t, uint64(5000), res)
+}
+
+func TestElGamal_RangeProof(t *testing.T) {
+	priv := GenerateKey()
+	ct, r, _ := Encrypt(priv.PublicKey(), 123456)
+
+	// the bullet proof of the value with the blind of the encryption commits to C
+	wit := new(bulletproof.BulletWitness)
+	wit.Set([]uint64{123456}, []*crypto.Scalar{r})
+	proof, err := wit.Single_Prove()
+	assert.Equal(t, nil, err)
+	res, err := proof.Single_Verify()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+	assert.Equal(t, true, crypto.IsCommitmentEqual(ct.Commitment(), proof.GetCommitments()[0]))
+	assert.Equal(t, true, ct.Commitment().Open(123456, r))
+}
+
+func TestDecryptionTable_Bounds(t *testing.T) {
+	for _, bits := range []int{1, 3, 8} {
+		table, err := NewDecryptionTable(bits)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, bits, table.Bits())
+		for v := uint64(0); v < 1<<uint(bits); v++ {
+			res, err := table.Lookup(new(crypto.Point).ScalarMultBase(new(crypto.Scalar).FromUint64(v)))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, v, res)
+		}
+		_, err = table.Lookup(new(crypto.Point).ScalarMultBase(new(crypto.Scalar).FromUint64(1 << uint(bits))))
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func BenchmarkNewDecryptionTable(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		NewDecryptionTable(DefaultTableBits)
+	}
+}
+
+func BenchmarkDecrypt(b *testing.B) {
+	table, _ := NewDecryptionTable(DefaultTableBits)
+	priv := GenerateKey()
+	ct, _, _ := Encrypt(priv.PublicKey(), 1<<32-1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		priv.Decrypt(ct, table)
+	}
+}
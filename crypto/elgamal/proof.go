@@ -0,0 +1,171 @@
+package elgamal
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Proof that a ciphertext (C, D) under PK is well formed, that is the prover knows v and r
+// with C = v*G + r*H and D = r*PK, so that the owner of PK can decrypt it
+// Prover:   a, b random, A1 = a*G + b*H, A2 = b*PK, e = H(PK || C || D || A1 || A2),
+//           z1 = a + e*v, z2 = b + e*r
+// Verifier: z1*G + z2*H == A1 + e*C and z2*PK == A2 + e*D
+// With a bullet proof of C, which shares the witness (v, r), the ciphertext holds a value in range.
+
+const cStringElGamal = "twistedelgamal"
+
+const wellFormedProofSize = 7 * crypto.Ed25519KeySize
+
+type WellFormedWitness struct {
+	pk    *crypto.Point
+	ct    *Ciphertext
+	value uint64
+	r     *crypto.Scalar
+}
+
+type WellFormedProof struct {
+	pk *crypto.Point
+	ct *Ciphertext
+	a1 *crypto.Point
+	a2 *crypto.Point
+	z1 *crypto.Scalar
+	z2 *crypto.Scalar
+}
+
+// Set sets the witness of the encryption of value under pk with the blind r
+func (wit *WellFormedWitness) Set(pk *crypto.Point, value uint64, r *crypto.Scalar) error {
+	ct, err := EncryptWithBlind(pk, value, r)
+	if err != nil {
+		return err
+	}
+	wit.pk = new(crypto.Point).Set(pk)
+	wit.ct = ct
+	wit.value = value
+	wit.r = new(crypto.Scalar).Set(r)
+	return nil
+}
+
+func (wit WellFormedWitness) GetCiphertext() *Ciphertext {
+	return wit.ct
+}
+
+func generateChallenge(pk *crypto.Point, ct *Ciphertext, a1, a2 *crypto.Point) *crypto.Scalar {
+	bytes := []byte(cStringElGamal)
+	bytes = crypto.AppendPointsToBytesArray(bytes, []*crypto.Point{pk, ct.c, ct.d, a1, a2})
+	return crypto.HashToScalar(bytes)
+}
+
+func (wit WellFormedWitness) Prove() (*WellFormedProof, error) {
+	return wit.ProveWithRand(rand.Reader)
+}
+
+// ProveWithRand creates the proof, reading the nonces from rng
+func (wit WellFormedWitness) ProveWithRand(rng io.Reader) (*WellFormedProof, error) {
+	if wit.pk == nil || wit.ct == nil || wit.r == nil {
+		return nil, errors.New("invalid witness of well formed ciphertext")
+	}
+	nonces, err := crypto.RandomScalarsFrom(rng, 2)
+	if err != nil {
+		return nil, err
+	}
+	a, b := nonces[0], nonces[1]
+	a1 := new(crypto.Point).AddPedersenBase(a, b)
+	a2 := new(crypto.Point).ScalarMult(wit.pk, b)
+
+	e := generateChallenge(wit.pk, wit.ct, a1, a2)
+	return &WellFormedProof{
+		pk: wit.pk,
+		ct: wit.ct,
+		a1: a1,
+		a2: a2,
+		z1: new(crypto.Scalar).MulAdd(e, new(crypto.Scalar).FromUint64(wit.value), a),
+		z2: new(crypto.Scalar).MulAdd(e, wit.r, b),
+	}, nil
+}
+
+func (proof WellFormedProof) GetPublicKey() *crypto.Point {
+	return proof.pk
+}
+
+func (proof WellFormedProof) GetCiphertext() *Ciphertext {
+	return proof.ct
+}
+
+func (proof WellFormedProof) ValidateSanity() bool {
+	if proof.pk == nil || !proof.pk.PointValid() || proof.pk.IsIdentity() {
+		return false
+	}
+	if proof.ct == nil || proof.ct.c == nil || !proof.ct.c.PointValid() || proof.ct.d == nil || !proof.ct.d.PointValid() {
+		return false
+	}
+	if proof.a1 == nil || !proof.a1.PointValid() || proof.a2 == nil || !proof.a2.PointValid() {
+		return false
+	}
+	return proof.z1 != nil && proof.z1.ScalarValid() && proof.z2 != nil && proof.z2.ScalarValid()
+}
+
+func (proof WellFormedProof) Verify() (bool, error) {
+	if !proof.ValidateSanity() {
+		return false, errors.New("invalid well formed ciphertext proof")
+	}
+	e := generateChallenge(proof.pk, proof.ct, proof.a1, proof.a2)
+
+	// z1*G + z2*H - e*C == A1
+	lhs := new(crypto.Point).AddPedersenBase(proof.z1, proof.z2)
+	lhs.Sub(lhs, new(crypto.Point).ScalarMult(proof.ct.c, e))
+	if !crypto.IsPointEqual(lhs, proof.a1) {
+		return false, errors.New("verify well formed ciphertext proof failed")
+	}
+	// z2*PK - e*D == A2
+	eNeg := new(crypto.Scalar).Sub(new(crypto.Scalar).FromUint64(0), e)
+	lhs = new(crypto.Point).AddPedersen(proof.z2, proof.pk, eNeg, proof.ct.d)
+	if !crypto.IsPointEqual(lhs, proof.a2) {
+		return false, errors.New("verify well formed ciphertext proof failed")
+	}
+	return true, nil
+}
+
+// Bytes encodes the proof as PK || C || D || A1 || A2 || z1 || z2
+func (proof WellFormedProof) Bytes() []byte {
+	if !proof.ValidateSanity() {
+		return []byte{}
+	}
+	res := make([]byte, 0, wellFormedProofSize)
+	res = crypto.AppendPointsToBytesArray(res, []*crypto.Point{proof.pk, proof.ct.c, proof.ct.d, proof.a1, proof.a2})
+	res = append(res, proof.z1.ToBytes()...)
+	return append(res, proof.z2.ToBytes()...)
+}
+
+func (proof *WellFormedProof) SetBytes(bytes []byte) error {
+	if len(bytes) != wellFormedProofSize {
+		return errors.New("invalid well formed ciphertext proof size")
+	}
+	var err error
+	points := make([]*crypto.Point, 5)
+	for i := range points {
+		points[i], err = new(crypto.Point).FromBytes(bytes[i*crypto.Ed25519KeySize : (i+1)*crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+	}
+	offset := 5 * crypto.Ed25519KeySize
+	z1, err := new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	z2, err := new(crypto.Scalar).FromBytes(bytes[offset+crypto.Ed25519KeySize:])
+	if err != nil {
+		return err
+	}
+
+	proof.pk = points[0]
+	proof.ct = &Ciphertext{c: points[1], d: points[2]}
+	proof.a1 = points[3]
+	proof.a2 = points[4]
+	proof.z1 = z1
+	proof.z2 = z2
+	return nil
+}
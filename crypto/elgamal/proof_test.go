@@ -0,0 +1,58 @@
+package elgamal
+
+import (
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWellFormedProof(t *testing.T) {
+	priv := GenerateKey()
+	r := crypto.RandomScalar()
+	wit := new(WellFormedWitness)
+	assert.Equal(t, nil, wit.Set(priv.PublicKey(), 42, r))
+
+	proof, err := wit.Prove()
+	assert.Equal(t, nil, err)
+	res, err := proof.Verify()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	expected, _ := EncryptWithBlind(priv.PublicKey(), 42, r)
+	assert.Equal(t, expected.Bytes(), proof.GetCiphertext().Bytes())
+
+	received := new(WellFormedProof)
+	assert.Equal(t, nil, received.SetBytes(proof.Bytes()))
+	assert.Equal(t, proof.Bytes(), received.Bytes())
+	res, err = received.Verify()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	// a handle which is not r*PK is rejected, the owner could not decrypt the ciphertext
+	forged := *proof
+	forged.ct = &Ciphertext{c: proof.ct.c, d: new(crypto.Point).ScalarMult(priv.PublicKey(), crypto.RandomScalar())}
+	res, _ = forged.Verify()
+	assert.Equal(t, false, res)
+
+	// the proof is bound to the public key
+	forged = *proof
+	forged.pk = GenerateKey().PublicKey()
+	res, _ = forged.Verify()
+	assert.Equal(t, false, res)
+
+	forged = *proof
+	forged.z2 = crypto.RandomScalar()
+	res, _ = forged.Verify()
+	assert.Equal(t, false, res)
+
+	rngErr := errors.New("rng failure")
+	_, err = wit.ProveWithRand(iotest.ErrReader(rngErr))
+	assert.Equal(t, rngErr, err)
+
+	assert.NotEqual(t, nil, new(WellFormedProof).SetBytes(proof.Bytes()[1:]))
+	_, err = new(WellFormedWitness).Prove()
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,69 @@
+package elgamal
+
+import (
+	"errors"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Baby-step giant-step search of v in [0, 2^bits) from v*G
+// Baby steps: the table of j*G for j in [0, m) with m = 2^ceil(bits/2)
+// Giant steps: P - i*m*G for i in [0, 2^bits / m), the first one found in the table gives v = i*m + j
+// The table has m entries, 2^16 for the default 32 bits
+
+const (
+	DefaultTableBits = 32
+	MaxTableBits     = 40
+)
+
+type DecryptionTable struct {
+	bits      int
+	babySteps map[[crypto.Ed25519KeySize]byte]uint64
+	giantStep *crypto.Point
+}
+
+// NewDecryptionTable builds the table to decrypt the values below 2^bits
+func NewDecryptionTable(bits int) (*DecryptionTable, error) {
+	if bits < 1 || bits > MaxTableBits {
+		return nil, errors.New("NewDecryptionTable bits out of range")
+	}
+	m := uint64(1) << uint((bits+1)/2)
+
+	table := &DecryptionTable{
+		bits:      bits,
+		babySteps: make(map[[crypto.Ed25519KeySize]byte]uint64, m),
+	}
+	p := new(crypto.Point).Identity()
+	for j := uint64(0); j < m; j++ {
+		table.babySteps[pointKey(p)] = j
+		p.Add(p, crypto.G)
+	}
+	table.giantStep = p
+	return table, nil
+}
+
+func pointKey(p *crypto.Point) [crypto.Ed25519KeySize]byte {
+	var key [crypto.Ed25519KeySize]byte
+	copy(key[:], p.ToBytes())
+	return key
+}
+
+// Bits returns the number of bits of the values the table decrypts
+func (table DecryptionTable) Bits() int {
+	return table.bits
+}
+
+// Lookup returns v from v*G
+func (table DecryptionTable) Lookup(p *crypto.Point) (uint64, error) {
+	m := uint64(len(table.babySteps))
+	giantSteps := (uint64(1) << uint(table.bits)) / m
+
+	cur := new(crypto.Point).Set(p)
+	for i := uint64(0); i < giantSteps; i++ {
+		if j, ok := table.babySteps[pointKey(cur)]; ok {
+			return i*m + j, nil
+		}
+		cur.Sub(cur, table.giantStep)
+	}
+	return 0, errors.New("Lookup value out of the range of the table")
+}
@@ -1,7 +1,19 @@
 package crypto
 
-import "errors"
+import (
+	"errors"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
 
 var InvalidMaxHashSizeErr = errors.New("invalid max hash size")
 var InvalidHashSizeErr = errors.New("invalid hash size")
-var NilHashErr = errors.New("input hash is nil")
\ No newline at end of file
+var NilHashErr = errors.New("input hash is nil")
+
+// The errors of invalid points and scalars are those of curve25519, compare them with errors.Is
+var (
+	InvalidPointErr   = C25519.InvalidPointErr
+	InvalidScalarErr  = C25519.InvalidScalarErr
+	LengthMismatchErr = C25519.LengthMismatchErr
+	InvalidKeySizeErr = C25519.InvalidKeySizeErr
+)
\ No newline at end of file
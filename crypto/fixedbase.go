@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"sync"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// FixedBase is a point that is multiplied by many different scalars, such as H
+// or the bulletproof generators. Its precomputed table is built on first use
+// and makes every later multiplication as fast as ScalarMultBase.
+type FixedBase struct {
+	point Point
+	once  sync.Once
+	table *C25519.FIXED_BASE_TABLE
+}
+
+var hFixedBase = NewFixedBase(H)
+
+func NewFixedBase(p *Point) *FixedBase {
+	base := new(FixedBase)
+	base.point.Set(p)
+	return base
+}
+
+func (base *FixedBase) Point() *Point {
+	return new(Point).Set(&base.point)
+}
+
+// Table returns the precomputed table of the point, building it once
+func (base *FixedBase) Table() *C25519.FIXED_BASE_TABLE {
+	base.once.Do(func() {
+		base.table = new(C25519.FIXED_BASE_TABLE)
+		C25519.GenFixedBaseTable(base.table, base.point.key)
+	})
+	return base.table
+}
+
+// ScalarMultFixedBase does a * A in constant time where A is the point of base
+func (p *Point) ScalarMultFixedBase(base *FixedBase, a *Scalar) *Point {
+	if p == nil {
+		p = new(Point)
+	}
+	key := C25519.ScalarMultFixedBase(base.Table(), &a.key)
+	p.key = *key
+	return p
+}
+
+// ScalarMultH does a * H where a is a scalar and H is the second base point
+func (p *Point) ScalarMultH(a *Scalar) *Point {
+	return p.ScalarMultFixedBase(hFixedBase, a)
+}
+
+// PreComputeForMultiScalar builds the tables used by MultiScalarMultCached,
+// so that points which are used in many multi scalar mults are only decoded once
+func PreComputeForMultiScalar(pointLs []*Point) [][8]C25519.CachedGroupElement {
+	res := make([][8]C25519.CachedGroupElement, len(pointLs))
+	for i := range pointLs {
+		res[i] = C25519.PreComputeForMultiScalar(&pointLs[i].key)
+	}
+	return res
+}
@@ -0,0 +1,162 @@
+package crypto
+
+import (
+	"crypto/sha512"
+	"errors"
+	"math/big"
+)
+
+// Hash to curve of RFC 9380, suite edwards25519_XMD:SHA-512_ELL2_NU_
+// u = hash_to_field(msg) with expand_message_xmd and SHA-512
+// Q = map_to_curve(u), elligator 2 to curve25519 followed by the rational map to edwards25519
+// P = 8*Q
+// Unlike HashToPoint the result does not depend on a keccak based map of this library,
+// so it matches other implementations of the standard. It uses math/big and is not constant time,
+// the message is expected to be public.
+
+const (
+	h2cExpandLen     = 48  // L = ceil((ceil(log2(p)) + k) / 8) with k = 128
+	h2cSHA512Block   = 128 // s_in_bytes of SHA-512
+	h2cOversizeDST   = "H2C-OVERSIZE-DST-"
+	montgomeryA      = 486662
+	elligator2Z      = 2
+	h2cMaxDSTLen     = 255
+	h2cMaxExpandSize = 255 * sha512.Size
+)
+
+var (
+	fieldP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	bigJ   = big.NewInt(montgomeryA)
+	// c1 = sqrt(-486664) with sgn0(c1) == 0, the constant of the rational map to edwards25519
+	edwardsC1 = func() *big.Int {
+		c1 := new(big.Int).ModSqrt(new(big.Int).Sub(fieldP, big.NewInt(montgomeryA+2)), fieldP)
+		if c1.Bit(0) == 1 {
+			c1.Sub(fieldP, c1)
+		}
+		return c1
+	}()
+)
+
+// EncodeToCurve hashes msg to a point of the prime order subgroup with the domain separation tag dst,
+// following the encode_to_curve function of the edwards25519_XMD:SHA-512_ELL2_NU_ suite
+func EncodeToCurve(msg []byte, dst []byte) (*Point, error) {
+	uniform, err := expandMessageXMD(msg, dst, h2cExpandLen)
+	if err != nil {
+		return nil, err
+	}
+	u := new(big.Int).SetBytes(uniform)
+	u.Mod(u, fieldP)
+
+	x, y := mapToEdwards25519(u)
+	q, err := new(Point).FromBytes(encodeEdwards(x, y))
+	if err != nil {
+		return nil, err
+	}
+	return new(Point).MulCofactor(q), nil
+}
+
+// expandMessageXMD is expand_message_xmd of RFC 9380 section 5.3.1 with SHA-512
+func expandMessageXMD(msg []byte, dst []byte, lenInBytes int) ([]byte, error) {
+	if len(dst) > h2cMaxDSTLen {
+		digest := sha512.Sum512(append([]byte(h2cOversizeDST), dst...))
+		dst = digest[:]
+	}
+	if lenInBytes <= 0 || lenInBytes > h2cMaxExpandSize {
+		return nil, errors.New("expandMessageXMD invalid output length")
+	}
+	ell := (lenInBytes + sha512.Size - 1) / sha512.Size
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+
+	h := sha512.New()
+	h.Write(make([]byte, h2cSHA512Block))
+	h.Write(msg)
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+
+	res := make([]byte, 0, ell*sha512.Size)
+	bi := make([]byte, sha512.Size)
+	for i := 1; i <= ell; i++ {
+		// b_i = H(strxor(b_0, b_(i-1)) || I2OSP(i, 1) || DST_prime), with b_0 in place of the xor for i = 1
+		for j := range bi {
+			bi[j] ^= b0[j]
+		}
+		h.Reset()
+		h.Write(bi)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		res = append(res, bi...)
+	}
+	return res[:lenInBytes], nil
+}
+
+// mapToEdwards25519 maps a field element to a point of edwards25519, the point may have a small order component
+func mapToEdwards25519(u *big.Int) (*big.Int, *big.Int) {
+	s, t := elligator2(u)
+
+	// rational map: x = c1 * s / t, y = (s - 1) / (s + 1), the exceptional cases go to the identity
+	sPlus1 := new(big.Int).Add(s, big.NewInt(1))
+	sPlus1.Mod(sPlus1, fieldP)
+	if t.Sign() == 0 || sPlus1.Sign() == 0 {
+		return big.NewInt(0), big.NewInt(1)
+	}
+	x := new(big.Int).Mul(edwardsC1, s)
+	x.Mul(x, new(big.Int).ModInverse(t, fieldP))
+	x.Mod(x, fieldP)
+	y := new(big.Int).Sub(s, big.NewInt(1))
+	y.Mul(y, new(big.Int).ModInverse(sPlus1, fieldP))
+	y.Mod(y, fieldP)
+	return x, y
+}
+
+// elligator2 is map_to_curve_elligator2 of RFC 9380 section 6.7.1 for curve25519 with Z = 2
+func elligator2(u *big.Int) (*big.Int, *big.Int) {
+	// x1 = -J / (1 + Z*u^2), or -J when the denominator is zero
+	den := new(big.Int).Mul(u, u)
+	den.Mul(den, big.NewInt(elligator2Z))
+	den.Add(den, big.NewInt(1))
+	den.Mod(den, fieldP)
+	x1 := new(big.Int).Neg(bigJ)
+	if den.Sign() != 0 {
+		x1.Mul(x1, new(big.Int).ModInverse(den, fieldP))
+	}
+	x1.Mod(x1, fieldP)
+
+	// if gx1 is square the point is (x1, sqrt(gx1)) with sgn0(y) = 1,
+	// otherwise it is (x2, sqrt(gx2)) with x2 = -x1 - J and sgn0(y) = 0
+	if y := new(big.Int).ModSqrt(montgomeryRHS(x1), fieldP); y != nil {
+		if y.Bit(0) == 0 {
+			y.Sub(fieldP, y).Mod(y, fieldP)
+		}
+		return x1, y
+	}
+	x2 := new(big.Int).Neg(x1)
+	x2.Sub(x2, bigJ)
+	x2.Mod(x2, fieldP)
+	y := new(big.Int).ModSqrt(montgomeryRHS(x2), fieldP)
+	if y.Bit(0) == 1 {
+		y.Sub(fieldP, y)
+	}
+	return x2, y
+}
+
+// montgomeryRHS returns x^3 + J*x^2 + x
+func montgomeryRHS(x *big.Int) *big.Int {
+	res := new(big.Int).Add(x, bigJ)
+	res.Mul(res, x)
+	res.Add(res, big.NewInt(1))
+	res.Mul(res, x)
+	return res.Mod(res, fieldP)
+}
+
+// encodeEdwards returns the 32 bytes encoding of (x, y): y in little endian with the sign of x in the top bit
+func encodeEdwards(x, y *big.Int) []byte {
+	res := make([]byte, Ed25519KeySize)
+	be := y.FillBytes(make([]byte, Ed25519KeySize))
+	for i := range res {
+		res[i] = be[Ed25519KeySize-1-i]
+	}
+	res[Ed25519KeySize-1] |= byte(x.Bit(0)) << 7
+	return res
+}
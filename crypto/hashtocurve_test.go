@@ -0,0 +1,76 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors of RFC 9380 appendix J.5.2, edwards25519_XMD:SHA-512_ELL2_NU_
+func TestEncodeToCurve(t *testing.T) {
+	dst := []byte("QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_NU_")
+	vectors := []struct {
+		msg  string
+		x, y string
+	}{
+		{"",
+			"1ff2b70ecf862799e11b7ae744e3489aa058ce805dd323a936375a84695e76da",
+			"222e314d04a4d5725e9f2aff9fb2a6b69ef375a1214eb19021ceab2d687f0f9b"},
+		{"abc",
+			"5f13cc69c891d86927eb37bd4afc6672360007c63f68a33ab423a3aa040fd2a8",
+			"67732d50f9a26f73111dd1ed5dba225614e538599db58ba30aaea1f5c827fa42"},
+		{"abcdef0123456789",
+			"1dd2fefce934ecfd7aae6ec998de088d7dd03316aa1847198aecf699ba6613f1",
+			"2f8a6c24dd1adde73909cada6a4a137577b0f179d336685c4a955a0a8e1a86fb"},
+		{"q128_" + strings.Repeat("q", 128),
+			"35fbdc5143e8a97afd3096f2b843e07df72e15bfca2eaf6879bf97c5d3362f73",
+			"2af6ff6ef5ebba128b0774f4296cb4c2279a074658b083b8dcca91f57a603450"},
+		{"a512_" + strings.Repeat("a", 512),
+			"6e5e1f37e99345887fc12111575fc1c3e36df4b289b8759d23af14d774b66bff",
+			"2c90c3d39eb18ff291d33441b35f3262cdd307162cc97c31bfcc7a4245891a37"},
+	}
+
+	for _, vector := range vectors {
+		xBytes, _ := hex.DecodeString(vector.x)
+		yBytes, _ := hex.DecodeString(vector.y)
+		expected := encodeEdwards(new(big.Int).SetBytes(xBytes), new(big.Int).SetBytes(yBytes))
+
+		p, err := EncodeToCurve([]byte(vector.msg), dst)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, expected, p.ToBytes())
+		assert.Equal(t, true, p.PointValid())
+	}
+
+	// the tag separates the domains
+	p1, _ := EncodeToCurve([]byte("abc"), []byte("tag 1"))
+	p2, _ := EncodeToCurve([]byte("abc"), []byte("tag 2"))
+	assert.Equal(t, false, IsPointEqual(p1, p2))
+}
+
+func TestMapToEdwards25519_Exceptional(t *testing.T) {
+	// u = 0 gives x1 = -J on curve25519 and a point of order 2, the image is on edwards25519
+	x, y := mapToEdwards25519(big.NewInt(0))
+	_, err := new(Point).FromBytes(encodeEdwards(x, y))
+	assert.Equal(t, nil, err)
+}
+
+func TestScalar_FromBytesModOrder(t *testing.T) {
+	sc := RandomScalar()
+	res, err := new(Scalar).FromBytesModOrder(sc.ToBytes())
+	assert.Equal(t, nil, err)
+	assert.Equal(t, sc, res)
+
+	// L reduces to zero
+	l := LInt.Bytes()
+	for i, j := 0, len(l)-1; i < j; i, j = i+1, j-1 {
+		l[i], l[j] = l[j], l[i]
+	}
+	res, _ = new(Scalar).FromBytesModOrder(l)
+	assert.Equal(t, new(Scalar).FromUint64(0), res)
+
+	_, err = new(Scalar).FromBytesModOrder(make([]byte, 65))
+	assert.NotEqual(t, nil, err)
+}
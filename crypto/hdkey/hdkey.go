@@ -0,0 +1,218 @@
+package hdkey
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Hierarchical deterministic derivation of (spend, view) key pairs from a 32 bytes seed.
+// Every scalar comes from HashToScalar over a domain tag, as KeyDerivationToScalar does,
+// and indices are encoded as uvarints.
+//
+// Master:     b = Hs("hd_spend" || seed), a = Hs("hd_view" || seed), c = Keccak("hd_chain" || seed)
+// Child i:    t_b = Hs("hd_spend_child" || c || data || i), t_a = Hs("hd_view_child" || c || data || i),
+//             c_i = Keccak("hd_chain_child" || c || data || i)
+//             b_i = b + t_b, a_i = a + t_a, so B_i = B + t_b*G
+// Hardened indices (i >= 2^31) use data = b, only the owner of the spend key derives them.
+// Normal indices use data = B || A, so a view-only wallet, which has a, B and c, derives the
+// child view keys and spend public keys, and never needs b.
+// As in BIP32, the spend key of a normal child together with the public data of its parent
+// gives the spend key of the parent: share the keys of hardened children only.
+
+const (
+	SeedSize       = 32
+	ChainCodeSize  = 32
+	HardenedOffset = uint32(1) << 31
+
+	cStringSpend       = "hd_spend"
+	cStringView        = "hd_view"
+	cStringChain       = "hd_chain"
+	cStringSpendChild  = "hd_spend_child"
+	cStringViewChild   = "hd_view_child"
+	cStringChainChild  = "hd_chain_child"
+	cStringSubaddress  = "hd_subaddress"
+	maxIndexUvarintLen = binary.MaxVarintLen32
+)
+
+type ExtendedKey struct {
+	spend     *crypto.Scalar // nil for a view-only key
+	spendPub  *crypto.Point
+	view      *crypto.Scalar
+	viewPub   *crypto.Point
+	chainCode []byte
+	depth     uint8
+	index     uint32
+}
+
+func appendIndex(bytes []byte, index uint32) []byte {
+	tmp := make([]byte, maxIndexUvarintLen)
+	n := binary.PutUvarint(tmp, uint64(index))
+	return append(bytes, tmp[:n]...)
+}
+
+func hashToScalar(domain string, data ...[]byte) *crypto.Scalar {
+	msg := []byte(domain)
+	for _, d := range data {
+		msg = append(msg, d...)
+	}
+	return crypto.HashToScalar(msg)
+}
+
+func isZero(sc *crypto.Scalar) bool {
+	return crypto.CompareScalar(sc, new(crypto.Scalar).FromUint64(0)) == 0
+}
+
+// NewMaster returns the root key of the seed
+func NewMaster(seed []byte) (*ExtendedKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("NewMaster invalid seed size")
+	}
+	spend := hashToScalar(cStringSpend, seed)
+	view := hashToScalar(cStringView, seed)
+	if isZero(spend) || isZero(view) {
+		return nil, errors.New("NewMaster invalid seed")
+	}
+	return &ExtendedKey{
+		spend:     spend,
+		spendPub:  new(crypto.Point).ScalarMultBase(spend),
+		view:      view,
+		viewPub:   new(crypto.Point).ScalarMultBase(view),
+		chainCode: crypto.Keccak256([]byte(cStringChain), seed),
+	}, nil
+}
+
+// Child returns the child key of the index, indices from HardenedOffset on are hardened
+func (key ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if key.depth == 255 {
+		return nil, errors.New("Child max depth reached")
+	}
+	var data []byte
+	if index >= HardenedOffset {
+		if key.spend == nil {
+			return nil, errors.New("Child a view-only key cannot derive hardened children")
+		}
+		data = key.spend.ToBytes()
+	} else {
+		data = crypto.AppendPointsToBytesArray(nil, []*crypto.Point{key.spendPub, key.viewPub})
+	}
+	i := appendIndex(nil, index)
+
+	tSpend := hashToScalar(cStringSpendChild, key.chainCode, data, i)
+	tView := hashToScalar(cStringViewChild, key.chainCode, data, i)
+	child := &ExtendedKey{
+		spendPub:  new(crypto.Point).Add(key.spendPub, new(crypto.Point).ScalarMultBase(tSpend)),
+		view:      new(crypto.Scalar).Add(key.view, tView),
+		chainCode: crypto.Keccak256([]byte(cStringChainChild), key.chainCode, data, i),
+		depth:     key.depth + 1,
+		index:     index,
+	}
+	if key.spend != nil {
+		child.spend = new(crypto.Scalar).Add(key.spend, tSpend)
+		if isZero(child.spend) {
+			return nil, errors.New("Child invalid child key, skip the index")
+		}
+	}
+	if isZero(child.view) || child.spendPub.IsIdentity() {
+		return nil, errors.New("Child invalid child key, skip the index")
+	}
+	child.viewPub = new(crypto.Point).ScalarMultBase(child.view)
+	return child, nil
+}
+
+// Derive returns the descendant key of the path, relative to key
+func (key ExtendedKey) Derive(path []uint32) (*ExtendedKey, error) {
+	res := &key
+	for _, index := range path {
+		var err error
+		res, err = res.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// DerivePath returns the key of a path string such as m/44'/0'/1, the key must be the master key
+func (key ExtendedKey) DerivePath(path string) (*ExtendedKey, error) {
+	if key.depth != 0 {
+		return nil, errors.New("DerivePath the key is not a master key")
+	}
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return key.Derive(indices)
+}
+
+// ViewOnly returns the key without the spend key, it derives the normal children only
+func (key ExtendedKey) ViewOnly() *ExtendedKey {
+	res := key
+	res.spend = nil
+	return &res
+}
+
+func (key ExtendedKey) IsViewOnly() bool {
+	return key.spend == nil
+}
+
+// SpendKey returns the private spend key, nil for a view-only key
+func (key ExtendedKey) SpendKey() *crypto.Scalar {
+	if key.spend == nil {
+		return nil
+	}
+	return new(crypto.Scalar).Set(key.spend)
+}
+
+func (key ExtendedKey) SpendPublicKey() *crypto.Point {
+	return new(crypto.Point).Set(key.spendPub)
+}
+
+func (key ExtendedKey) ViewKey() *crypto.Scalar {
+	return new(crypto.Scalar).Set(key.view)
+}
+
+func (key ExtendedKey) ViewPublicKey() *crypto.Point {
+	return new(crypto.Point).Set(key.viewPub)
+}
+
+func (key ExtendedKey) ChainCode() []byte {
+	return append([]byte{}, key.chainCode...)
+}
+
+func (key ExtendedKey) Depth() uint8 {
+	return key.depth
+}
+
+// Index returns the index of the key in its parent, 0 for the master key
+func (key ExtendedKey) Index() uint32 {
+	return key.index
+}
+
+// subaddressScalar returns m = Hs("hd_subaddress" || a || account || index)
+func (key ExtendedKey) subaddressScalar(account uint32, index uint32) *crypto.Scalar {
+	return hashToScalar(cStringSubaddress, key.view.ToBytes(), appendIndex(appendIndex(nil, account), index))
+}
+
+// Subaddress returns the public keys (D, C) of the subaddress index of account, as in cryptonote:
+// D = B + m*G and C = a*D with m = Hs("hd_subaddress" || a || account || index).
+// The subaddress (0, 0) is the main address (B, A). A view-only key derives the subaddresses as well
+func (key ExtendedKey) Subaddress(account uint32, index uint32) (*crypto.Point, *crypto.Point) {
+	if account == 0 && index == 0 {
+		return key.SpendPublicKey(), key.ViewPublicKey()
+	}
+	d := new(crypto.Point).Add(key.spendPub, new(crypto.Point).ScalarMultBase(key.subaddressScalar(account, index)))
+	return d, new(crypto.Point).ScalarMult(d, key.view)
+}
+
+// SubaddressSpendKey returns the private spend key b + m of the subaddress
+func (key ExtendedKey) SubaddressSpendKey(account uint32, index uint32) (*crypto.Scalar, error) {
+	if key.spend == nil {
+		return nil, errors.New("SubaddressSpendKey the key is view-only")
+	}
+	if account == 0 && index == 0 {
+		return key.SpendKey(), nil
+	}
+	return new(crypto.Scalar).Add(key.spend, key.subaddressScalar(account, index)), nil
+}
@@ -0,0 +1,121 @@
+package hdkey
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func testSeed() []byte {
+	seed := make([]byte, SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	return seed
+}
+
+// test vectors of the seed 00 01 02 ... 1f
+func TestExtendedKey_Vectors(t *testing.T) {
+	vectors := []struct {
+		path, spend, view, chainCode string
+	}{
+		{"m",
+			"10f417f08bfb0b12bca0be3e1ff07787563313c0909af85222624ef11053a706",
+			"41384ebdb26dbd6d7d1cc9a50c4b44335693fcaa1f5dc8cdc3d16f802527cd07",
+			"ce25f8b3e028f3e2c44b2f8aece2d88585ef4a10e0c8a200213a6417d217db32"},
+		{"m/44'",
+			"7c2076d0d7c5815d7df3478fbaa3133dd7a758bef23d06973f4f02eed2025e0c",
+			"d5562ed03ce732db1d6178829aa3fec7fd94c080746759f2717421c8c7b2b409",
+			"7ba720f475c57992aac48c998203296b304893ea9ef9db9bc7a69c444a9a50f6"},
+		{"m/44'/0'/1",
+			"396bb628e40802fef92aae194d5076ab4e001de2b244b4e553418b26cfc48708",
+			"49a9a33a53de4854af1340f30464b0105a2a4e7732c8be9dc9c1c21589589f05",
+			"d8cfe09e23c32090dd0d2cf5f09a00812bf131b54fa8e4efb04b3a0500dfbbca"},
+		{"m/44'/0'/1/7",
+			"8e3d48489d9eebc64f8161035c73a0604745ae5673936cdad0e4c1a99058650f",
+			"930580f31f566dd08c99a27a42624372d61a2d2201a8f55304c1e643f4c6dc04",
+			"b9346a55304580e20148f388c67c1f382ae97fa9cae72076b0b63962ca344dca"},
+	}
+
+	master, err := NewMaster(testSeed())
+	assert.Equal(t, nil, err)
+	for _, vector := range vectors {
+		key, err := master.DerivePath(vector.path)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, vector.spend, hex.EncodeToString(key.SpendKey().ToBytes()))
+		assert.Equal(t, vector.view, hex.EncodeToString(key.ViewKey().ToBytes()))
+		assert.Equal(t, vector.chainCode, hex.EncodeToString(key.ChainCode()))
+
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(key.SpendKey()), key.SpendPublicKey()))
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(key.ViewKey()), key.ViewPublicKey()))
+	}
+
+	account, _ := master.DerivePath("m/44'/0'")
+	d, c := account.Subaddress(1, 2)
+	assert.Equal(t, "796a14bd6bceb75551f10adf9f561291b621ede026086cded6ad410877129c26", hex.EncodeToString(d.ToBytes()))
+	assert.Equal(t, "06b9c6068fcda565d9bea35320784d3194dfd0619db85b07073be0e70bdf2b8c", hex.EncodeToString(c.ToBytes()))
+}
+
+func TestExtendedKey_ViewOnly(t *testing.T) {
+	master, _ := NewMaster(crypto.RandBytes(SeedSize))
+	account, _ := master.DerivePath("m/44'/0'")
+	watch := account.ViewOnly()
+	assert.Equal(t, true, watch.IsViewOnly())
+	assert.Equal(t, false, account.IsViewOnly())
+	assert.Equal(t, (*crypto.Scalar)(nil), watch.SpendKey())
+
+	// normal children are derived alike, without the spend key
+	path := []uint32{3, 14}
+	full, err := account.Derive(path)
+	assert.Equal(t, nil, err)
+	viewed, err := watch.Derive(path)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, crypto.IsPointEqual(full.SpendPublicKey(), viewed.SpendPublicKey()))
+	assert.Equal(t, full.ViewKey(), viewed.ViewKey())
+	assert.Equal(t, full.ChainCode(), viewed.ChainCode())
+	assert.Equal(t, uint8(4), viewed.Depth())
+	assert.Equal(t, uint32(14), viewed.Index())
+
+	// hardened children need the spend key
+	_, err = watch.Child(HardenedOffset)
+	assert.NotEqual(t, nil, err)
+
+	// hardened and normal children of the same number differ
+	normal, _ := account.Child(1)
+	hardened, _ := account.Child(HardenedOffset + 1)
+	assert.NotEqual(t, normal.SpendKey(), hardened.SpendKey())
+
+	// subaddresses match between the full and the view-only key
+	for _, sub := range [][2]uint32{{0, 0}, {0, 1}, {2, 5}} {
+		d1, c1 := account.Subaddress(sub[0], sub[1])
+		d2, c2 := watch.Subaddress(sub[0], sub[1])
+		assert.Equal(t, true, crypto.IsPointEqual(d1, d2))
+		assert.Equal(t, true, crypto.IsPointEqual(c1, c2))
+
+		// D = (b + m)*G and C = a*D, the main address is (B, A)
+		spend, err := account.SubaddressSpendKey(sub[0], sub[1])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(spend), d1))
+		if sub[0] == 0 && sub[1] == 0 {
+			assert.Equal(t, true, crypto.IsPointEqual(account.ViewPublicKey(), c1))
+		} else {
+			assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMult(d1, account.ViewKey()), c1))
+		}
+	}
+	_, err = watch.SubaddressSpendKey(1, 1)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestExtendedKey_Errors(t *testing.T) {
+	_, err := NewMaster(make([]byte, 16))
+	assert.NotEqual(t, nil, err)
+
+	master, _ := NewMaster(testSeed())
+	_, err = master.DerivePath("44'/0'")
+	assert.NotEqual(t, nil, err)
+	child, _ := master.Child(0)
+	_, err = child.DerivePath("m/1")
+	assert.NotEqual(t, nil, err)
+}
@@ -0,0 +1,52 @@
+package hdkey
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePath parses a derivation path such as m/44'/0'/1, where a trailing ' or h marks a hardened index.
+// "m" alone is the master key and gives an empty path
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, errors.New("ParsePath path must start with m")
+	}
+	res := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+		if hardened {
+			part = part[:len(part)-1]
+		}
+		if part == "" || part[0] == '+' || part[0] == '-' || (len(part) > 1 && part[0] == '0') {
+			return nil, fmt.Errorf("ParsePath invalid index %q", part)
+		}
+		index, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, fmt.Errorf("ParsePath invalid index %q", part)
+		}
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+		res = append(res, uint32(index))
+	}
+	return res, nil
+}
+
+// FormatPath returns the path string of the indices, hardened indices are marked with '
+func FormatPath(path []uint32) string {
+	var sb strings.Builder
+	sb.WriteString("m")
+	for _, index := range path {
+		sb.WriteString("/")
+		if index >= HardenedOffset {
+			sb.WriteString(strconv.FormatUint(uint64(index-HardenedOffset), 10))
+			sb.WriteString("'")
+		} else {
+			sb.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+	return sb.String()
+}
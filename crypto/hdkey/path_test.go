@@ -0,0 +1,41 @@
+package hdkey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	valid := []struct {
+		path     string
+		expected []uint32
+	}{
+		{"m", []uint32{}},
+		{"m/0", []uint32{0}},
+		{"m/44'/0'/1", []uint32{HardenedOffset + 44, HardenedOffset, 1}},
+		{"m/44h/0h/1", []uint32{HardenedOffset + 44, HardenedOffset, 1}},
+		{"m/2147483647'/2147483647", []uint32{1<<32 - 1, HardenedOffset - 1}},
+	}
+	for _, vector := range valid {
+		res, err := ParsePath(vector.path)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, vector.expected, res)
+	}
+
+	invalid := []string{"", "/0", "n/0", "m/", "m//1", "m/a", "m/1''", "m/-1", "m/+1", "m/01",
+		"m/2147483648", "m/4294967296'", "m/1'/", "m/ 1"}
+	for _, path := range invalid {
+		_, err := ParsePath(path)
+		assert.NotEqual(t, nil, err, path)
+	}
+}
+
+func TestFormatPath(t *testing.T) {
+	for _, path := range []string{"m", "m/0", "m/44'/0'/1", "m/2147483647'/5"} {
+		indices, err := ParsePath(path)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, path, FormatPath(indices))
+	}
+	assert.Equal(t, "m/44'/0'/1", FormatPath([]uint32{HardenedOffset + 44, HardenedOffset, 1}))
+}
@@ -0,0 +1,169 @@
+package incmerkle
+
+import (
+	"encoding/binary"
+	"errors"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// Append-only Merkle tree of fixed depth over the output commitments, updated leaf by leaf.
+// The positions are filled from the left, the empty leaves are 0 and
+// empty[h+1] = HashMerkleBranches(empty[h], empty[h]).
+// The state is the frontier: the last leaf and, for the first size-1 leaves, the root of the
+// complete left subtree of height h for every bit h set in size-1, that is at most depth hashes.
+// The root is the path of the last leaf: starting from it, the node of height h is hashed
+// to the right of frontier[h] if bit h of size-1 is set, and to the left of empty[h] otherwise.
+
+const (
+	MaxDepth     = 63
+	DefaultDepth = 32
+)
+
+var emptyHashes = func() [MaxDepth + 1]C25519.Hash {
+	var res [MaxDepth + 1]C25519.Hash
+	for h := 1; h <= MaxDepth; h++ {
+		res[h] = *C25519.HashMerkleBranches(&res[h-1], &res[h-1])
+	}
+	return res
+}()
+
+// EmptyRoot returns the root of the empty tree of depth
+func EmptyRoot(depth int) C25519.Hash {
+	return emptyHashes[depth]
+}
+
+func hashBranches(left, right C25519.Hash) C25519.Hash {
+	return *C25519.HashMerkleBranches(&left, &right)
+}
+
+type Tree struct {
+	depth    int
+	size     uint64
+	last     C25519.Hash
+	frontier []C25519.Hash // frontier[h] is set if bit h of size-1 is set
+}
+
+// NewTree returns an empty tree of 2^depth leaves
+func NewTree(depth int) (*Tree, error) {
+	if depth < 1 || depth > MaxDepth {
+		return nil, errors.New("NewTree invalid depth")
+	}
+	return &Tree{depth: depth, frontier: make([]C25519.Hash, depth)}, nil
+}
+
+func (t Tree) Depth() int {
+	return t.depth
+}
+
+// Size returns the number of leaves
+func (t Tree) Size() uint64 {
+	return t.size
+}
+
+func (t Tree) capacity() uint64 {
+	return uint64(1) << uint(t.depth)
+}
+
+// Append adds the leaf and returns its position
+func (t *Tree) Append(leaf C25519.Hash) (uint64, error) {
+	if t.size == t.capacity() {
+		return 0, errors.New("Append the tree is full")
+	}
+	if t.size > 0 {
+		// fold the last leaf into the frontier of the first size leaves
+		n := t.size - 1
+		node := t.last
+		h := 0
+		for ; n>>uint(h)&1 == 1; h++ {
+			node = hashBranches(t.frontier[h], node)
+		}
+		t.frontier[h] = node
+	}
+	t.last = leaf
+	t.size++
+	return t.size - 1, nil
+}
+
+// Root returns the root of the tree
+func (t Tree) Root() C25519.Hash {
+	if t.size == 0 {
+		return emptyHashes[t.depth]
+	}
+	n := t.size - 1
+	node := t.last
+	for h := 0; h < t.depth; h++ {
+		if n>>uint(h)&1 == 1 {
+			node = hashBranches(t.frontier[h], node)
+		} else {
+			node = hashBranches(node, emptyHashes[h])
+		}
+	}
+	return node
+}
+
+// Witness returns the witness of the last leaf, it follows the tree with Witness.Append
+func (t Tree) Witness() (*Witness, error) {
+	if t.size == 0 {
+		return nil, errors.New("Witness the tree is empty")
+	}
+	return &Witness{
+		depth: t.depth,
+		pos:   t.size - 1,
+		leaf:  t.last,
+		left:  append([]C25519.Hash{}, t.frontier...),
+		size:  t.size,
+	}, nil
+}
+
+// Bytes returns the frontier: depth || size || last || frontier[h] for the bits h of size-1
+func (t Tree) Bytes() []byte {
+	res := make([]byte, 9, 9+(t.depth+1)*C25519.HashLength)
+	res[0] = byte(t.depth)
+	binary.BigEndian.PutUint64(res[1:], t.size)
+	if t.size == 0 {
+		return res
+	}
+	res = append(res, t.last[:]...)
+	for h := 0; h < t.depth; h++ {
+		if (t.size-1)>>uint(h)&1 == 1 {
+			res = append(res, t.frontier[h][:]...)
+		}
+	}
+	return res
+}
+
+// SetBytes parses a frontier of Bytes and returns the number of bytes read
+func (t *Tree) SetBytes(b []byte) (int, error) {
+	if len(b) < 9 {
+		return 0, errors.New("SetBytes invalid frontier size")
+	}
+	res, err := NewTree(int(b[0]))
+	if err != nil {
+		return 0, err
+	}
+	res.size = binary.BigEndian.Uint64(b[1:])
+	if res.size > res.capacity() {
+		return 0, errors.New("SetBytes invalid tree size")
+	}
+	offset := 9
+	if res.size > 0 {
+		hashes := 1
+		for n := res.size - 1; n != 0; n &= n - 1 {
+			hashes++
+		}
+		if len(b) < offset+hashes*C25519.HashLength {
+			return 0, errors.New("SetBytes invalid frontier size")
+		}
+		copy(res.last[:], b[offset:])
+		offset += C25519.HashLength
+		for h := 0; h < res.depth; h++ {
+			if (res.size-1)>>uint(h)&1 == 1 {
+				copy(res.frontier[h][:], b[offset:])
+				offset += C25519.HashLength
+			}
+		}
+	}
+	*t = *res
+	return offset, nil
+}
@@ -0,0 +1,148 @@
+package incmerkle
+
+import (
+	"testing"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLeaf(i int) C25519.Hash {
+	return C25519.Keccak256([]byte("leaf"), []byte{byte(i), byte(i >> 8)})
+}
+
+// naiveRoot hashes the full tree of the leaves, padded with empty leaves
+func naiveRoot(leaves []C25519.Hash, depth int) C25519.Hash {
+	level := make([]C25519.Hash, 1<<uint(depth))
+	copy(level, leaves)
+	for len(level) > 1 {
+		next := make([]C25519.Hash, len(level)/2)
+		for i := range next {
+			next[i] = hashBranches(level[2*i], level[2*i+1])
+		}
+		level = next
+	}
+	return level[0]
+}
+
+func TestTree(t *testing.T) {
+	for _, depth := range []int{1, 2, 4, 5} {
+		tree, err := NewTree(depth)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, EmptyRoot(depth), tree.Root())
+		assert.Equal(t, naiveRoot(nil, depth), tree.Root())
+
+		var leaves []C25519.Hash
+		for i := 0; i < 1<<uint(depth); i++ {
+			leaves = append(leaves, testLeaf(i))
+			pos, err := tree.Append(testLeaf(i))
+			assert.Equal(t, nil, err)
+			assert.Equal(t, uint64(i), pos)
+			assert.Equal(t, naiveRoot(leaves, depth), tree.Root())
+
+			// the frontier gives the same tree
+			res := new(Tree)
+			n, err := res.SetBytes(tree.Bytes())
+			assert.Equal(t, nil, err)
+			assert.Equal(t, len(tree.Bytes()), n)
+			assert.Equal(t, tree.Root(), res.Root())
+			assert.Equal(t, tree.Size(), res.Size())
+		}
+		_, err = tree.Append(testLeaf(0))
+		assert.NotEqual(t, nil, err)
+	}
+
+	for _, depth := range []int{0, MaxDepth + 1} {
+		_, err := NewTree(depth)
+		assert.NotEqual(t, nil, err)
+	}
+	// the frontier of the default depth grows with the bits of the size
+	tree, _ := NewTree(DefaultDepth)
+	for i := 0; i < 8; i++ {
+		tree.Append(testLeaf(i))
+	}
+	assert.Equal(t, 9+4*C25519.HashLength, len(tree.Bytes()))
+}
+
+func TestTree_SetBytes(t *testing.T) {
+	tree, _ := NewTree(4)
+	for i := 0; i < 6; i++ {
+		tree.Append(testLeaf(i))
+	}
+	b := tree.Bytes()
+	invalid := [][]byte{
+		b[:8],
+		b[:len(b)-1],
+		append([]byte{0}, b[1:]...),
+		append([]byte{MaxDepth + 1}, b[1:]...),
+		append([]byte{4, 0, 0, 0, 0, 0, 0, 0, 17}, b[9:]...),
+	}
+	for _, data := range invalid {
+		_, err := new(Tree).SetBytes(data)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestWitness(t *testing.T) {
+	const depth = 5
+	tree, _ := NewTree(depth)
+	_, err := tree.Witness()
+	assert.NotEqual(t, nil, err)
+
+	var witnesses []*Witness
+	for i := 0; i < 1<<depth; i++ {
+		leaf := testLeaf(i)
+		tree.Append(leaf)
+		for _, w := range witnesses {
+			assert.Equal(t, nil, w.Append(leaf))
+		}
+		w, err := tree.Witness()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint64(i), w.Position())
+		assert.Equal(t, leaf, w.Leaf())
+		witnesses = append(witnesses, w)
+
+		root := tree.Root()
+		for j, w := range witnesses {
+			assert.Equal(t, root, w.Root(), "witness %d of %d leaves", j, i+1)
+			path := w.Path()
+			assert.Equal(t, true, VerifyPath(root, w.Leaf(), w.Position(), path))
+			assert.Equal(t, false, VerifyPath(root, testLeaf(j+1), w.Position(), path))
+			assert.Equal(t, false, VerifyPath(root, w.Leaf(), w.Position()^1, path))
+
+			res := new(Witness)
+			assert.Equal(t, nil, res.SetBytes(w.Bytes()), "witness %d of %d leaves", j, i+1)
+			assert.Equal(t, w.Path(), res.Path())
+		}
+	}
+	assert.NotEqual(t, nil, witnesses[0].Append(testLeaf(0)))
+	assert.Equal(t, false, VerifyPath(tree.Root(), testLeaf(0), 1<<depth, witnesses[0].Path()))
+}
+
+func TestWitness_SetBytes(t *testing.T) {
+	tree, _ := NewTree(4)
+	tree.Append(testLeaf(0))
+	w, _ := tree.Witness()
+	for i := 1; i < 4; i++ {
+		tree.Append(testLeaf(i))
+		w.Append(testLeaf(i))
+	}
+	// the witness of leaf 0 has filled the siblings of height 0 and 1
+	b := w.Bytes()
+	restored := new(Witness)
+	assert.Equal(t, nil, restored.SetBytes(b))
+	tree.Append(testLeaf(4))
+	assert.Equal(t, nil, restored.Append(testLeaf(4)))
+	assert.Equal(t, tree.Root(), restored.Root())
+
+	invalid := [][]byte{
+		b[:len(b)-1],
+		append(append([]byte{}, b...), 0),
+		append([]byte{0}, b[1:]...),
+		// position beyond the size
+		append(append([]byte{4, 0, 0, 0, 0, 0, 0, 0, 5}, b[9:9+8]...), b[17:]...),
+	}
+	for _, data := range invalid {
+		assert.NotEqual(t, nil, new(Witness).SetBytes(data))
+	}
+}
@@ -0,0 +1,203 @@
+package incmerkle
+
+import (
+	"encoding/binary"
+	"errors"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// Witness is the authentication path of a leaf, kept up to date with the leaves appended after it.
+// The left siblings of the path are final when the leaf is appended and come from the frontier.
+// The right siblings are filled from the bottom: filled holds the complete ones, and cursor is
+// the tree of the next right sibling while its leaves arrive. The others are still empty.
+
+type Witness struct {
+	depth  int
+	pos    uint64
+	leaf   C25519.Hash
+	left   []C25519.Hash // left[h] is the left sibling if bit h of pos is set
+	filled []C25519.Hash
+	cursor *Tree
+	size   uint64 // size of the tree the witness follows
+}
+
+func (w Witness) Position() uint64 {
+	return w.pos
+}
+
+func (w Witness) Leaf() C25519.Hash {
+	return w.leaf
+}
+
+// nextHeight returns the height of the first right sibling that is not complete, -1 if none
+func (w Witness) nextHeight() int {
+	skip := len(w.filled)
+	for h := 0; h < w.depth; h++ {
+		if w.pos>>uint(h)&1 == 0 {
+			if skip == 0 {
+				return h
+			}
+			skip--
+		}
+	}
+	return -1
+}
+
+// Append adds the next leaf of the tree to the witness
+func (w *Witness) Append(leaf C25519.Hash) error {
+	if w.size == uint64(1)<<uint(w.depth) {
+		return errors.New("Append the tree is full")
+	}
+	if w.cursor != nil {
+		if _, err := w.cursor.Append(leaf); err != nil {
+			return err
+		}
+		if w.cursor.Size() == w.cursor.capacity() {
+			w.filled = append(w.filled, w.cursor.Root())
+			w.cursor = nil
+		}
+	} else {
+		h := w.nextHeight()
+		if h < 0 {
+			return errors.New("Append the path is complete")
+		}
+		if h == 0 {
+			w.filled = append(w.filled, leaf)
+		} else {
+			w.cursor, _ = NewTree(h)
+			w.cursor.Append(leaf)
+		}
+	}
+	w.size++
+	return nil
+}
+
+// Path returns the siblings of the leaf from the bottom up
+func (w Witness) Path() []C25519.Hash {
+	path := make([]C25519.Hash, w.depth)
+	filled := w.filled
+	cursor := w.cursor
+	for h := range path {
+		switch {
+		case w.pos>>uint(h)&1 == 1:
+			path[h] = w.left[h]
+		case len(filled) > 0:
+			path[h] = filled[0]
+			filled = filled[1:]
+		case cursor != nil:
+			path[h] = cursor.Root()
+			cursor = nil
+		default:
+			path[h] = emptyHashes[h]
+		}
+	}
+	return path
+}
+
+// Root returns the root of the tree the witness follows
+func (w Witness) Root() C25519.Hash {
+	return computeRoot(w.leaf, w.pos, w.Path())
+}
+
+func computeRoot(leaf C25519.Hash, pos uint64, path []C25519.Hash) C25519.Hash {
+	node := leaf
+	for h, sibling := range path {
+		if pos>>uint(h)&1 == 1 {
+			node = hashBranches(sibling, node)
+		} else {
+			node = hashBranches(node, sibling)
+		}
+	}
+	return node
+}
+
+// VerifyPath checks that leaf is at pos in the tree of root, path is the Path of its witness
+func VerifyPath(root C25519.Hash, leaf C25519.Hash, pos uint64, path []C25519.Hash) bool {
+	if len(path) == 0 || len(path) > MaxDepth || pos>>uint(len(path)) != 0 {
+		return false
+	}
+	return computeRoot(leaf, pos, path) == root
+}
+
+// Bytes returns depth || pos || size || leaf || left[h] for the bits h of pos ||
+// count of filled || filled || cursor frontier if any
+func (w Witness) Bytes() []byte {
+	res := make([]byte, 17, 17+(2*w.depth+3)*C25519.HashLength)
+	res[0] = byte(w.depth)
+	binary.BigEndian.PutUint64(res[1:], w.pos)
+	binary.BigEndian.PutUint64(res[9:], w.size)
+	res = append(res, w.leaf[:]...)
+	for h := 0; h < w.depth; h++ {
+		if w.pos>>uint(h)&1 == 1 {
+			res = append(res, w.left[h][:]...)
+		}
+	}
+	res = append(res, byte(len(w.filled)))
+	for _, node := range w.filled {
+		res = append(res, node[:]...)
+	}
+	if w.cursor != nil {
+		res = append(res, w.cursor.Bytes()...)
+	}
+	return res
+}
+
+func (w *Witness) SetBytes(b []byte) error {
+	if len(b) < 17+C25519.HashLength+1 {
+		return errors.New("SetBytes invalid witness size")
+	}
+	res := &Witness{
+		depth: int(b[0]),
+		pos:   binary.BigEndian.Uint64(b[1:]),
+		size:  binary.BigEndian.Uint64(b[9:]),
+	}
+	if res.depth < 1 || res.depth > MaxDepth || res.pos >= res.size || res.size > uint64(1)<<uint(res.depth) {
+		return errors.New("SetBytes invalid witness header")
+	}
+	offset := 17
+	copy(res.leaf[:], b[offset:])
+	offset += C25519.HashLength
+
+	res.left = make([]C25519.Hash, res.depth)
+	for h := 0; h < res.depth; h++ {
+		if res.pos>>uint(h)&1 == 1 {
+			if len(b) < offset+C25519.HashLength {
+				return errors.New("SetBytes invalid witness size")
+			}
+			copy(res.left[h][:], b[offset:])
+			offset += C25519.HashLength
+		}
+	}
+
+	if len(b) < offset+1 {
+		return errors.New("SetBytes invalid witness size")
+	}
+	count := int(b[offset])
+	offset++
+	if count > res.depth || len(b) < offset+count*C25519.HashLength {
+		return errors.New("SetBytes invalid witness size")
+	}
+	res.filled = make([]C25519.Hash, count)
+	for i := range res.filled {
+		copy(res.filled[i][:], b[offset:])
+		offset += C25519.HashLength
+	}
+
+	if offset < len(b) {
+		res.cursor = new(Tree)
+		n, err := res.cursor.SetBytes(b[offset:])
+		if err != nil {
+			return err
+		}
+		offset += n
+		if res.cursor.Size() == 0 || res.cursor.Size() == res.cursor.capacity() || res.cursor.Depth() != res.nextHeight() {
+			return errors.New("SetBytes invalid witness cursor")
+		}
+	}
+	if offset != len(b) {
+		return errors.New("SetBytes invalid witness size")
+	}
+	*w = *res
+	return nil
+}
@@ -0,0 +1,272 @@
+package keystore
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/aead"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Password protected wallet file of a (spend, view) key pair.
+// key = KDF(password, salt) with scrypt or argon2id, the salt is random and the cost is in the header
+// file = header || Seal(key, spend || view, additional data = header)
+// header = version || kdf || param1 || param2 || param3 || salt || suite || B || A
+// The params are big endian uint32, (N, r, p) for scrypt and (time, memory in KiB, threads) for argon2id.
+// The public keys B and A stay readable without the password, the AEAD tag authenticates them
+// with the rest of the header, and Decrypt checks that they match the private keys.
+
+type KDF byte
+
+const (
+	Scrypt   KDF = 1
+	Argon2id KDF = 2
+
+	Version  = 1
+	SaltSize = 32
+
+	headerSize    = 2 + 3*4 + SaltSize + 1 + 2*crypto.Ed25519KeySize
+	plaintextSize = 2 * crypto.Ed25519KeySize
+	Size          = headerSize + plaintextSize + aead.Overhead
+
+	// a key file may ask the KDF for at most 1 GiB, scrypt uses 128*N*r bytes
+	maxKDFMemory    = 1 << 30
+	maxScryptN      = 1 << 20
+	maxScryptR      = 32
+	maxScryptP      = 16
+	maxArgonMemory  = maxKDFMemory >> 10 // KiB
+	maxArgonTime    = 1 << 5
+	maxArgonThreads = 64
+)
+
+var InvalidPasswordErr = errors.New("invalid password or corrupted key file")
+
+// Params are the KDF and its cost
+type Params struct {
+	KDF KDF
+	// scrypt
+	N, R, P uint32
+	// argon2id
+	Time, Memory uint32
+	Threads      uint8
+}
+
+var (
+	DefaultScryptParams   = Params{KDF: Scrypt, N: 1 << 18, R: 8, P: 1}
+	DefaultArgon2idParams = Params{KDF: Argon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+)
+
+// ValidateSanity checks the params, with upper bounds on the memory and the time of the KDF
+// so that a crafted key file cannot exhaust the memory or stall the process
+func (params Params) ValidateSanity() error {
+	switch params.KDF {
+	case Scrypt:
+		if params.N < 2 || params.N > maxScryptN || params.N&(params.N-1) != 0 {
+			return errors.New("ValidateSanity scrypt N must be a power of 2 up to 2^20")
+		}
+		if params.R == 0 || params.R > maxScryptR || params.P == 0 || params.P > maxScryptP {
+			return errors.New("ValidateSanity invalid scrypt r and p")
+		}
+		if 128*uint64(params.N)*uint64(params.R) > maxKDFMemory {
+			return errors.New("ValidateSanity scrypt needs more than 1 GiB")
+		}
+	case Argon2id:
+		if params.Time == 0 || params.Time > maxArgonTime {
+			return errors.New("ValidateSanity invalid argon2id time")
+		}
+		if params.Threads == 0 || params.Threads > maxArgonThreads {
+			return errors.New("ValidateSanity invalid argon2id threads")
+		}
+		if params.Memory < 8*uint32(params.Threads) || params.Memory > maxArgonMemory {
+			return errors.New("ValidateSanity invalid argon2id memory")
+		}
+	default:
+		return errors.New("ValidateSanity unknown KDF")
+	}
+	return nil
+}
+
+func (params Params) deriveKey(password []byte, salt []byte) ([]byte, error) {
+	if err := params.ValidateSanity(); err != nil {
+		return nil, err
+	}
+	if params.KDF == Argon2id {
+		return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, crypto.AESKeySize), nil
+	}
+	return scrypt.Key(password, salt, int(params.N), int(params.R), int(params.P), crypto.AESKeySize)
+}
+
+func (params Params) values() [3]uint32 {
+	if params.KDF == Argon2id {
+		return [3]uint32{params.Time, params.Memory, uint32(params.Threads)}
+	}
+	return [3]uint32{params.N, params.R, params.P}
+}
+
+func paramsFromValues(kdf KDF, values [3]uint32) (Params, error) {
+	if kdf == Argon2id {
+		if values[2] > 255 {
+			return Params{}, errors.New("SetBytes invalid argon2id threads")
+		}
+		return Params{KDF: kdf, Time: values[0], Memory: values[1], Threads: uint8(values[2])}, nil
+	}
+	return Params{KDF: kdf, N: values[0], R: values[1], P: values[2]}, nil
+}
+
+type Keystore struct {
+	params   Params
+	salt     []byte
+	suite    aead.Suite
+	spendPub *crypto.Point
+	viewPub  *crypto.Point
+	sealed   []byte
+}
+
+// Encrypt returns the key file of the spend and view keys, protected by the password
+func Encrypt(spend, view *crypto.Scalar, password []byte, params Params) (*Keystore, error) {
+	return EncryptWithRand(rand.Reader, spend, view, password, params)
+}
+
+// EncryptWithRand is Encrypt with the salt and the nonce read from rng
+func EncryptWithRand(rng io.Reader, spend, view *crypto.Scalar, password []byte, params Params) (*Keystore, error) {
+	if spend == nil || view == nil || !spend.ScalarValid() || !view.ScalarValid() {
+		return nil, errors.New("Encrypt invalid keys")
+	}
+	salt, err := crypto.RandBytesFrom(rng, SaltSize)
+	if err != nil {
+		return nil, err
+	}
+	key, err := params.deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	ks := &Keystore{
+		params:   params,
+		salt:     salt,
+		suite:    aead.AES256GCM,
+		spendPub: new(crypto.Point).ScalarMultBase(spend),
+		viewPub:  new(crypto.Point).ScalarMultBase(view),
+	}
+	plaintext := append(spend.ToBytes(), view.ToBytes()...)
+	ks.sealed, err = aead.SealWithRand(rng, ks.suite, key, plaintext, ks.header())
+	if err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Decrypt returns the spend and view keys, InvalidPasswordErr if the password is wrong or the file was modified
+func (ks Keystore) Decrypt(password []byte) (*crypto.Scalar, *crypto.Scalar, error) {
+	key, err := ks.params.deriveKey(password, ks.salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	plaintext, err := aead.Open(ks.suite, key, ks.sealed, ks.header())
+	if err != nil || len(plaintext) != plaintextSize {
+		return nil, nil, InvalidPasswordErr
+	}
+	spend, err := new(crypto.Scalar).FromBytes(plaintext[:crypto.Ed25519KeySize])
+	if err != nil {
+		return nil, nil, err
+	}
+	view, err := new(crypto.Scalar).FromBytes(plaintext[crypto.Ed25519KeySize:])
+	if err != nil {
+		return nil, nil, err
+	}
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(spend), ks.spendPub) ||
+		!crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(view), ks.viewPub) {
+		return nil, nil, errors.New("Decrypt the keys do not match the public keys")
+	}
+	return spend, view, nil
+}
+
+// Verify checks the password and the integrity of the key file
+func (ks Keystore) Verify(password []byte) error {
+	_, _, err := ks.Decrypt(password)
+	return err
+}
+
+// ChangePassword returns the key file of the same keys under the new password and params,
+// with a new salt and nonce
+func (ks Keystore) ChangePassword(oldPassword, newPassword []byte, params Params) (*Keystore, error) {
+	spend, view, err := ks.Decrypt(oldPassword)
+	if err != nil {
+		return nil, err
+	}
+	return Encrypt(spend, view, newPassword, params)
+}
+
+func (ks Keystore) Params() Params {
+	return ks.params
+}
+
+func (ks Keystore) SpendPublicKey() *crypto.Point {
+	return new(crypto.Point).Set(ks.spendPub)
+}
+
+func (ks Keystore) ViewPublicKey() *crypto.Point {
+	return new(crypto.Point).Set(ks.viewPub)
+}
+
+func (ks Keystore) header() []byte {
+	res := make([]byte, 2+3*4, headerSize)
+	res[0] = Version
+	res[1] = byte(ks.params.KDF)
+	for i, v := range ks.params.values() {
+		binary.BigEndian.PutUint32(res[2+4*i:], v)
+	}
+	res = append(res, ks.salt...)
+	res = append(res, byte(ks.suite))
+	return crypto.AppendPointsToBytesArray(res, []*crypto.Point{ks.spendPub, ks.viewPub})
+}
+
+func (ks Keystore) Bytes() []byte {
+	return append(ks.header(), ks.sealed...)
+}
+
+// SetBytes parses a key file, the password is checked by Decrypt
+func (ks *Keystore) SetBytes(b []byte) error {
+	if len(b) != Size {
+		return errors.New("SetBytes invalid key file size")
+	}
+	if b[0] != Version {
+		return errors.New("SetBytes unknown key file version")
+	}
+	var values [3]uint32
+	for i := range values {
+		values[i] = binary.BigEndian.Uint32(b[2+4*i:])
+	}
+	params, err := paramsFromValues(KDF(b[1]), values)
+	if err != nil {
+		return err
+	}
+	if err := params.ValidateSanity(); err != nil {
+		return err
+	}
+	offset := 2 + 3*4
+	salt := append([]byte{}, b[offset:offset+SaltSize]...)
+	offset += SaltSize
+	suite := aead.Suite(b[offset])
+	offset++
+	spendPub, err := new(crypto.Point).FromBytes(b[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	offset += crypto.Ed25519KeySize
+	viewPub, err := new(crypto.Point).FromBytes(b[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+
+	ks.params = params
+	ks.salt = salt
+	ks.suite = suite
+	ks.spendPub = spendPub
+	ks.viewPub = viewPub
+	ks.sealed = append([]byte{}, b[headerSize:]...)
+	return nil
+}
@@ -0,0 +1,163 @@
+package keystore
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// cheap params for the tests
+var (
+	testScrypt   = Params{KDF: Scrypt, N: 1 << 10, R: 8, P: 1}
+	testArgon2id = Params{KDF: Argon2id, Time: 1, Memory: 64, Threads: 1}
+)
+
+func TestKeystore(t *testing.T) {
+	spend := crypto.RandomScalar()
+	view := crypto.RandomScalar()
+	password := []byte("correct horse battery staple")
+	for _, params := range []Params{testScrypt, testArgon2id} {
+		ks, err := Encrypt(spend, view, password, params)
+		assert.Equal(t, nil, err)
+		b := ks.Bytes()
+		assert.Equal(t, Size, len(b))
+
+		res := new(Keystore)
+		assert.Equal(t, nil, res.SetBytes(b))
+		assert.Equal(t, params, res.Params())
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(spend), res.SpendPublicKey()))
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(view), res.ViewPublicKey()))
+
+		s, v, err := res.Decrypt(password)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, spend.ToBytes(), s.ToBytes())
+		assert.Equal(t, view.ToBytes(), v.ToBytes())
+		assert.Equal(t, nil, res.Verify(password))
+
+		_, _, err = res.Decrypt([]byte("wrong"))
+		assert.Equal(t, InvalidPasswordErr, err)
+
+		// the salt is random
+		other, _ := Encrypt(spend, view, password, params)
+		assert.NotEqual(t, b, other.Bytes())
+	}
+}
+
+func TestKeystore_ChangePassword(t *testing.T) {
+	spend := crypto.RandomScalar()
+	view := crypto.RandomScalar()
+	ks, _ := Encrypt(spend, view, []byte("old"), testScrypt)
+
+	_, err := ks.ChangePassword([]byte("bad"), []byte("new"), testArgon2id)
+	assert.Equal(t, InvalidPasswordErr, err)
+
+	changed, err := ks.ChangePassword([]byte("old"), []byte("new"), testArgon2id)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, Argon2id, changed.Params().KDF)
+	assert.Equal(t, InvalidPasswordErr, changed.Verify([]byte("old")))
+	s, v, err := changed.Decrypt([]byte("new"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, spend.ToBytes(), s.ToBytes())
+	assert.Equal(t, view.ToBytes(), v.ToBytes())
+}
+
+func TestKeystore_Integrity(t *testing.T) {
+	password := []byte("password")
+	ks, _ := Encrypt(crypto.RandomScalar(), crypto.RandomScalar(), password, testScrypt)
+	b := ks.Bytes()
+
+	// any change of the salt, the public keys or the ciphertext is detected
+	for _, i := range []int{2 + 3*4, headerSize - 1, headerSize, Size - 1} {
+		tampered := append([]byte{}, b...)
+		tampered[i] ^= 1
+		res := new(Keystore)
+		if res.SetBytes(tampered) != nil {
+			continue
+		}
+		assert.NotEqual(t, nil, res.Verify(password), i)
+	}
+	// another public key
+	tampered := append([]byte{}, b...)
+	copy(tampered[headerSize-crypto.Ed25519KeySize:], crypto.RandomPoint().ToBytes())
+	res := new(Keystore)
+	assert.Equal(t, nil, res.SetBytes(tampered))
+	assert.Equal(t, InvalidPasswordErr, res.Verify(password))
+
+	invalid := [][]byte{
+		b[:Size-1],
+		append(append([]byte{}, b...), 0),
+		append([]byte{Version + 1}, b[1:]...),
+		append([]byte{Version, 3}, b[2:]...),
+		// scrypt N of 3, and of 2^30
+		append([]byte{Version, byte(Scrypt), 0, 0, 0, 3}, b[6:]...),
+		append([]byte{Version, byte(Scrypt), 0x40, 0, 0, 0}, b[6:]...),
+	}
+	for _, data := range invalid {
+		assert.NotEqual(t, nil, new(Keystore).SetBytes(data))
+	}
+}
+
+// TestKeystore_HostileHeader checks that a key file asking the KDF for too much memory
+// is rejected before the KDF runs
+func TestKeystore_HostileHeader(t *testing.T) {
+	password := []byte("password")
+	ks, _ := Encrypt(crypto.RandomScalar(), crypto.RandomScalar(), password, testScrypt)
+	b := ks.Bytes()
+
+	hostile := [][3]uint32{
+		// scrypt N = 2^22, r = 2^20 asks for 512 TiB
+		{1 << 22, 1 << 20, 1},
+		{1 << 20, 1 << 10, 1},
+		{1 << 10, 8, 1 << 20},
+	}
+	for _, values := range hostile {
+		data := append([]byte{}, b...)
+		for i, v := range values {
+			binary.BigEndian.PutUint32(data[2+4*i:], v)
+		}
+		res := new(Keystore)
+		assert.NotEqual(t, nil, res.SetBytes(data), values)
+		// a keystore that skipped SetBytes is still checked by Decrypt
+		res.params = Params{KDF: Scrypt, N: values[0], R: values[1], P: values[2]}
+		res.salt = ks.salt
+		_, _, err := res.Decrypt(password)
+		assert.NotEqual(t, nil, err, values)
+	}
+
+	data := append([]byte{}, b...)
+	data[1] = byte(Argon2id)
+	for i, v := range []uint32{1, 1 << 22, 1} {
+		binary.BigEndian.PutUint32(data[2+4*i:], v)
+	}
+	assert.NotEqual(t, nil, new(Keystore).SetBytes(data))
+}
+
+func TestParams_ValidateSanity(t *testing.T) {
+	for _, params := range []Params{DefaultScryptParams, DefaultArgon2idParams, testScrypt, testArgon2id} {
+		assert.Equal(t, nil, params.ValidateSanity())
+	}
+	invalid := []Params{
+		{},
+		{KDF: Scrypt, N: 1000, R: 8, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 0, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 1 << 15, P: 1 << 15},
+		{KDF: Scrypt, N: 1 << 22, R: 1 << 20, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 64, P: 1},
+		{KDF: Scrypt, N: 1 << 10, R: 8, P: 64},
+		{KDF: Scrypt, N: 1 << 20, R: 16, P: 1},
+		{KDF: Argon2id, Time: 0, Memory: 64, Threads: 1},
+		{KDF: Argon2id, Time: 1, Memory: 64, Threads: 0},
+		{KDF: Argon2id, Time: 1, Memory: 4, Threads: 1},
+		{KDF: Argon2id, Time: 1, Memory: 1 << 30, Threads: 1},
+		{KDF: Argon2id, Time: 1, Memory: 1<<20 + 1, Threads: 1},
+		{KDF: Argon2id, Time: 1 << 8, Memory: 64, Threads: 1},
+		{KDF: Argon2id, Time: 1, Memory: 1024, Threads: 128},
+	}
+	for _, params := range invalid {
+		assert.NotEqual(t, nil, params.ValidateSanity(), params)
+		_, err := Encrypt(crypto.RandomScalar(), crypto.RandomScalar(), nil, params)
+		assert.NotEqual(t, nil, err)
+	}
+}
@@ -0,0 +1,216 @@
+package mnemonic
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Mnemonic phrases of BIP39
+// The entropy of 16 to 32 bytes is followed by the first len/4 bits of its SHA-256 as the checksum,
+// and every 11 bits give the index of a word in a list of 2048 words.
+// The private spend key is encoded as 32 bytes of entropy in 24 words, the last one embeds the checksum.
+// Decoding a phrase reduces the entropy with ScReduce32, so any valid phrase gives a valid spend key.
+//
+// The CryptoNote 25 words scheme needs the word lists of 1626 words of those wallets, which are not
+// part of this library, so the phrases are only compatible with the BIP39 wallets.
+//
+// See reference: https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+
+const (
+	wordBits          = 11
+	listSize          = 1 << wordBits
+	minEntropySize    = 16
+	maxEntropySize    = 32
+	seedIterations    = 2048
+	SeedSize          = 64
+	PrivateKeyWords   = 24
+	cStringSeedPrefix = "mnemonic"
+)
+
+// WordList is a BIP39 list of 2048 words, the words are compared in their NFKD form
+type WordList struct {
+	name      string
+	words     []string
+	index     map[string]int
+	separator string
+}
+
+func newWordList(name string, words string, separator string) *WordList {
+	list := &WordList{
+		name:      name,
+		words:     strings.Split(strings.TrimSpace(words), "\n"),
+		separator: separator,
+	}
+	if len(list.words) != listSize {
+		panic(fmt.Sprintf("mnemonic %s word list has %d words", name, len(list.words)))
+	}
+	list.index = make(map[string]int, listSize)
+	for i, w := range list.words {
+		list.index[norm.NFKD.String(w)] = i
+	}
+	return list
+}
+
+var (
+	English            = newWordList("english", englishWords, " ")
+	Spanish            = newWordList("spanish", spanishWords, " ")
+	French             = newWordList("french", frenchWords, " ")
+	Italian            = newWordList("italian", italianWords, " ")
+	Czech              = newWordList("czech", czechWords, " ")
+	Korean             = newWordList("korean", koreanWords, " ")
+	Japanese           = newWordList("japanese", japaneseWords, "\u3000")
+	ChineseSimplified  = newWordList("chinese_simplified", chineseSimplifiedWords, " ")
+	ChineseTraditional = newWordList("chinese_traditional", chineseTraditionalWords, " ")
+
+	// WordLists are the supported languages, in the order DetectLanguage tries them
+	WordLists = []*WordList{English, Spanish, French, Italian, Czech, Korean, Japanese, ChineseSimplified, ChineseTraditional}
+)
+
+func (list WordList) Name() string {
+	return list.name
+}
+
+// Word returns the word of index, the empty string if index is out of range
+func (list WordList) Word(index int) string {
+	if index < 0 || index >= listSize {
+		return ""
+	}
+	return list.words[index]
+}
+
+// Index returns the index of word in the list
+func (list WordList) Index(word string) (int, bool) {
+	i, ok := list.index[norm.NFKD.String(word)]
+	return i, ok
+}
+
+// splitWords splits a phrase on any white space, such as the ideographic space of Japanese phrases
+func splitWords(phrase string) []string {
+	return strings.Fields(norm.NFKD.String(phrase))
+}
+
+// FromEntropy returns the phrase of the entropy, whose size is 16, 20, 24, 28 or 32 bytes
+func FromEntropy(entropy []byte, list *WordList) (string, error) {
+	n := len(entropy)
+	if n < minEntropySize || n > maxEntropySize || n%4 != 0 {
+		return "", errors.New("FromEntropy invalid entropy size")
+	}
+	if list == nil {
+		return "", errors.New("FromEntropy word list is nil")
+	}
+	checksum := sha256.Sum256(entropy)
+	data := append(append([]byte{}, entropy...), checksum[0])
+	checksumBits := n / 4
+	wordCount := (n*8 + checksumBits) / wordBits
+
+	words := make([]string, wordCount)
+	for w := range words {
+		index := 0
+		for b := w * wordBits; b < (w+1)*wordBits; b++ {
+			index = index<<1 | int(data[b/8]>>(7-uint(b%8))&1)
+		}
+		words[w] = list.words[index]
+	}
+	return strings.Join(words, list.separator), nil
+}
+
+// ToEntropy returns the entropy of the phrase, checking the words and the checksum
+func ToEntropy(phrase string, list *WordList) ([]byte, error) {
+	if list == nil {
+		return nil, errors.New("ToEntropy word list is nil")
+	}
+	words := splitWords(phrase)
+	wordCount := len(words)
+	if wordCount < 12 || wordCount > 24 || wordCount%3 != 0 {
+		return nil, errors.New("ToEntropy invalid number of words")
+	}
+
+	totalBits := wordCount * wordBits
+	checksumBits := totalBits / 33
+	data := make([]byte, (totalBits+7)/8)
+	for w, word := range words {
+		index, ok := list.index[word]
+		if !ok {
+			return nil, fmt.Errorf("ToEntropy word %d is not in the %s word list", w+1, list.name)
+		}
+		for b := 0; b < wordBits; b++ {
+			bit := w*wordBits + b
+			data[bit/8] |= byte(index>>(wordBits-1-uint(b))&1) << (7 - uint(bit%8))
+		}
+	}
+
+	entropy := data[:(totalBits-checksumBits)/8]
+	checksum := sha256.Sum256(entropy)
+	mask := byte(0xff) << (8 - uint(checksumBits))
+	if (data[len(entropy)]^checksum[0])&mask != 0 {
+		return nil, errors.New("ToEntropy invalid checksum")
+	}
+	return entropy, nil
+}
+
+// Validate checks that the phrase is a valid phrase of the list
+func Validate(phrase string, list *WordList) error {
+	_, err := ToEntropy(phrase, list)
+	return err
+}
+
+// DetectLanguage returns the word list of the phrase
+func DetectLanguage(phrase string) (*WordList, error) {
+	words := splitWords(phrase)
+	if len(words) == 0 {
+		return nil, errors.New("DetectLanguage phrase is empty")
+	}
+	for _, list := range WordLists {
+		found := true
+		for _, word := range words {
+			if _, ok := list.index[word]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			return list, nil
+		}
+	}
+	return nil, errors.New("DetectLanguage no word list has all the words")
+}
+
+// FromPrivateKey returns the 24 words phrase of the private spend key
+func FromPrivateKey(sk *crypto.Scalar, list *WordList) (string, error) {
+	if sk == nil || !sk.ScalarValid() {
+		return "", errors.New("FromPrivateKey invalid private key")
+	}
+	return FromEntropy(sk.ToBytes(), list)
+}
+
+// ToPrivateKey returns the private spend key of a 24 words phrase, the entropy is reduced
+// with ScReduce32 so that any valid phrase gives a key
+func ToPrivateKey(phrase string, list *WordList) (*crypto.Scalar, error) {
+	entropy, err := ToEntropy(phrase, list)
+	if err != nil {
+		return nil, err
+	}
+	if len(entropy) != C25519.KeyLength {
+		return nil, errors.New("ToPrivateKey the phrase of a private key has 24 words")
+	}
+	var key C25519.Key
+	copy(key[:], entropy)
+	C25519.ScReduce32(&key)
+	return new(crypto.Scalar).FromBytes(key[:])
+}
+
+// NewSeed returns the BIP39 seed of the phrase and the passphrase, PBKDF2-HMAC-SHA512 of the NFKD forms.
+// The phrase is not checked, call Validate first
+func NewSeed(phrase string, passphrase string) []byte {
+	password := []byte(strings.Join(splitWords(phrase), " "))
+	salt := []byte(norm.NFKD.String(cStringSeedPrefix + passphrase))
+	return pbkdf2.Key(password, salt, seedIterations, SeedSize, sha512.New)
+}
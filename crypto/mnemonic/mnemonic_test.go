@@ -0,0 +1,126 @@
+package mnemonic
+
+import (
+	"encoding/hex"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// test vectors of the trezor reference implementation, the seeds use the passphrase TREZOR
+func TestMnemonic_Vectors(t *testing.T) {
+	vectors := []struct {
+		entropy, phrase, seed string
+	}{
+		{"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04"},
+		{"0000000000000000000000000000000000000000000000000000000000000000",
+			strings.Repeat("abandon ", 23) + "art",
+			"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+			"bc09fca1804f7e69da93c2f2028eb238c227f2e9dda30cd63699232578480a4021b146ad717fbb7e451ce9eb835f43620bf5c514db0f8add49f5d121449d3e87"},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			strings.Repeat("zoo ", 23) + "vote",
+			"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad"},
+		{"68a79eaca2324873eacc50cb9c6eca8cc68ea5d936f98787c60c7ebc74e6ce7c",
+			"hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length",
+			"64c87cde7e12ecf6704ab95bb1408bef047c22db4cc7491c4271d170a1b213d20b385bc1588d9c7b38f1b39d415665b8a9030c9ec653d75e65f847d8fc1fc440"},
+	}
+	for _, vector := range vectors {
+		entropy, _ := hex.DecodeString(vector.entropy)
+		phrase, err := FromEntropy(entropy, English)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, vector.phrase, phrase)
+
+		res, err := ToEntropy(phrase, English)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, entropy, res)
+		assert.Equal(t, vector.seed, hex.EncodeToString(NewSeed(phrase, "TREZOR")))
+	}
+}
+
+func TestMnemonic_WordLists(t *testing.T) {
+	checksums := map[*WordList]uint32{
+		English:            0xc1dbd296,
+		Spanish:            0x266e4f3d,
+		French:             0x3e56b216,
+		Italian:            0x2fc7d07e,
+		Japanese:           0x0acc1419,
+		Korean:             0x4ef461eb,
+		Czech:              0xd1b5fda0,
+		ChineseSimplified:  0xe3721bbf,
+		ChineseTraditional: 0x3c20b443,
+	}
+	assert.Equal(t, len(WordLists), len(checksums))
+	for _, list := range WordLists {
+		raw := strings.Join(list.words, "\n") + "\n"
+		assert.Equal(t, checksums[list], crc32.ChecksumIEEE([]byte(raw)), list.Name())
+		assert.Equal(t, listSize, len(list.index), list.Name())
+		assert.Equal(t, "", list.Word(listSize))
+		i, ok := list.Index(list.Word(1000))
+		assert.Equal(t, true, ok)
+		assert.Equal(t, 1000, i)
+	}
+}
+
+func TestMnemonic_PrivateKey(t *testing.T) {
+	for _, list := range WordLists {
+		sk := crypto.RandomScalar()
+		phrase, err := FromPrivateKey(sk, list)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, PrivateKeyWords, len(splitWords(phrase)))
+		assert.Equal(t, nil, Validate(phrase, list))
+
+		detected, err := DetectLanguage(phrase)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, list, detected)
+
+		res, err := ToPrivateKey(phrase, list)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, sk.ToBytes(), res.ToBytes())
+	}
+
+	// entropy above the group order is reduced to a valid key
+	phrase := strings.Repeat("zoo ", 23) + "vote"
+	sk, err := ToPrivateKey(phrase, English)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, sk.ScalarValid())
+
+	// 12 words phrases do not encode a private key
+	_, err = ToPrivateKey("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", English)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMnemonic_Errors(t *testing.T) {
+	_, err := FromEntropy(make([]byte, 15), English)
+	assert.NotEqual(t, nil, err)
+	_, err = FromEntropy(make([]byte, 18), English)
+	assert.NotEqual(t, nil, err)
+	_, err = FromEntropy(make([]byte, 36), English)
+	assert.NotEqual(t, nil, err)
+
+	invalid := []string{
+		"",
+		// bad checksum
+		strings.Repeat("abandon ", 11) + "abandon",
+		strings.Repeat("zoo ", 23) + "zoo",
+		// unknown word
+		strings.Repeat("abandon ", 11) + "bitcoin",
+		// invalid number of words
+		strings.Repeat("abandon ", 10) + "about",
+	}
+	for _, phrase := range invalid {
+		assert.NotEqual(t, nil, Validate(phrase, English), phrase)
+	}
+
+	_, err = DetectLanguage("abandon zoo bitcoin")
+	assert.NotEqual(t, nil, err)
+
+	// the white space does not matter
+	assert.Equal(t, nil, Validate("  abandon abandon abandon abandon abandon abandon\tabandon abandon abandon abandon abandon\nabout ", English))
+}
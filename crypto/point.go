@@ -3,7 +3,6 @@ package crypto
 import (
 	"crypto/subtle"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
 )
@@ -36,7 +35,7 @@ func (p *Point) SetKey(a *C25519.Key) (*Point, error) {
 
 	var point C25519.ExtendedGroupElement
 	if !point.FromBytes(&p.key){
-		return nil, errors.New("Invalid point value")
+		return nil, InvalidPointErr
 	}
 	return p, nil
 }
@@ -60,7 +59,7 @@ func (p *Point) UnmarshalText(data []byte) (*Point, error) {
 
 	byteSlice, _ := hex.DecodeString(string(data))
 	if len(byteSlice) != Ed25519KeySize {
-		return nil, errors.New("Incorrect key size")
+		return nil, InvalidKeySizeErr
 	}
 	copy(p.key[:], byteSlice)
 	return p, nil
@@ -73,7 +72,7 @@ func (p Point) ToBytes() []byte {
 
 func (p *Point) FromBytes(b []byte) (*Point, error) {
 	if len(b) != Ed25519KeySize {
-		return nil, errors.New("Invalid Ed25519 Key Size")
+		return nil, InvalidKeySizeErr
 	}
 
 	if p == nil {
@@ -85,7 +84,7 @@ func (p *Point) FromBytes(b []byte) (*Point, error) {
 
 	var point C25519.ExtendedGroupElement
 	if !point.FromBytes(&p.key){
-		return nil, errors.New("Invalid point value")
+		return nil, InvalidPointErr
 	}
 
 	return p, nil
@@ -125,40 +124,94 @@ func (p *Point) ScalarMult(pa *Point, a *Scalar) *Point {
 	return p
 }
 
-func (p *Point) MultiScalarMultCached(scalarLs []*Scalar, pointPreComputedLs [][8]C25519.CachedGroupElement) *Point {
-	nSc := len(scalarLs)
+// MulCofactor returns 8 * pa, which is the identity for the points of small order
+func (p *Point) MulCofactor(pa *Point) *Point {
+	if p == nil {
+		p = new(Point)
+	}
+	var e C25519.ExtendedGroupElement
+	var t C25519.ProjectiveGroupElement
+	var r C25519.CompletedGroupElement
+	e.FromBytes(&pa.key)
+	e.ToProjective(&t)
+	C25519.GeMul8(&r, &t)
+	r.ToProjective(&t)
+	t.ToBytes(&p.key)
+	return p
+}
 
-	if nSc != len(pointPreComputedLs) {
-		panic("Cannot MultiscalarMul with different size inputs")
+// InPrimeOrderSubgroup returns whether l * p is the identity, that is p decodes and has no component of small order
+func (p Point) InPrimeOrderSubgroup() bool {
+	if !p.PointValid() {
+		return false
 	}
+	return *C25519.ScalarMultKey(&p.key, &C25519.L) == C25519.Identity
+}
 
-	scalarKeyLs := make([]*C25519.Key, nSc)
-	for i:= 0; i < nSc ; i++ {
-		scalarKeyLs[i] = &scalarLs[i].key
+// MultiScalarMultCached panics on inputs of different sizes, see CheckedMultiScalarMultCached
+func (p *Point) MultiScalarMultCached(scalarLs []*Scalar, pointPreComputedLs [][8]C25519.CachedGroupElement) *Point {
+	res, err := p.CheckedMultiScalarMultCached(scalarLs, pointPreComputedLs)
+	if err != nil {
+		panic(err)
 	}
-	key := C25519.MultiScalarMultKeyCached(pointPreComputedLs, scalarKeyLs)
-	res, _ := new(Point).SetKey(key)
 	return res
 }
 
+// CheckedMultiScalarMultCached returns the sum of scalarLs[i] * A_i from the precomputed tables of the A_i
+func (p *Point) CheckedMultiScalarMultCached(scalarLs []*Scalar, pointPreComputedLs [][8]C25519.CachedGroupElement) (*Point, error) {
+	scalarKeyLs, err := scalarKeys(scalarLs)
+	if err != nil {
+		return nil, err
+	}
+	key, err := C25519.CheckedMultiScalarMultKeyCached(pointPreComputedLs, scalarKeyLs)
+	if err != nil {
+		return nil, err
+	}
+	return p.SetKey(key)
+}
 
+// MultiScalarMult panics on inputs of different sizes, see CheckedMultiScalarMult
 func (p *Point) MultiScalarMult(scalarLs []*Scalar, pointLs []*Point) *Point {
-	nSc := len(scalarLs)
-	nPoint := len(pointLs)
+	res, err := p.CheckedMultiScalarMult(scalarLs, pointLs)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
 
-	if nSc != nPoint {
-		panic("Cannot MultiscalarMul with different size inputs")
+// CheckedMultiScalarMult returns the sum of scalarLs[i] * pointLs[i], an error if the sizes differ
+// or an input is nil or invalid
+func (p *Point) CheckedMultiScalarMult(scalarLs []*Scalar, pointLs []*Point) (*Point, error) {
+	if len(scalarLs) != len(pointLs) {
+		return nil, fmt.Errorf("%w: %d scalars and %d points", LengthMismatchErr, len(scalarLs), len(pointLs))
+	}
+	scalarKeyLs, err := scalarKeys(scalarLs)
+	if err != nil {
+		return nil, err
+	}
+	pointKeyLs := make([]*C25519.Key, len(pointLs))
+	for i, point := range pointLs {
+		if point == nil {
+			return nil, fmt.Errorf("%w: point %d is nil", InvalidPointErr, i)
+		}
+		pointKeyLs[i] = &point.key
+	}
+	key, err := C25519.CheckedMultiScalarMultKey(pointKeyLs, scalarKeyLs)
+	if err != nil {
+		return nil, err
 	}
+	return p.SetKey(key)
+}
 
-	scalarKeyLs := make([]*C25519.Key, nSc)
-	pointKeyLs := make([]*C25519.Key, nSc)
-	for i:= 0; i < nSc ; i++ {
-		scalarKeyLs[i] = &scalarLs[i].key
-		pointKeyLs[i] = &pointLs[i].key
+func scalarKeys(scalarLs []*Scalar) ([]*C25519.Key, error) {
+	res := make([]*C25519.Key, len(scalarLs))
+	for i, sc := range scalarLs {
+		if sc == nil {
+			return nil, fmt.Errorf("%w: scalar %d is nil", InvalidScalarErr, i)
+		}
+		res[i] = &sc.key
 	}
-	key := C25519.MultiScalarMultKey(pointKeyLs, scalarKeyLs)
-	res, _ := new(Point).SetKey(key)
-	return res
+	return res, nil
 }
 
 func (p *Point) InvertScalarMult(pa *Point, a *Scalar) *Point {
@@ -204,9 +257,27 @@ func (p *Point) AddPedersen(a *Scalar, A *Point, b *Scalar, B *Point) *Point {
 	return p
 }
 
-// AddPedersenBase returns aG + bH
+// DoubleScalarMultBaseVartime returns aG + bP in variable time,
+// it must only be used with public inputs such as in verification
+func (p *Point) DoubleScalarMultBaseVartime(a *Scalar, P *Point, b *Scalar) *Point {
+	if p == nil {
+		p = new(Point)
+	}
+	var key C25519.Key
+	C25519.AddKeys2(&key, &a.key, &b.key, &P.key)
+	p.key = key
+	return p
+}
+
+// AddPedersenBase returns aG + bH using the precomputed tables of G and H
 func (p *Point) AddPedersenBase(a *Scalar, b *Scalar) *Point {
-	return p.AddPedersen(a, G, b, H)
+	if p == nil {
+		p = new(Point)
+	}
+	var key C25519.Key
+	C25519.AddKeysFixedBase(&key, &a.key, &b.key, hFixedBase.Table())
+	p.key = key
+	return p
 }
 
 func (p *Point) Sub(pa, pb *Point) *Point {
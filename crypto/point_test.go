@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"crypto/subtle"
+	"errors"
 	"fmt"
 
 	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
@@ -236,3 +237,144 @@ func TestPoint_Set(t*testing.T){
 	fmt.Printf("b after: %v\n", b)
 	fmt.Printf("a after: %v\n", a)
 }
+
+func TestPoint_AddPedersenBase(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		a := RandomScalar()
+		b := RandomScalar()
+
+		res := new(Point).AddPedersenBase(a, b)
+		expected := new(Point).AddPedersen(a, G, b, H)
+		if !IsPointEqual(res, expected) {
+			t.Fatalf("expected AddPedersenBase equals AddPedersen with G and H")
+		}
+	}
+}
+
+func TestPoint_ScalarMultFixedBase(t *testing.T) {
+	base := NewFixedBase(RandomPoint())
+	for i := 0; i < 100; i++ {
+		a := RandomScalar()
+
+		if !IsPointEqual(new(Point).ScalarMultH(a), new(Point).ScalarMult(H, a)) {
+			t.Fatalf("expected ScalarMultH equals ScalarMult with H")
+		}
+		if !IsPointEqual(new(Point).ScalarMultFixedBase(base, a), new(Point).ScalarMult(base.Point(), a)) {
+			t.Fatalf("expected ScalarMultFixedBase equals ScalarMult")
+		}
+	}
+}
+
+func BenchmarkPoint_AddPedersenBase(b *testing.B) {
+	x := RandomScalar()
+	y := RandomScalar()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		new(Point).AddPedersenBase(x, y)
+	}
+}
+
+func TestPoint_DoubleScalarMultBaseVartime(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		a := RandomScalar()
+		b := RandomScalar()
+		P := RandomPoint()
+
+		res := new(Point).DoubleScalarMultBaseVartime(a, P, b)
+		expected := new(Point).AddPedersen(a, G, b, P)
+		if !IsPointEqual(res, expected) {
+			t.Fatalf("expected DoubleScalarMultBaseVartime equals AddPedersen with G")
+		}
+	}
+
+	zero := new(Scalar).FromUint64(0)
+	a := RandomScalar()
+	P := RandomPoint()
+	if !IsPointEqual(new(Point).DoubleScalarMultBaseVartime(a, P, zero), new(Point).ScalarMultBase(a)) {
+		t.Fatalf("expected aG when b is zero")
+	}
+	if !new(Point).DoubleScalarMultBaseVartime(zero, P, zero).IsIdentity() {
+		t.Fatalf("expected identity when both scalars are zero")
+	}
+}
+
+func BenchmarkPoint_DoubleScalarMultBaseVartime(b *testing.B) {
+	x := RandomScalar()
+	y := RandomScalar()
+	P := RandomPoint()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		new(Point).DoubleScalarMultBaseVartime(x, P, y)
+	}
+}
+
+func TestPoint_MulCofactor(t *testing.T) {
+	eight := new(Scalar).FromUint64(8)
+	for i := 0; i < 10; i++ {
+		p := RandomPoint()
+		if !IsPointEqual(new(Point).MulCofactor(p), new(Point).ScalarMult(p, eight)) {
+			t.Fatalf("expected MulCofactor equals ScalarMult by 8")
+		}
+	}
+
+	// a point of order 8
+	key := C25519.HexToKey("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	small, err := new(Point).SetKey(&key)
+	if err != nil {
+		t.Fatalf("expected a valid point of order 8")
+	}
+	if small.IsIdentity() || !new(Point).MulCofactor(small).IsIdentity() {
+		t.Fatalf("expected MulCofactor of a point of order 8 to be the identity")
+	}
+}
+
+func TestPoint_InPrimeOrderSubgroup(t *testing.T) {
+	key := C25519.HexToKey("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	small, _ := new(Point).SetKey(&key)
+	if small.InPrimeOrderSubgroup() {
+		t.Fatalf("expected a point of order 8 to be rejected")
+	}
+	if !new(Point).Identity().InPrimeOrderSubgroup() {
+		t.Fatalf("expected the identity to be in the subgroup")
+	}
+	for i := 0; i < 10; i++ {
+		p := RandomPoint()
+		if !p.InPrimeOrderSubgroup() || !HashToPoint(p.ToBytes()).InPrimeOrderSubgroup() {
+			t.Fatalf("expected a point of prime order")
+		}
+		if new(Point).Add(p, small).InPrimeOrderSubgroup() {
+			t.Fatalf("expected a torsioned point to be rejected")
+		}
+	}
+}
+
+func TestPoint_CheckedMultiScalarMult(t *testing.T) {
+	scalars := []*Scalar{RandomScalar(), RandomScalar()}
+	points := []*Point{RandomPoint(), RandomPoint()}
+
+	res, err := new(Point).CheckedMultiScalarMult(scalars, points)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	expected := new(Point).Add(new(Point).ScalarMult(points[0], scalars[0]), new(Point).ScalarMult(points[1], scalars[1]))
+	if !IsPointEqual(res, expected) {
+		t.Fatalf("expected the sums to match")
+	}
+
+	if _, err := new(Point).CheckedMultiScalarMult(scalars, points[:1]); !errors.Is(err, LengthMismatchErr) {
+		t.Fatalf("expected LengthMismatchErr, got %v", err)
+	}
+	if _, err := new(Point).CheckedMultiScalarMult(scalars, []*Point{points[0], nil}); !errors.Is(err, InvalidPointErr) {
+		t.Fatalf("expected InvalidPointErr, got %v", err)
+	}
+	if _, err := new(Point).CheckedMultiScalarMult([]*Scalar{nil, scalars[1]}, points); !errors.Is(err, InvalidScalarErr) {
+		t.Fatalf("expected InvalidScalarErr, got %v", err)
+	}
+	if _, err := new(Point).FromBytes(make([]byte, 31)); !errors.Is(err, InvalidKeySizeErr) {
+		t.Fatalf("expected InvalidKeySizeErr, got %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// The provers take their randomness from an io.Reader, crypto/rand.Reader by default.
+// A NonceReader can be given instead to derive the nonces from the witness,
+// which makes proofs reproducible for test vectors.
+
+// RandBytesFrom reads length bytes from r
+func RandBytesFrom(r io.Reader, length int) ([]byte, error) {
+	rbytes := make([]byte, length)
+	if _, err := io.ReadFull(r, rbytes); err != nil {
+		return nil, err
+	}
+	return rbytes, nil
+}
+
+// RandomScalarFrom returns a uniformly random scalar read from r
+func RandomScalarFrom(r io.Reader) (*Scalar, error) {
+	key, err := C25519.RandomScalarFrom(r)
+	if err != nil {
+		return nil, err
+	}
+	sc := new(Scalar)
+	sc.key = *key
+	return sc, nil
+}
+
+// RandomScalarsFrom returns n uniformly random scalars read from r
+func RandomScalarsFrom(r io.Reader, n int) ([]*Scalar, error) {
+	res := make([]*Scalar, n)
+	var err error
+	for i := range res {
+		if res[i], err = RandomScalarFrom(r); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// NonceReader is a deterministic random bit generator in the style of RFC 6979,
+// it is HMAC_DRBG with SHA-256 seeded with the secret witness, optional fresh entropy
+// and public data. Without entropy the same inputs always give the same nonces.
+// With entropy the nonces stay secure if either the entropy or the secret is good.
+type NonceReader struct {
+	k []byte
+	v []byte
+}
+
+const nonceEntropySize = 32
+
+// NewNonceReader seeds a NonceReader with the secret, nonceEntropySize bytes read from entropy
+// if entropy is not nil, and data. Each input is length prefixed so that they cannot be confused.
+func NewNonceReader(secret []byte, entropy io.Reader, data ...[]byte) (*NonceReader, error) {
+	var fresh []byte
+	if entropy != nil {
+		var err error
+		if fresh, err = RandBytesFrom(entropy, nonceEntropySize); err != nil {
+			return nil, err
+		}
+	}
+
+	seed := appendWithLength(nil, secret)
+	seed = appendWithLength(seed, fresh)
+	for _, d := range data {
+		seed = appendWithLength(seed, d)
+	}
+
+	nr := &NonceReader{
+		k: make([]byte, sha256.Size),
+		v: make([]byte, sha256.Size),
+	}
+	for i := range nr.v {
+		nr.v[i] = 0x01
+	}
+	nr.update(seed)
+	return nr, nil
+}
+
+func appendWithLength(dst []byte, b []byte) []byte {
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+	dst = append(dst, length[:]...)
+	return append(dst, b...)
+}
+
+func (nr *NonceReader) hmac(data ...[]byte) []byte {
+	mac := hmac.New(sha256.New, nr.k)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// update is the HMAC_DRBG update function, RFC 6979 section 3.2 steps d to g
+func (nr *NonceReader) update(seed []byte) {
+	nr.k = nr.hmac(nr.v, []byte{0x00}, seed)
+	nr.v = nr.hmac(nr.v)
+	if len(seed) == 0 {
+		return
+	}
+	nr.k = nr.hmac(nr.v, []byte{0x01}, seed)
+	nr.v = nr.hmac(nr.v)
+}
+
+// Read fills p with the next bytes of the generator, it never fails
+func (nr *NonceReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		nr.v = nr.hmac(nr.v)
+		n += copy(p[n:], nr.v)
+	}
+	nr.update(nil)
+	return n, nil
+}
@@ -0,0 +1,55 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomScalarFrom(t *testing.T) {
+	sc, err := RandomScalarFrom(bytes.NewReader(RandBytes(64)))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, sc.ScalarValid())
+
+	// the error of the reader is returned instead of a weak scalar
+	rngErr := errors.New("rng failure")
+	_, err = RandomScalarFrom(iotest.ErrReader(rngErr))
+	assert.Equal(t, rngErr, err)
+	_, err = RandomScalarFrom(bytes.NewReader(make([]byte, 63)))
+	assert.NotEqual(t, nil, err)
+	_, err = RandomScalarsFrom(bytes.NewReader(make([]byte, 64*3)), 4)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestNonceReader(t *testing.T) {
+	secret := RandBytes(32)
+	read := func(nr *NonceReader) []byte {
+		res, err := RandBytesFrom(nr, 100)
+		assert.Equal(t, nil, err)
+		return res
+	}
+
+	// without entropy the output only depends on the inputs
+	nr1, err := NewNonceReader(secret, nil, []byte("data"))
+	assert.Equal(t, nil, err)
+	nr2, _ := NewNonceReader(secret, nil, []byte("data"))
+	out1 := read(nr1)
+	assert.Equal(t, out1, read(nr2))
+	assert.NotEqual(t, out1, read(nr1))
+
+	nr3, _ := NewNonceReader(secret, nil, []byte("dat"), []byte("a"))
+	assert.NotEqual(t, out1, read(nr3))
+	nr4, _ := NewNonceReader(RandBytes(32), nil, []byte("data"))
+	assert.NotEqual(t, out1, read(nr4))
+
+	// fresh entropy gives different nonces
+	nr5, err := NewNonceReader(secret, bytes.NewReader(RandBytes(nonceEntropySize)), []byte("data"))
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, out1, read(nr5))
+
+	_, err = NewNonceReader(secret, iotest.ErrReader(errors.New("rng failure")))
+	assert.NotEqual(t, nil, err)
+}
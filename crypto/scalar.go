@@ -28,7 +28,7 @@ func (sc *Scalar) UnmarshalText(data []byte) (*Scalar, error) {
 
 	byteSlice, _ := hex.DecodeString(string(data))
 	if len(byteSlice) != Ed25519KeySize {
-		return nil, errors.New("Incorrect key size")
+		return nil, InvalidKeySizeErr
 	}
 	copy(sc.key[:], byteSlice)
 	return sc, nil
@@ -39,17 +39,38 @@ func (sc Scalar) ToBytes() []byte {
 	return slice[:]
 }
 
+// FromBytes sets sc to the little endian integer b of at most 32 bytes, which must be reduced
 func (sc *Scalar) FromBytes(b []byte) (*Scalar, error) {
-	if sc == nil {
-		sc = new(Scalar)
+	if len(b) > Ed25519KeySize {
+		return nil, InvalidKeySizeErr
 	}
 	var array [Ed25519KeySize]byte
 	copy(array[:], b)
-	sc.key.FromBytes(array)
+	var key C25519.Key
+	key.FromBytes(array)
 
-	if !C25519.ScValid(&sc.key) {
-		return nil, errors.New("Scalar FromBytes bytes array is invalid")
+	if !C25519.ScValid(&key) {
+		return nil, InvalidScalarErr
 	}
+	if sc == nil {
+		sc = new(Scalar)
+	}
+	sc.key = key
+	return sc, nil
+}
+
+// FromBytesModOrder sets sc to the little endian integer b reduced modulo the group order,
+// b is at most 64 bytes such as a SHA-512 digest
+func (sc *Scalar) FromBytesModOrder(b []byte) (*Scalar, error) {
+	if len(b) > 2*Ed25519KeySize {
+		return nil, errors.New("Scalar FromBytesModOrder bytes array is too long")
+	}
+	if sc == nil {
+		sc = new(Scalar)
+	}
+	var wide [2 * Ed25519KeySize]byte
+	copy(wide[:], b)
+	C25519.ScReduce(&sc.key, &wide)
 	return sc, nil
 }
 
@@ -187,36 +208,65 @@ func CheckDuplicateScalarArray(arr []*Scalar) bool {
 	return false
 }
 
+// Invert sets sc = a^-1 mod l. It runs in constant time, so it is safe to use
+// with secret scalars. The inverse of zero is zero.
 func (sc *Scalar) Invert(a *Scalar) *Scalar {
 	if sc == nil {
 		sc = new(Scalar)
 	}
 
-	var inverse_result C25519.Key
-	x := a.key
-
-	reversex := Reverse(x)
-	bigX := new(big.Int).SetBytes(reversex[:])
-
-	reverseL := Reverse(C25519.CurveOrder()) // as speed improvements it can be made constant
-	bigL := new(big.Int).SetBytes(reverseL[:])
+	var res C25519.Key
+	C25519.ScInvert(&res, &a.key)
+	sc.key = res
+	return sc
+}
 
-	var inverse big.Int
-	inverse.ModInverse(bigX, bigL)
+// CheckedInvert sets sc = a^-1 mod l, an error if a is nil, zero or not reduced
+func (sc *Scalar) CheckedInvert(a *Scalar) (*Scalar, error) {
+	if a == nil || !a.ScalarValid() || C25519.ScIsZero(&a.key) {
+		return nil, fmt.Errorf("%w: no inverse", InvalidScalarErr)
+	}
+	return sc.Invert(a), nil
+}
 
-	inverse_bytes := inverse.Bytes()
+// BatchInvert returns the inverses of all scalars in arr using Montgomery's
+// trick, which costs a single inversion plus 3*(len(arr)-1) multiplications.
+// Zero scalars are mapped to zero and do not affect the other results.
+// The input array is not modified.
+func BatchInvert(arr []*Scalar) []*Scalar {
+	n := len(arr)
+	res := make([]*Scalar, n)
+	if n == 0 {
+		return res
+	}
 
-	if len(inverse_bytes) > Ed25519KeySize {
-		panic("Inverse cannot be more than Ed25519KeySize bytes in this domain")
+	// prefix[i] = product of the non-zero scalars among arr[0..i]
+	prefix := make([]C25519.Key, n)
+	acc := C25519.Identity
+	for i := 0; i < n; i++ {
+		if !C25519.ScIsZero(&arr[i].key) {
+			C25519.ScMul(&acc, &acc, &arr[i].key)
+		}
+		prefix[i] = acc
 	}
 
-	for i, j := 0, len(inverse_bytes)-1; i < j; i, j = i+1, j-1 {
-		inverse_bytes[i], inverse_bytes[j] = inverse_bytes[j], inverse_bytes[i]
+	var accInverse C25519.Key
+	C25519.ScInvert(&accInverse, &acc)
+
+	for i := n - 1; i >= 0; i-- {
+		res[i] = new(Scalar)
+		if C25519.ScIsZero(&arr[i].key) {
+			continue
+		}
+		if i > 0 {
+			C25519.ScMul(&res[i].key, &accInverse, &prefix[i-1])
+		} else {
+			res[i].key = accInverse
+		}
+		C25519.ScMul(&accInverse, &accInverse, &arr[i].key)
 	}
-	copy(inverse_result[:], inverse_bytes[:]) // copy the bytes  as they should be
 
-	sc.key = inverse_result
-	return sc
+	return res
 }
 
 func Reverse(x C25519.Key) (result C25519.Key) {
@@ -2,9 +2,11 @@ package crypto
 
 import (
 	"crypto/subtle"
+	"errors"
 	"fmt"
 	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
 	"github.com/stretchr/testify/assert"
+	"math/big"
 	"testing"
 )
 
@@ -153,6 +155,60 @@ func TestScalar_Invert(t *testing.T) {
 	fmt.Printf("bInverse %v\n", bInverse)
 }
 
+func TestScalar_InvertMatchesModInverse(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		a := RandomScalar()
+		inv := new(Scalar).Invert(a)
+
+		tmp := Reverse(a.key)
+		expected := new(big.Int).ModInverse(new(big.Int).SetBytes(tmp[:]), LInt)
+		tmp = Reverse(inv.key)
+		assert.Equal(t, 0, expected.Cmp(new(big.Int).SetBytes(tmp[:])))
+	}
+
+	zero := new(Scalar).FromUint64(0)
+	assert.Equal(t, zero, new(Scalar).Invert(zero))
+}
+
+func TestBatchInvert(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 17, 64} {
+		arr := make([]*Scalar, n)
+		for i := range arr {
+			arr[i] = RandomScalar()
+		}
+		if n > 2 {
+			arr[n/2] = new(Scalar).FromUint64(0)
+		}
+
+		res := BatchInvert(arr)
+		assert.Equal(t, n, len(res))
+		for i := range arr {
+			assert.Equal(t, new(Scalar).Invert(arr[i]), res[i])
+		}
+	}
+}
+
+func BenchmarkScalar_Invert(b *testing.B) {
+	a := RandomScalar()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		new(Scalar).Invert(a)
+	}
+}
+
+func BenchmarkBatchInvert(b *testing.B) {
+	arr := make([]*Scalar, 64)
+	for i := range arr {
+		arr[i] = RandomScalar()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BatchInvert(arr)
+	}
+}
+
 //func BenchmarkIsScalarEqual(b *testing.B){
 //	a := RandomScalar()
 //	c := new(Scalar).Set(a)
@@ -172,3 +228,18 @@ func BenchmarkCompare(b *testing.B){
 		CompareScalar(a, c)
 	}
 }
+
+func TestScalar_CheckedInvert(t *testing.T) {
+	a := RandomScalar()
+	inv, err := new(Scalar).CheckedInvert(a)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, new(Scalar).Invert(a), inv)
+
+	_, err = new(Scalar).CheckedInvert(new(Scalar).FromUint64(0))
+	assert.Equal(t, true, errors.Is(err, InvalidScalarErr))
+	_, err = new(Scalar).CheckedInvert(nil)
+	assert.Equal(t, true, errors.Is(err, InvalidScalarErr))
+
+	_, err = new(Scalar).FromBytes(make([]byte, 33))
+	assert.Equal(t, true, errors.Is(err, InvalidKeySizeErr))
+}
@@ -0,0 +1,157 @@
+package secretsharing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Resharing to a new threshold and new holders without reconstructing the secret (Desmedt-Jajodia).
+// Every dealer i of a set of at least t old holders shares its share s_i with a new polynomial f_i
+// of the new threshold t', f_i(0) = s_i, and sends f_i(j) to the new holder j with the commitment to f_i.
+// The new holder j checks each sub-share against its commitment and the constant term of the commitment
+// against the old commitment at i, then takes s'_j = sum_i lambda_i * f_i(j), lambda_i the Lagrange
+// coefficients of the dealers. The new polynomial is sum_i lambda_i * f_i, whose value at zero is
+// sum_i lambda_i * s_i = secret, and its commitment is sum_i lambda_i * C_i.
+// A dealer which sends a wrong sub-share or commits to another share is named in the error.
+
+// Reshare shares the share to newN holders with the threshold newThreshold, the dealings are verified
+// against the Feldman commitment of the current sharing
+func (share Share) Reshare(newThreshold int, newN int, rng io.Reader) ([]*Share, *FeldmanCommitment, error) {
+	if share.value == nil {
+		return nil, nil, errors.New("Reshare share is nil")
+	}
+	return SplitFeldman(share.value, newThreshold, newN, rng)
+}
+
+// ResharePedersen shares the share and its blind to newN holders with the threshold newThreshold,
+// the dealings are verified against the Pedersen commitment of the current sharing
+func (share Share) ResharePedersen(newThreshold int, newN int, rng io.Reader) ([]*Share, *PedersenCommitment, error) {
+	if share.value == nil || share.blind == nil {
+		return nil, nil, errors.New("ResharePedersen share is not a pedersen share")
+	}
+	return splitPedersen(share.value, share.blind, newThreshold, newN, rng)
+}
+
+// combineReshares returns the new share from the sub-shares of the dealers, with the Lagrange coefficients of the dealers
+func combineReshares(dealers []uint16, subShares []*Share, threshold int) (*Share, []*crypto.Scalar, error) {
+	if len(dealers) < threshold {
+		return nil, nil, errors.New("CombineReshares not enough dealers")
+	}
+	if len(subShares) != len(dealers) {
+		return nil, nil, errors.New("CombineReshares wrong number of sub-shares")
+	}
+	coeffs, err := lagrangeCoefficients(dealers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	res := &Share{value: new(crypto.Scalar).FromUint64(0)}
+	for i, sub := range subShares {
+		if sub == nil || sub.value == nil || sub.index == 0 {
+			return nil, nil, fmt.Errorf("dealer %d: invalid sub-share", dealers[i])
+		}
+		if i == 0 {
+			res.index = sub.index
+			if sub.blind != nil {
+				res.blind = new(crypto.Scalar).FromUint64(0)
+			}
+		}
+		if sub.index != res.index || (sub.blind == nil) != (res.blind == nil) {
+			return nil, nil, fmt.Errorf("dealer %d: sub-share is for another holder", dealers[i])
+		}
+		res.value.MulAdd(coeffs[i], sub.value, res.value)
+		if res.blind != nil {
+			res.blind.MulAdd(coeffs[i], sub.blind, res.blind)
+		}
+	}
+	return res, coeffs, nil
+}
+
+// combineCommitments returns sum_i coeffs[i] * commitments[i], pointwise
+func combineCommitments(coeffs []*crypto.Scalar, commitments [][]*crypto.Point) []*crypto.Point {
+	res := make([]*crypto.Point, len(commitments[0]))
+	column := make([]*crypto.Point, len(commitments))
+	for k := range res {
+		for i := range commitments {
+			column[i] = commitments[i][k]
+		}
+		res[k] = new(crypto.Point).MultiScalarMult(coeffs, column)
+	}
+	return res
+}
+
+// CombineReshares returns the share of a new holder and the commitment of the new sharing from the
+// dealings of the dealers: commitments[i] and subShares[i] are the commitment and the sub-share of dealers[i].
+// The receiver is the old commitment, the new commitment has the same public key
+func (commitment FeldmanCommitment) CombineReshares(dealers []uint16, commitments []*FeldmanCommitment, subShares []*Share) (*Share, *FeldmanCommitment, error) {
+	if !validatePoints(commitment.points) {
+		return nil, nil, errors.New("invalid feldman commitment")
+	}
+	if len(commitments) != len(dealers) || len(subShares) != len(dealers) || len(dealers) == 0 {
+		return nil, nil, errors.New("CombineReshares wrong number of dealings")
+	}
+	if commitments[0] == nil {
+		return nil, nil, fmt.Errorf("dealer %d: commitment is nil", dealers[0])
+	}
+	newThreshold := commitments[0].Threshold()
+	points := make([][]*crypto.Point, len(dealers))
+	for i, dealing := range commitments {
+		if dealing == nil || dealing.Threshold() != newThreshold {
+			return nil, nil, fmt.Errorf("dealer %d: commitment has another threshold", dealers[i])
+		}
+		if err := dealing.Verify(subShares[i]); err != nil {
+			return nil, nil, fmt.Errorf("dealer %d: %v", dealers[i], err)
+		}
+		if dealers[i] == 0 || !crypto.IsPointEqual(dealing.points[0], commitment.VerifyingShare(dealers[i])) {
+			return nil, nil, fmt.Errorf("dealer %d: commitment is not to its share", dealers[i])
+		}
+		points[i] = dealing.points
+	}
+
+	share, coeffs, err := combineReshares(dealers, subShares, commitment.Threshold())
+	if err != nil {
+		return nil, nil, err
+	}
+	return share, &FeldmanCommitment{points: combineCommitments(coeffs, points)}, nil
+}
+
+// CombineReshares returns the share of a new holder and the commitment of the new sharing from the
+// dealings of the dealers, as FeldmanCommitment.CombineReshares does for Pedersen sharings
+func (commitment PedersenCommitment) CombineReshares(dealers []uint16, commitments []*PedersenCommitment, subShares []*Share) (*Share, *PedersenCommitment, error) {
+	if !validatePoints(commitment.points) {
+		return nil, nil, errors.New("invalid pedersen commitment")
+	}
+	if len(commitments) != len(dealers) || len(subShares) != len(dealers) || len(dealers) == 0 {
+		return nil, nil, errors.New("CombineReshares wrong number of dealings")
+	}
+	if commitments[0] == nil {
+		return nil, nil, fmt.Errorf("dealer %d: commitment is nil", dealers[0])
+	}
+	newThreshold := commitments[0].Threshold()
+	points := make([][]*crypto.Point, len(dealers))
+	for i, dealing := range commitments {
+		if dealing == nil || dealing.Threshold() != newThreshold {
+			return nil, nil, fmt.Errorf("dealer %d: commitment has another threshold", dealers[i])
+		}
+		if err := dealing.Verify(subShares[i]); err != nil {
+			return nil, nil, fmt.Errorf("dealer %d: %v", dealers[i], err)
+		}
+		expected, err := EvaluateCommitment(commitment.points, indexScalar(dealers[i]))
+		if err != nil {
+			return nil, nil, err
+		}
+		if dealers[i] == 0 || !crypto.IsPointEqual(dealing.points[0], expected) {
+			return nil, nil, fmt.Errorf("dealer %d: commitment is not to its share", dealers[i])
+		}
+		points[i] = dealing.points
+	}
+
+	share, coeffs, err := combineReshares(dealers, subShares, commitment.Threshold())
+	if err != nil {
+		return nil, nil, err
+	}
+	return share, &PedersenCommitment{points: combineCommitments(coeffs, points)}, nil
+}
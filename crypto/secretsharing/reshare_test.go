@@ -0,0 +1,134 @@
+package secretsharing
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// reshare runs the resharing of the shares of the dealers to newN holders,
+// it returns the sub-shares received by each new holder and the dealings
+func reshare(t *testing.T, dealers []*Share, newThreshold int, newN int) ([]uint16, [][]*Share, []*FeldmanCommitment) {
+	indices := make([]uint16, len(dealers))
+	received := make([][]*Share, newN)
+	commitments := make([]*FeldmanCommitment, len(dealers))
+	for i, dealer := range dealers {
+		subShares, commitment, err := dealer.Reshare(newThreshold, newN, nil)
+		assert.Equal(t, nil, err)
+		indices[i] = dealer.Index()
+		commitments[i] = commitment
+		for j := range subShares {
+			received[j] = append(received[j], subShares[j])
+		}
+	}
+	return indices, received, commitments
+}
+
+func TestReshare_Feldman(t *testing.T) {
+	secret := crypto.RandomScalar()
+	shares, commitment, _ := SplitFeldman(secret, 2, 3, nil)
+
+	// the holders 1 and 3 reshare the 2-of-3 sharing to a 3-of-5 one
+	dealers, received, dealings := reshare(t, []*Share{shares[0], shares[2]}, 3, 5)
+	newShares := make([]*Share, 5)
+	var newCommitment *FeldmanCommitment
+	for j := range newShares {
+		var err error
+		newShares[j], newCommitment, err = commitment.CombineReshares(dealers, dealings, received[j])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, uint16(j+1), newShares[j].Index())
+	}
+
+	assert.Equal(t, 3, newCommitment.Threshold())
+	assert.Equal(t, true, crypto.IsPointEqual(commitment.PublicKey(), newCommitment.PublicKey()))
+	for _, share := range newShares {
+		assert.Equal(t, nil, newCommitment.Verify(share))
+	}
+	res, err := newCommitment.Combine(newShares[1:4])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, secret, res)
+
+	// the old shares do not combine with the new ones
+	res, _ = Combine([]*Share{shares[0], newShares[1], newShares[2]})
+	assert.NotEqual(t, secret, res)
+
+	// one dealer is not enough
+	_, _, err = commitment.CombineReshares(dealers[:1], dealings[:1], received[0][:1])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestReshare_Cheating(t *testing.T) {
+	secret := crypto.RandomScalar()
+	shares, commitment, _ := SplitFeldman(secret, 2, 3, nil)
+
+	// a dealer resharing another value is caught by the old commitment
+	cheater := &Share{index: 2, value: crypto.RandomScalar()}
+	dealers, received, dealings := reshare(t, []*Share{shares[0], cheater}, 2, 2)
+	_, _, err := commitment.CombineReshares(dealers, dealings, received[0])
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "dealer 2")
+
+	// a wrong sub-share is caught by the commitment of the dealing
+	dealers, received, dealings = reshare(t, []*Share{shares[0], shares[1]}, 2, 2)
+	received[1][0] = &Share{index: 2, value: crypto.RandomScalar()}
+	_, _, err = commitment.CombineReshares(dealers, dealings, received[1])
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "dealer 1")
+
+	// sub-shares for another holder
+	_, _, err = commitment.CombineReshares(dealers, dealings, []*Share{received[0][0], received[1][1]})
+	assert.NotEqual(t, nil, err)
+
+	// a dealing with another threshold
+	other, _, _ := shares[1].Reshare(3, 3, nil)
+	_, otherCommitment, _ := shares[1].Reshare(3, 3, nil)
+	_, _, err = commitment.CombineReshares(dealers, []*FeldmanCommitment{dealings[0], otherCommitment}, []*Share{received[0][0], other[0]})
+	assert.NotEqual(t, nil, err)
+
+	_, _, err = commitment.CombineReshares(dealers, dealings, received[0][:1])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestReshare_Pedersen(t *testing.T) {
+	secret := crypto.RandomScalar()
+	shares, commitment, _ := SplitPedersen(secret, 3, 4, nil)
+
+	dealerShares := []*Share{shares[3], shares[0], shares[2]}
+	dealers := make([]uint16, len(dealerShares))
+	dealings := make([]*PedersenCommitment, len(dealerShares))
+	received := make([][]*Share, 2)
+	for i, dealer := range dealerShares {
+		subShares, dealing, err := dealer.ResharePedersen(2, 2, nil)
+		assert.Equal(t, nil, err)
+		dealers[i] = dealer.Index()
+		dealings[i] = dealing
+		for j := range subShares {
+			received[j] = append(received[j], subShares[j])
+		}
+	}
+
+	newShares := make([]*Share, 2)
+	var newCommitment *PedersenCommitment
+	for j := range newShares {
+		var err error
+		newShares[j], newCommitment, err = commitment.CombineReshares(dealers, dealings, received[j])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, nil, newCommitment.Verify(newShares[j]))
+	}
+	res, err := newCommitment.Combine(newShares)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, secret, res)
+
+	// the constant term of a dealing must match the old commitment, value and blind
+	cheater := &Share{index: shares[0].index, value: shares[0].value, blind: crypto.RandomScalar()}
+	subShares, dealing, _ := cheater.ResharePedersen(2, 2, nil)
+	dealings[1] = dealing
+	received[0][1] = subShares[0]
+	_, _, err = commitment.CombineReshares(dealers, dealings, received[0])
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "dealer 1")
+
+	_, _, err = (&Share{index: 1, value: secret}).ResharePedersen(2, 2, nil)
+	assert.NotEqual(t, nil, err)
+}
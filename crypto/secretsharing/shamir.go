@@ -0,0 +1,231 @@
+package secretsharing
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Shamir secret sharing of a scalar, such as a spend key backed up across guardians.
+// The dealer draws f(x) = secret + a_1*x + ... + a_(t-1)*x^(t-1) and gives f(i) to the holder i, i = 1..n.
+// Any t shares give the secret by Lagrange interpolation at zero, fewer shares reveal nothing about it.
+// The verifiable schemes of vss.go let the holders check their shares against commitments to f.
+
+// MaxShares is the max number of shares, the indices are 1..n
+const MaxShares = 1<<16 - 1
+
+const indexSize = 2
+
+// Share is the value f(index) of a sharing polynomial. The shares of a Pedersen sharing
+// also hold the value of the blinding polynomial
+type Share struct {
+	index uint16
+	value *crypto.Scalar
+	blind *crypto.Scalar
+}
+
+// NewShare returns the share of the holder index with the value f(index)
+func NewShare(index uint16, value *crypto.Scalar) (*Share, error) {
+	if index == 0 {
+		return nil, errors.New("NewShare index must not be zero")
+	}
+	if value == nil || !value.ScalarValid() {
+		return nil, errors.New("NewShare invalid value")
+	}
+	return &Share{index: index, value: new(crypto.Scalar).Set(value)}, nil
+}
+
+func (share Share) Index() uint16 {
+	return share.index
+}
+
+func (share Share) Value() *crypto.Scalar {
+	return new(crypto.Scalar).Set(share.value)
+}
+
+// Blind returns the blinding value of a Pedersen share, nil for the other shares
+func (share Share) Blind() *crypto.Scalar {
+	if share.blind == nil {
+		return nil
+	}
+	return new(crypto.Scalar).Set(share.blind)
+}
+
+// Bytes encodes the share as index || value, followed by the blind for a Pedersen share
+func (share Share) Bytes() []byte {
+	if share.value == nil {
+		return []byte{}
+	}
+	res := make([]byte, 0, indexSize+2*crypto.Ed25519KeySize)
+	res = append(res, byte(share.index>>8), byte(share.index))
+	res = append(res, share.value.ToBytes()...)
+	if share.blind != nil {
+		res = append(res, share.blind.ToBytes()...)
+	}
+	return res
+}
+
+func (share *Share) SetBytes(bytes []byte) error {
+	if len(bytes) != indexSize+crypto.Ed25519KeySize && len(bytes) != indexSize+2*crypto.Ed25519KeySize {
+		return errors.New("invalid share size")
+	}
+	index := uint16(bytes[0])<<8 | uint16(bytes[1])
+	if index == 0 {
+		return errors.New("share index must not be zero")
+	}
+	value, err := new(crypto.Scalar).FromBytes(bytes[indexSize : indexSize+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	var blind *crypto.Scalar
+	if len(bytes) > indexSize+crypto.Ed25519KeySize {
+		blind, err = new(crypto.Scalar).FromBytes(bytes[indexSize+crypto.Ed25519KeySize:])
+		if err != nil {
+			return err
+		}
+	}
+	share.index = index
+	share.value = value
+	share.blind = blind
+	return nil
+}
+
+func validateParams(threshold int, n int) error {
+	if threshold < 1 || n < threshold || n > MaxShares {
+		return errors.New("invalid threshold or number of shares")
+	}
+	return nil
+}
+
+func indexScalar(index uint16) *crypto.Scalar {
+	return new(crypto.Scalar).FromUint64(uint64(index))
+}
+
+// randomPolynomial returns the threshold coefficients of a random polynomial with f(0) = secret,
+// a nil secret is drawn at random as well
+func randomPolynomial(secret *crypto.Scalar, threshold int, rng io.Reader) ([]*crypto.Scalar, error) {
+	if rng == nil {
+		rng = rand.Reader
+	}
+	coeffs, err := crypto.RandomScalarsFrom(rng, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if secret != nil {
+		coeffs[0].Set(secret)
+	}
+	return coeffs, nil
+}
+
+// EvaluatePolynomial returns f(x) = coeffs[0] + coeffs[1]*x + ... by Horner's rule
+func EvaluatePolynomial(coeffs []*crypto.Scalar, x *crypto.Scalar) *crypto.Scalar {
+	res := new(crypto.Scalar).FromUint64(0)
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		res.MulAdd(res, x, coeffs[i])
+	}
+	return res
+}
+
+func erase(coeffs []*crypto.Scalar) {
+	for _, c := range coeffs {
+		c.FromUint64(0)
+	}
+}
+
+// Split splits the secret into n shares, any threshold of them give the secret back
+func Split(secret *crypto.Scalar, threshold int, n int, rng io.Reader) ([]*Share, error) {
+	if secret == nil || !secret.ScalarValid() {
+		return nil, errors.New("Split invalid secret")
+	}
+	if err := validateParams(threshold, n); err != nil {
+		return nil, err
+	}
+	coeffs, err := randomPolynomial(secret, threshold, rng)
+	if err != nil {
+		return nil, err
+	}
+	defer erase(coeffs)
+
+	shares := make([]*Share, n)
+	for i := range shares {
+		index := uint16(i + 1)
+		shares[i] = &Share{index: index, value: EvaluatePolynomial(coeffs, indexScalar(index))}
+	}
+	return shares, nil
+}
+
+// LagrangeCoefficient returns the coefficient of index for the interpolation at zero
+// over the indices: prod_{j != index} x_j / (x_j - x_index).
+// The indices must be non zero and distinct, and hold index
+func LagrangeCoefficient(index uint16, indices []uint16) (*crypto.Scalar, error) {
+	num := new(crypto.Scalar).FromUint64(1)
+	den := new(crypto.Scalar).FromUint64(1)
+	found := false
+	for _, j := range indices {
+		if j == 0 {
+			return nil, errors.New("share index must not be zero")
+		}
+		if j == index {
+			if found {
+				return nil, fmt.Errorf("share %d is duplicated", index)
+			}
+			found = true
+			continue
+		}
+		num.Mul(num, indexScalar(j))
+		den.Mul(den, new(crypto.Scalar).Sub(indexScalar(j), indexScalar(index)))
+	}
+	if !found {
+		return nil, fmt.Errorf("share %d is not in the indices", index)
+	}
+	return num.Mul(num, new(crypto.Scalar).Invert(den)), nil
+}
+
+// lagrangeCoefficients returns the coefficients of all the indices, which must be distinct
+func lagrangeCoefficients(indices []uint16) ([]*crypto.Scalar, error) {
+	res := make([]*crypto.Scalar, len(indices))
+	for i, index := range indices {
+		var err error
+		res[i], err = LagrangeCoefficient(index, indices)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func shareIndices(shares []*Share) ([]uint16, error) {
+	indices := make([]uint16, len(shares))
+	for i, share := range shares {
+		if share == nil || share.value == nil {
+			return nil, errors.New("share is nil")
+		}
+		indices[i] = share.index
+	}
+	return indices, nil
+}
+
+// Combine returns the secret from the shares by Lagrange interpolation at zero.
+// With less shares than the threshold the result is a random scalar, not an error,
+// so the verifiable sharings should check it against the commitment
+func Combine(shares []*Share) (*crypto.Scalar, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("Combine shares must not be empty")
+	}
+	indices, err := shareIndices(shares)
+	if err != nil {
+		return nil, err
+	}
+	coeffs, err := lagrangeCoefficients(indices)
+	if err != nil {
+		return nil, err
+	}
+	secret := new(crypto.Scalar).FromUint64(0)
+	for i, share := range shares {
+		secret.MulAdd(coeffs[i], share.value, secret)
+	}
+	return secret, nil
+}
@@ -0,0 +1,115 @@
+package secretsharing
+
+import (
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShamir_SplitCombine(t *testing.T) {
+	secret := crypto.RandomScalar()
+	shares, err := Split(secret, 3, 5, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 5, len(shares))
+
+	// every subset of 3 shares gives the secret
+	for a := 0; a < 5; a++ {
+		for b := a + 1; b < 5; b++ {
+			for c := b + 1; c < 5; c++ {
+				res, err := Combine([]*Share{shares[c], shares[a], shares[b]})
+				assert.Equal(t, nil, err)
+				assert.Equal(t, secret, res)
+			}
+		}
+	}
+	res, _ := Combine(shares)
+	assert.Equal(t, secret, res)
+
+	// 2 shares do not
+	res, err = Combine(shares[:2])
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, secret, res)
+
+	_, err = Combine([]*Share{shares[0], shares[1], shares[0]})
+	assert.NotEqual(t, nil, err)
+	_, err = Combine(nil)
+	assert.NotEqual(t, nil, err)
+
+	// 1-of-n shares are copies of the secret
+	shares, _ = Split(secret, 1, 3, nil)
+	assert.Equal(t, secret, shares[2].Value())
+}
+
+func TestLagrangeCoefficient(t *testing.T) {
+	// f(x) = a0 + a1*x + a2*x^2 interpolates back to a0 at zero from any 3 points
+	coeffs := []*crypto.Scalar{crypto.RandomScalar(), crypto.RandomScalar(), crypto.RandomScalar()}
+	indices := []uint16{2, 5, 9}
+	sum := new(crypto.Scalar).FromUint64(0)
+	for _, index := range indices {
+		lambda, err := LagrangeCoefficient(index, indices)
+		assert.Equal(t, nil, err)
+		sum.MulAdd(lambda, EvaluatePolynomial(coeffs, indexScalar(index)), sum)
+	}
+	assert.Equal(t, 0, crypto.CompareScalar(coeffs[0], sum))
+
+	// index not in the indices, a zero index and a duplicated index
+	for _, invalid := range [][]uint16{{5, 9}, {0, 2, 5}, {2, 2, 5}} {
+		_, err := LagrangeCoefficient(2, invalid)
+		assert.NotEqual(t, nil, err, invalid)
+	}
+
+	// the commitments to the coefficients evaluate to f(x)*G
+	points := make([]*crypto.Point, len(coeffs))
+	for k := range coeffs {
+		points[k] = new(crypto.Point).ScalarMultBase(coeffs[k])
+	}
+	x := indexScalar(7)
+	res, err := EvaluateCommitment(points, x)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(EvaluatePolynomial(coeffs, x)), res))
+	_, err = EvaluateCommitment(nil, x)
+	assert.NotEqual(t, nil, err)
+	_, err = EvaluateCommitment([]*crypto.Point{points[0], nil}, x)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestShamir_Params(t *testing.T) {
+	secret := crypto.RandomScalar()
+	_, err := Split(secret, 0, 3, nil)
+	assert.NotEqual(t, nil, err)
+	_, err = Split(secret, 4, 3, nil)
+	assert.NotEqual(t, nil, err)
+	_, err = Split(secret, 2, MaxShares+1, nil)
+	assert.NotEqual(t, nil, err)
+	_, err = Split(nil, 2, 3, nil)
+	assert.NotEqual(t, nil, err)
+
+	rngErr := errors.New("rng failure")
+	_, err = Split(secret, 2, 3, iotest.ErrReader(rngErr))
+	assert.Equal(t, rngErr, err)
+
+	_, err = NewShare(0, secret)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestShare_Bytes(t *testing.T) {
+	shares, _ := Split(crypto.RandomScalar(), 2, 300, nil)
+	share := shares[299]
+	received := new(Share)
+	assert.Equal(t, nil, received.SetBytes(share.Bytes()))
+	assert.Equal(t, uint16(300), received.Index())
+	assert.Equal(t, share.Value(), received.Value())
+	assert.Equal(t, (*crypto.Scalar)(nil), received.Blind())
+
+	pedersen, _, _ := SplitPedersen(crypto.RandomScalar(), 2, 3, nil)
+	assert.Equal(t, nil, received.SetBytes(pedersen[1].Bytes()))
+	assert.Equal(t, pedersen[1].Blind(), received.Blind())
+
+	assert.NotEqual(t, nil, received.SetBytes(share.Bytes()[1:]))
+	zeroIndex := share.Bytes()
+	zeroIndex[0], zeroIndex[1] = 0, 0
+	assert.NotEqual(t, nil, received.SetBytes(zeroIndex))
+}
@@ -0,0 +1,269 @@
+package secretsharing
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Verifiable secret sharing, the dealer publishes commitments to the coefficients of f
+// Feldman:  C_k = a_k*G, the holder i checks f(i)*G == sum_k i^k * C_k.
+//           C_0 = secret*G is public, so the secret is only computationally hidden
+// Pedersen: a second random polynomial g blinds f, C_k = a_k*G + b_k*H and the holder i
+//           gets (f(i), g(i)), it checks f(i)*G + g(i)*H == sum_k i^k * C_k.
+//           The commitments reveal nothing about the secret
+
+// FeldmanCommitment is the commitment to a sharing polynomial, its length is the threshold
+type FeldmanCommitment struct {
+	points []*crypto.Point
+}
+
+// PedersenCommitment is the hiding commitment to a sharing polynomial, its length is the threshold
+type PedersenCommitment struct {
+	points []*crypto.Point
+}
+
+// SplitFeldman splits the secret as Split does and commits to the polynomial
+func SplitFeldman(secret *crypto.Scalar, threshold int, n int, rng io.Reader) ([]*Share, *FeldmanCommitment, error) {
+	if secret == nil || !secret.ScalarValid() {
+		return nil, nil, errors.New("SplitFeldman invalid secret")
+	}
+	if err := validateParams(threshold, n); err != nil {
+		return nil, nil, err
+	}
+	coeffs, err := randomPolynomial(secret, threshold, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer erase(coeffs)
+
+	commitment := &FeldmanCommitment{points: make([]*crypto.Point, threshold)}
+	for k, a := range coeffs {
+		commitment.points[k] = new(crypto.Point).ScalarMultBase(a)
+	}
+	shares := make([]*Share, n)
+	for i := range shares {
+		index := uint16(i + 1)
+		shares[i] = &Share{index: index, value: EvaluatePolynomial(coeffs, indexScalar(index))}
+	}
+	return shares, commitment, nil
+}
+
+// SplitPedersen splits the secret as Split does, the shares also hold the blinding values
+func SplitPedersen(secret *crypto.Scalar, threshold int, n int, rng io.Reader) ([]*Share, *PedersenCommitment, error) {
+	if secret == nil || !secret.ScalarValid() {
+		return nil, nil, errors.New("SplitPedersen invalid secret")
+	}
+	return splitPedersen(secret, nil, threshold, n, rng)
+}
+
+// splitPedersen shares the secret with the blinding polynomial g(0) = blind, a random one for a nil blind
+func splitPedersen(secret *crypto.Scalar, blind *crypto.Scalar, threshold int, n int, rng io.Reader) ([]*Share, *PedersenCommitment, error) {
+	if err := validateParams(threshold, n); err != nil {
+		return nil, nil, err
+	}
+	coeffs, err := randomPolynomial(secret, threshold, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer erase(coeffs)
+	blinds, err := randomPolynomial(blind, threshold, rng)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer erase(blinds)
+
+	commitment := &PedersenCommitment{points: make([]*crypto.Point, threshold)}
+	for k := range coeffs {
+		commitment.points[k] = new(crypto.Point).AddPedersenBase(coeffs[k], blinds[k])
+	}
+	shares := make([]*Share, n)
+	for i := range shares {
+		index := uint16(i + 1)
+		x := indexScalar(index)
+		shares[i] = &Share{
+			index: index,
+			value: EvaluatePolynomial(coeffs, x),
+			blind: EvaluatePolynomial(blinds, x),
+		}
+	}
+	return shares, commitment, nil
+}
+
+// EvaluateCommitment returns sum_k x^k * points[k], that is f(x)*G from the commitments
+// a_k*G to the coefficients of f
+func EvaluateCommitment(points []*crypto.Point, x *crypto.Scalar) (*crypto.Point, error) {
+	if len(points) == 0 {
+		return nil, errors.New("EvaluateCommitment no commitment")
+	}
+	powers := make([]*crypto.Scalar, len(points))
+	powers[0] = new(crypto.Scalar).FromUint64(1)
+	for k := 1; k < len(powers); k++ {
+		powers[k] = new(crypto.Scalar).Mul(powers[k-1], x)
+	}
+	return new(crypto.Point).CheckedMultiScalarMult(powers, points)
+}
+
+func validatePoints(points []*crypto.Point) bool {
+	if len(points) == 0 || len(points) > MaxShares {
+		return false
+	}
+	for _, p := range points {
+		if p == nil || !p.PointValid() {
+			return false
+		}
+	}
+	return true
+}
+
+func pointsBytes(points []*crypto.Point) []byte {
+	res := make([]byte, 0, indexSize+len(points)*crypto.Ed25519KeySize)
+	res = append(res, byte(len(points)>>8), byte(len(points)))
+	return crypto.AppendPointsToBytesArray(res, points)
+}
+
+func pointsFromBytes(bytes []byte) ([]*crypto.Point, error) {
+	if len(bytes) < indexSize {
+		return nil, errors.New("invalid commitment size")
+	}
+	n := int(bytes[0])<<8 | int(bytes[1])
+	if n == 0 || len(bytes) != indexSize+n*crypto.Ed25519KeySize {
+		return nil, errors.New("invalid commitment size")
+	}
+	points := make([]*crypto.Point, n)
+	for k := range points {
+		offset := indexSize + k*crypto.Ed25519KeySize
+		var err error
+		points[k], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return points, nil
+}
+
+func (commitment FeldmanCommitment) Threshold() int {
+	return len(commitment.points)
+}
+
+// PublicKey returns secret*G, nil for an empty commitment
+func (commitment FeldmanCommitment) PublicKey() *crypto.Point {
+	if len(commitment.points) == 0 {
+		return nil
+	}
+	return new(crypto.Point).Set(commitment.points[0])
+}
+
+// VerifyingShare returns f(index)*G, the public key of the share of index, nil for an empty commitment
+func (commitment FeldmanCommitment) VerifyingShare(index uint16) *crypto.Point {
+	if len(commitment.points) == 0 {
+		return nil
+	}
+	res, err := EvaluateCommitment(commitment.points, indexScalar(index))
+	if err != nil {
+		return nil
+	}
+	return res
+}
+
+// Verify checks that the share is the value of the committed polynomial at its index
+func (commitment FeldmanCommitment) Verify(share *Share) error {
+	if !validatePoints(commitment.points) {
+		return errors.New("invalid feldman commitment")
+	}
+	if share == nil || share.value == nil || share.index == 0 {
+		return errors.New("invalid share")
+	}
+	expected, err := EvaluateCommitment(commitment.points, indexScalar(share.index))
+	if err != nil {
+		return err
+	}
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(share.value), expected) {
+		return fmt.Errorf("share %d does not match the commitment", share.index)
+	}
+	return nil
+}
+
+// Combine returns the secret from the shares, checking every share against the commitment
+func (commitment FeldmanCommitment) Combine(shares []*Share) (*crypto.Scalar, error) {
+	if len(shares) < commitment.Threshold() {
+		return nil, errors.New("Combine not enough shares")
+	}
+	for _, share := range shares {
+		if err := commitment.Verify(share); err != nil {
+			return nil, err
+		}
+	}
+	return Combine(shares)
+}
+
+// Bytes encodes the commitment as the threshold in 2 bytes followed by the points
+func (commitment FeldmanCommitment) Bytes() []byte {
+	if !validatePoints(commitment.points) {
+		return []byte{}
+	}
+	return pointsBytes(commitment.points)
+}
+
+func (commitment *FeldmanCommitment) SetBytes(bytes []byte) error {
+	points, err := pointsFromBytes(bytes)
+	if err != nil {
+		return err
+	}
+	commitment.points = points
+	return nil
+}
+
+func (commitment PedersenCommitment) Threshold() int {
+	return len(commitment.points)
+}
+
+// Verify checks that the share and its blind are the values of the committed polynomials at its index
+func (commitment PedersenCommitment) Verify(share *Share) error {
+	if !validatePoints(commitment.points) {
+		return errors.New("invalid pedersen commitment")
+	}
+	if share == nil || share.value == nil || share.blind == nil || share.index == 0 {
+		return errors.New("invalid pedersen share")
+	}
+	expected, err := EvaluateCommitment(commitment.points, indexScalar(share.index))
+	if err != nil {
+		return err
+	}
+	if !crypto.IsPointEqual(new(crypto.Point).AddPedersenBase(share.value, share.blind), expected) {
+		return fmt.Errorf("share %d does not match the commitment", share.index)
+	}
+	return nil
+}
+
+// Combine returns the secret from the shares, checking every share against the commitment
+func (commitment PedersenCommitment) Combine(shares []*Share) (*crypto.Scalar, error) {
+	if len(shares) < commitment.Threshold() {
+		return nil, errors.New("Combine not enough shares")
+	}
+	for _, share := range shares {
+		if err := commitment.Verify(share); err != nil {
+			return nil, err
+		}
+	}
+	return Combine(shares)
+}
+
+// Bytes encodes the commitment as the threshold in 2 bytes followed by the points
+func (commitment PedersenCommitment) Bytes() []byte {
+	if !validatePoints(commitment.points) {
+		return []byte{}
+	}
+	return pointsBytes(commitment.points)
+}
+
+func (commitment *PedersenCommitment) SetBytes(bytes []byte) error {
+	points, err := pointsFromBytes(bytes)
+	if err != nil {
+		return err
+	}
+	commitment.points = points
+	return nil
+}
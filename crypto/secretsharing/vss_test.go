@@ -0,0 +1,64 @@
+package secretsharing
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeldman(t *testing.T) {
+	secret := crypto.RandomScalar()
+	shares, commitment, err := SplitFeldman(secret, 3, 5, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, commitment.Threshold())
+	assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(secret), commitment.PublicKey()))
+
+	received := new(FeldmanCommitment)
+	assert.Equal(t, nil, received.SetBytes(commitment.Bytes()))
+	for _, share := range shares {
+		assert.Equal(t, nil, received.Verify(share))
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(share.Value()), received.VerifyingShare(share.Index())))
+	}
+
+	res, err := received.Combine(shares[2:])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, secret, res)
+	_, err = received.Combine(shares[:2])
+	assert.NotEqual(t, nil, err)
+
+	// a wrong share names its index
+	bad := &Share{index: 4, value: crypto.RandomScalar()}
+	err = received.Verify(bad)
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "share 4")
+	_, err = received.Combine([]*Share{shares[0], shares[1], bad})
+	assert.NotEqual(t, nil, err)
+
+	assert.NotEqual(t, nil, received.SetBytes(commitment.Bytes()[1:]))
+	assert.NotEqual(t, nil, received.SetBytes([]byte{0, 0}))
+}
+
+func TestPedersen(t *testing.T) {
+	secret := crypto.RandomScalar()
+	shares, commitment, err := SplitPedersen(secret, 2, 4, nil)
+	assert.Equal(t, nil, err)
+
+	received := new(PedersenCommitment)
+	assert.Equal(t, nil, received.SetBytes(commitment.Bytes()))
+	assert.Equal(t, 2, received.Threshold())
+	for _, share := range shares {
+		assert.Equal(t, nil, received.Verify(share))
+	}
+	res, err := received.Combine([]*Share{shares[3], shares[1]})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, secret, res)
+
+	// the commitment does not reveal secret*G
+	assert.Equal(t, false, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(secret), commitment.points[0]))
+
+	// a wrong blind and a missing blind are rejected
+	bad := &Share{index: shares[0].index, value: shares[0].value, blind: crypto.RandomScalar()}
+	assert.NotEqual(t, nil, received.Verify(bad))
+	assert.NotEqual(t, nil, received.Verify(&Share{index: shares[0].index, value: shares[0].value}))
+}
@@ -0,0 +1,298 @@
+package smt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// Sparse Merkle tree of depth 256 over the 32 bytes keys, for instance the set of the spent key images.
+// The bit i of a key, from the most significant bit of key[0], selects the child at depth i.
+// leaf  = Keccak("smt_leaf" || key || value), or 0 for an empty leaf
+// inner = HashMerkleBranches(left, right), and the empty subtree of depth d has the root
+//         empty[d] = HashMerkleBranches(empty[d+1], empty[d+1]) with empty[256] = 0
+// Empty subtrees are not stored. A proof is the 256 siblings of a leaf, the empty ones are
+// replaced with a bit of a bitmap. A proof of the empty leaf of a key shows the key is absent.
+
+const (
+	Depth      = 256
+	bitmapSize = Depth / 8
+
+	cStringLeaf = "smt_leaf"
+)
+
+var emptyHashes = func() [Depth + 1]C25519.Hash {
+	var res [Depth + 1]C25519.Hash
+	for d := Depth - 1; d >= 0; d-- {
+		res[d] = *C25519.HashMerkleBranches(&res[d+1], &res[d+1])
+	}
+	return res
+}()
+
+// EmptyRoot is the root of the empty tree
+func EmptyRoot() C25519.Hash {
+	return emptyHashes[0]
+}
+
+func bit(key *C25519.Key, depth int) int {
+	return int(key[depth/8]>>(7-uint(depth%8))) & 1
+}
+
+func leafHash(key *C25519.Key, value *C25519.Hash) C25519.Hash {
+	data := make([]byte, 0, len(cStringLeaf)+C25519.KeyLength+C25519.HashLength)
+	data = append(data, cStringLeaf...)
+	data = append(data, key[:]...)
+	data = append(data, value[:]...)
+	return C25519.Keccak256(data)
+}
+
+type Tree struct {
+	store Store
+	root  C25519.Hash
+}
+
+// NewTree returns an empty tree on the store
+func NewTree(store Store) *Tree {
+	return &Tree{store: store, root: EmptyRoot()}
+}
+
+// NewTreeWithRoot returns the tree of a root whose nodes are in the store
+func NewTreeWithRoot(store Store, root C25519.Hash) *Tree {
+	return &Tree{store: store, root: root}
+}
+
+func (t Tree) Root() C25519.Hash {
+	return t.root
+}
+
+// children returns the children of the inner node at depth
+func (t Tree) children(node C25519.Hash, depth int) (C25519.Hash, C25519.Hash, error) {
+	var left, right C25519.Hash
+	if node == emptyHashes[depth] {
+		return emptyHashes[depth+1], emptyHashes[depth+1], nil
+	}
+	data, ok, err := t.store.Get(node)
+	if err != nil {
+		return left, right, err
+	}
+	if !ok || len(data) != 2*C25519.HashLength {
+		return left, right, fmt.Errorf("smt missing node %x at depth %d", node[:], depth)
+	}
+	copy(left[:], data[:C25519.HashLength])
+	copy(right[:], data[C25519.HashLength:])
+	return left, right, nil
+}
+
+// Get returns the value of the key, and false if the key is absent
+func (t Tree) Get(key C25519.Key) (C25519.Hash, bool, error) {
+	var value C25519.Hash
+	node := t.root
+	for depth := 0; depth < Depth; depth++ {
+		left, right, err := t.children(node, depth)
+		if err != nil {
+			return value, false, err
+		}
+		node = left
+		if bit(&key, depth) == 1 {
+			node = right
+		}
+	}
+	if node == emptyHashes[Depth] {
+		return value, false, nil
+	}
+	data, ok, err := t.store.Get(node)
+	if err != nil {
+		return value, false, err
+	}
+	if !ok || len(data) != C25519.HashLength {
+		return value, false, errors.New("smt missing leaf")
+	}
+	copy(value[:], data)
+	return value, true, nil
+}
+
+// Has returns true if the key is in the tree
+func (t Tree) Has(key C25519.Key) (bool, error) {
+	_, ok, err := t.Get(key)
+	return ok, err
+}
+
+// Entry is an update of a batch, a nil Value deletes the key
+type Entry struct {
+	Key   C25519.Key
+	Value *C25519.Hash
+}
+
+// Insert sets the value of the key
+func (t *Tree) Insert(key C25519.Key, value C25519.Hash) error {
+	return t.Update([]Entry{{Key: key, Value: &value}})
+}
+
+// Delete removes the key, deleting an absent key does nothing
+func (t *Tree) Delete(key C25519.Key) error {
+	return t.Update([]Entry{{Key: key}})
+}
+
+// Update applies the entries in one pass, the nodes shared by their paths are hashed once.
+// The tree is unchanged if an error is returned
+func (t *Tree) Update(entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	sorted := append([]Entry{}, entries...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i].Key[:], sorted[j].Key[:]) < 0
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Key == sorted[i-1].Key {
+			return fmt.Errorf("smt duplicate key %x", sorted[i].Key[:])
+		}
+	}
+	root, err := t.update(t.root, 0, sorted)
+	if err != nil {
+		return err
+	}
+	t.root = root
+	return nil
+}
+
+// update returns the new root of the subtree node at depth, the entries are sorted and in the subtree
+func (t *Tree) update(node C25519.Hash, depth int, entries []Entry) (C25519.Hash, error) {
+	if depth == Depth {
+		entry := entries[0]
+		if entry.Value == nil {
+			return emptyHashes[Depth], nil
+		}
+		leaf := leafHash(&entry.Key, entry.Value)
+		return leaf, t.store.Put(leaf, entry.Value[:])
+	}
+
+	left, right, err := t.children(node, depth)
+	if err != nil {
+		return node, err
+	}
+	split := sort.Search(len(entries), func(i int) bool {
+		return bit(&entries[i].Key, depth) == 1
+	})
+	if split > 0 {
+		if left, err = t.update(left, depth+1, entries[:split]); err != nil {
+			return node, err
+		}
+	}
+	if split < len(entries) {
+		if right, err = t.update(right, depth+1, entries[split:]); err != nil {
+			return node, err
+		}
+	}
+
+	res := *C25519.HashMerkleBranches(&left, &right)
+	if res == emptyHashes[depth] {
+		return res, nil
+	}
+	return res, t.store.Put(res, append(left[:], right[:]...))
+}
+
+// Proof is the path of a key, Bitmap marks the depths whose sibling is not empty,
+// and Siblings are those siblings from the root down
+type Proof struct {
+	Bitmap   [bitmapSize]byte
+	Siblings []C25519.Hash
+}
+
+// Prove returns the proof of the key, which shows its value if present, and its absence otherwise
+func (t Tree) Prove(key C25519.Key) (*Proof, error) {
+	proof := new(Proof)
+	node := t.root
+	for depth := 0; depth < Depth; depth++ {
+		left, right, err := t.children(node, depth)
+		if err != nil {
+			return nil, err
+		}
+		node = left
+		sibling := right
+		if bit(&key, depth) == 1 {
+			node, sibling = right, left
+		}
+		if sibling != emptyHashes[depth+1] {
+			proof.Bitmap[depth/8] |= 1 << (7 - uint(depth%8))
+			proof.Siblings = append(proof.Siblings, sibling)
+		}
+	}
+	return proof, nil
+}
+
+// computeRoot returns the root of the proof with the leaf at key
+func (proof Proof) computeRoot(key *C25519.Key, leaf C25519.Hash) (C25519.Hash, bool) {
+	next := len(proof.Siblings)
+	node := leaf
+	for depth := Depth - 1; depth >= 0; depth-- {
+		sibling := emptyHashes[depth+1]
+		if proof.Bitmap[depth/8]>>(7-uint(depth%8))&1 == 1 {
+			if next == 0 {
+				return node, false
+			}
+			next--
+			sibling = proof.Siblings[next]
+			if sibling == emptyHashes[depth+1] {
+				return node, false
+			}
+		}
+		if bit(key, depth) == 1 {
+			node = *C25519.HashMerkleBranches(&sibling, &node)
+		} else {
+			node = *C25519.HashMerkleBranches(&node, &sibling)
+		}
+	}
+	return node, next == 0
+}
+
+// VerifyMembership checks that the value of key is value in the tree of root
+func VerifyMembership(root C25519.Hash, key C25519.Key, value C25519.Hash, proof *Proof) bool {
+	if proof == nil {
+		return false
+	}
+	res, ok := proof.computeRoot(&key, leafHash(&key, &value))
+	return ok && res == root
+}
+
+// VerifyNonMembership checks that key is absent from the tree of root
+func VerifyNonMembership(root C25519.Hash, key C25519.Key, proof *Proof) bool {
+	if proof == nil {
+		return false
+	}
+	res, ok := proof.computeRoot(&key, emptyHashes[Depth])
+	return ok && res == root
+}
+
+func (proof Proof) Bytes() []byte {
+	res := make([]byte, 0, bitmapSize+len(proof.Siblings)*C25519.HashLength)
+	res = append(res, proof.Bitmap[:]...)
+	for _, sibling := range proof.Siblings {
+		res = append(res, sibling[:]...)
+	}
+	return res
+}
+
+func (proof *Proof) SetBytes(b []byte) error {
+	if len(b) < bitmapSize || (len(b)-bitmapSize)%C25519.HashLength != 0 {
+		return errors.New("SetBytes invalid proof size")
+	}
+	count := 0
+	for _, v := range b[:bitmapSize] {
+		for ; v != 0; v &= v - 1 {
+			count++
+		}
+	}
+	if len(b) != bitmapSize+count*C25519.HashLength {
+		return errors.New("SetBytes the bitmap does not match the siblings")
+	}
+	copy(proof.Bitmap[:], b[:bitmapSize])
+	proof.Siblings = make([]C25519.Hash, count)
+	for i := range proof.Siblings {
+		copy(proof.Siblings[i][:], b[bitmapSize+i*C25519.HashLength:])
+	}
+	return nil
+}
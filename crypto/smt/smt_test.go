@@ -0,0 +1,147 @@
+package smt
+
+import (
+	"math/rand"
+	"testing"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+	"github.com/stretchr/testify/assert"
+)
+
+func testKeys(n int) ([]C25519.Key, []C25519.Hash) {
+	keys := make([]C25519.Key, n)
+	values := make([]C25519.Hash, n)
+	for i := range keys {
+		keys[i] = C25519.Key(C25519.Keccak256([]byte("key"), []byte{byte(i)}))
+		values[i] = C25519.Keccak256([]byte("value"), []byte{byte(i)})
+	}
+	return keys, values
+}
+
+func TestTree(t *testing.T) {
+	keys, values := testKeys(20)
+	store := NewMemoryStore()
+	tree := NewTree(store)
+	assert.Equal(t, EmptyRoot(), tree.Root())
+
+	for i := range keys {
+		assert.Equal(t, nil, tree.Insert(keys[i], values[i]))
+	}
+	for i := range keys {
+		value, ok, err := tree.Get(keys[i])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, values[i], value)
+	}
+	absent := C25519.Key(C25519.Keccak256([]byte("absent")))
+	ok, err := tree.Has(absent)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, ok)
+
+	// the root does not depend on the order of the inserts
+	other := NewTree(NewMemoryStore())
+	for _, i := range rand.Perm(len(keys)) {
+		assert.Equal(t, nil, other.Insert(keys[i], values[i]))
+	}
+	assert.Equal(t, tree.Root(), other.Root())
+
+	// an older root is still readable
+	full := tree.Root()
+	assert.Equal(t, nil, tree.Delete(keys[3]))
+	ok, _ = tree.Has(keys[3])
+	assert.Equal(t, false, ok)
+	old := NewTreeWithRoot(store, full)
+	ok, _ = old.Has(keys[3])
+	assert.Equal(t, true, ok)
+
+	// updating a value and deleting every key
+	assert.Equal(t, nil, tree.Insert(keys[0], values[1]))
+	value, _, _ := tree.Get(keys[0])
+	assert.Equal(t, values[1], value)
+	for i := range keys {
+		assert.Equal(t, nil, tree.Delete(keys[i]))
+	}
+	assert.Equal(t, EmptyRoot(), tree.Root())
+
+	// a root unknown to the store
+	_, _, err = NewTreeWithRoot(NewMemoryStore(), full).Get(keys[0])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestTree_Update(t *testing.T) {
+	keys, values := testKeys(32)
+	batch := NewTree(NewMemoryStore())
+	sequential := NewTree(NewMemoryStore())
+
+	entries := make([]Entry, len(keys))
+	for i := range keys {
+		entries[i] = Entry{Key: keys[i], Value: &values[i]}
+		assert.Equal(t, nil, sequential.Insert(keys[i], values[i]))
+	}
+	assert.Equal(t, nil, batch.Update(entries))
+	assert.Equal(t, sequential.Root(), batch.Root())
+
+	// deletes and inserts in a batch
+	entries = []Entry{{Key: keys[0]}, {Key: keys[5]}, {Key: keys[1], Value: &values[0]}}
+	assert.Equal(t, nil, batch.Update(entries))
+	assert.Equal(t, nil, sequential.Delete(keys[0]))
+	assert.Equal(t, nil, sequential.Delete(keys[5]))
+	assert.Equal(t, nil, sequential.Insert(keys[1], values[0]))
+	assert.Equal(t, sequential.Root(), batch.Root())
+
+	root := batch.Root()
+	assert.NotEqual(t, nil, batch.Update([]Entry{{Key: keys[2]}, {Key: keys[2], Value: &values[2]}}))
+	assert.Equal(t, root, batch.Root())
+}
+
+func TestTree_Proof(t *testing.T) {
+	keys, values := testKeys(16)
+	tree := NewTree(NewMemoryStore())
+	absent := C25519.Key(C25519.Keccak256([]byte("absent")))
+
+	// proofs of the empty tree
+	proof, err := tree.Prove(absent)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, len(proof.Siblings))
+	assert.Equal(t, true, VerifyNonMembership(tree.Root(), absent, proof))
+
+	for i := range keys {
+		assert.Equal(t, nil, tree.Insert(keys[i], values[i]))
+	}
+	root := tree.Root()
+	for i := range keys {
+		proof, err := tree.Prove(keys[i])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, VerifyMembership(root, keys[i], values[i], proof))
+		assert.Equal(t, false, VerifyMembership(root, keys[i], values[(i+1)%len(keys)], proof))
+		assert.Equal(t, false, VerifyNonMembership(root, keys[i], proof))
+
+		res := new(Proof)
+		assert.Equal(t, nil, res.SetBytes(proof.Bytes()))
+		assert.Equal(t, proof, res)
+	}
+
+	proof, _ = tree.Prove(absent)
+	assert.Equal(t, true, VerifyNonMembership(root, absent, proof))
+	assert.Equal(t, false, VerifyMembership(root, absent, values[0], proof))
+	assert.Equal(t, false, VerifyNonMembership(root, keys[0], proof))
+
+	// a spent key image is absent from the tree before the insert
+	before := tree.Root()
+	assert.Equal(t, nil, tree.Insert(absent, values[0]))
+	assert.Equal(t, false, VerifyNonMembership(tree.Root(), absent, proof))
+	assert.Equal(t, true, VerifyNonMembership(before, absent, proof))
+
+	// malformed proofs
+	proof, _ = tree.Prove(keys[0])
+	short := &Proof{Bitmap: proof.Bitmap, Siblings: proof.Siblings[1:]}
+	assert.Equal(t, false, VerifyMembership(tree.Root(), keys[0], values[0], short))
+	long := &Proof{Bitmap: proof.Bitmap, Siblings: append([]C25519.Hash{{}}, proof.Siblings...)}
+	assert.Equal(t, false, VerifyMembership(tree.Root(), keys[0], values[0], long))
+	assert.Equal(t, false, VerifyMembership(tree.Root(), keys[0], values[0], nil))
+
+	b := proof.Bytes()
+	assert.NotEqual(t, nil, new(Proof).SetBytes(b[:len(b)-1]))
+	assert.NotEqual(t, nil, new(Proof).SetBytes(b[:len(b)-C25519.HashLength]))
+	assert.NotEqual(t, nil, new(Proof).SetBytes(b[:bitmapSize-1]))
+}
@@ -0,0 +1,46 @@
+package smt
+
+import (
+	"sync"
+
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+)
+
+// Store keeps the nodes of the trees by their hash: left || right for the inner nodes and the value
+// for the leaves. The nodes are content addressed and never overwritten, so a store holds the
+// nodes of every root of the trees it served.
+type Store interface {
+	Get(hash C25519.Hash) ([]byte, bool, error)
+	Put(hash C25519.Hash, data []byte) error
+}
+
+// MemoryStore is a Store in a map, safe for concurrent use
+type MemoryStore struct {
+	mu    sync.RWMutex
+	nodes map[C25519.Hash][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nodes: make(map[C25519.Hash][]byte)}
+}
+
+func (s *MemoryStore) Get(hash C25519.Hash) ([]byte, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, ok := s.nodes[hash]
+	return data, ok, nil
+}
+
+func (s *MemoryStore) Put(hash C25519.Hash, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nodes[hash] = append([]byte{}, data...)
+	return nil
+}
+
+// Len returns the number of nodes
+func (s *MemoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.nodes)
+}
@@ -5,10 +5,13 @@ import (
 	"math/big"
 )
 
-// RandBytes generates random bytes with length
+// RandBytes generates random bytes with length,
+// it panics if the OS fails to provide randomness
 func RandBytes(length int) []byte {
-	rbytes := make([]byte, length)
-	rand.Read(rbytes)
+	rbytes, err := RandBytesFrom(rand.Reader, length)
+	if err != nil {
+		panic(err)
+	}
 	return rbytes
 }
 
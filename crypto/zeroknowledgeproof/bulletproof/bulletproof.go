@@ -1,9 +1,12 @@
 package bulletproof
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"io"
 )
 
 /* Bullet proof convinces the verifier
@@ -41,9 +44,26 @@ func (wit *BulletWitness) Set(values []uint64, rands []*crypto.Scalar) {
 	}
 }
 
+// NonceReader returns a reader of nonces derived from the witness and entropy, RFC 6979 style,
+// to be given to Single_ProveWithRand or Agg_ProveWithRand.
+// With a nil entropy the proof is fully deterministic, which is meant for test vectors
+func (wit BulletWitness) NonceReader(entropy io.Reader) (io.Reader, error) {
+	secret := make([]byte, 0, len(wit.values)*(8+crypto.Ed25519KeySize))
+	for i := range wit.values {
+		var value [8]byte
+		binary.BigEndian.PutUint64(value[:], wit.values[i])
+		secret = append(secret, value[:]...)
+		secret = append(secret, wit.rands[i].ToBytes()...)
+	}
+	return crypto.NewNonceReader(secret, entropy, []byte(crypto.CStringBulletProof))
+}
+
 func (proof BulletProof) ValidateSanity() bool {
+	if proof.a == nil || proof.s == nil || proof.t1 == nil || proof.t2 == nil || proof.innerProductProof == nil {
+		return false
+	}
 	for i := 0; i < len(proof.comValues); i++ {
-		if !proof.comValues[i].PointValid() {
+		if proof.comValues[i] == nil || !proof.comValues[i].PointValid() {
 			return false
 		}
 	}
@@ -83,6 +103,16 @@ func (proof *BulletProof) Init() {
 	proof.innerProductProof = new(InnerProductProof)
 }
 
+// GetCommitments returns the commitments to the values proven in range,
+// to be checked against the inputs with crypto.VerifyBalance
+func (proof BulletProof) GetCommitments() []*crypto.Commitment {
+	res := make([]*crypto.Commitment, len(proof.comValues))
+	for i := range proof.comValues {
+		res[i] = crypto.NewCommitmentFromPoint(proof.comValues[i])
+	}
+	return res
+}
+
 func (proof BulletProof) IsNil() bool {
 	if proof.a == nil {
 		return true
@@ -142,6 +172,9 @@ func (proof *BulletProof) SetBytes(bytes []byte) error {
 	lenValues := int(bytes[0])
 	offset := 1
 	var err error
+	if len(bytes) < 1+(lenValues+7)*crypto.Ed25519KeySize {
+		return errors.New("bulletproof has an invalid size")
+	}
 
 	proof.comValues = make([]*crypto.Point, lenValues)
 	for i := 0; i < lenValues; i++ {
@@ -195,7 +228,9 @@ func (proof *BulletProof) SetBytes(bytes []byte) error {
 	offset += crypto.Ed25519KeySize
 
 	proof.innerProductProof = new(InnerProductProof)
-	proof.innerProductProof.SetBytes(bytes[offset:])
+	if err := proof.innerProductProof.SetBytes(bytes[offset:]); err != nil {
+		return err
+	}
 
 	//crypto.Logger.Log.Debugf("AFTER SETBYTES ------------ %v\n", proof.Bytes())
 	return nil
@@ -203,6 +238,12 @@ func (proof *BulletProof) SetBytes(bytes []byte) error {
 
 // Single_Prove creates bullet proof with one element in values array
 func (wit *BulletWitness) Single_Prove() (*BulletProof, error) {
+	return wit.Single_ProveWithRand(rand.Reader)
+}
+
+// Single_ProveWithRand creates bullet proof with one element in values array,
+// reading its random scalars from rng
+func (wit *BulletWitness) Single_ProveWithRand(rng io.Reader) (*BulletProof, error) {
 	// check witness
 	if len(wit.values) != len(wit.rands) || len(wit.values) != 1 {
 		return nil, errors.New("invalid witness of bullet protocol")
@@ -229,14 +270,17 @@ func (wit *BulletWitness) Single_Prove() (*BulletProof, error) {
 	// PAPER LINES 43 - 44
 
 	// generate random alpha
-	alpha := crypto.RandomScalar()
+	alpha, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
 
 	// Commitment to aL, aR: A = h^alpha * G^aL * H^aR
-	A, err := encodeVectors(aL, aR, SingleBulletParam.g, SingleBulletParam.h)
+	A, err := SingleBulletParam.encodeGenerators(aL, aR)
 	if err != nil {
 		return nil, err
 	}
-	A.Add(A, new(crypto.Point).ScalarMult(crypto.H, alpha))
+	A.Add(A, new(crypto.Point).ScalarMultH(alpha))
 
 
 	// PAPER LINES 45 - 47
@@ -244,18 +288,25 @@ func (wit *BulletWitness) Single_Prove() (*BulletProof, error) {
 	sL := make([]*crypto.Scalar, n)
 	sR := make([]*crypto.Scalar, n)
 	for i := range sL {
-		sL[i] = crypto.RandomScalar()
-		sR[i] = crypto.RandomScalar()
+		if sL[i], err = crypto.RandomScalarFrom(rng); err != nil {
+			return nil, err
+		}
+		if sR[i], err = crypto.RandomScalarFrom(rng); err != nil {
+			return nil, err
+		}
 	}
 	// generate random rho
-	rho := crypto.RandomScalar()
+	rho, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
 
 	// commitment to sL, sR
-	S, err := encodeVectors(sL, sR, SingleBulletParam.g, SingleBulletParam.h)
+	S, err := SingleBulletParam.encodeGenerators(sL, sR)
 	if err != nil {
 		return nil, err
 	}
-	S.Add(S, new(crypto.Point).ScalarMult(crypto.H, rho))
+	S.Add(S, new(crypto.Point).ScalarMultH(rho))
 
 	// PAPER LINES 48 - 50
 	// challenge y = H(csHash || comValue || A || S)
@@ -340,8 +391,14 @@ func (wit *BulletWitness) Single_Prove() (*BulletProof, error) {
 
 	// PAPER LINES 51 - 53
 	// commitment to t1, t2
-	tau1 := crypto.RandomScalar()
-	tau2 := crypto.RandomScalar()
+	tau1, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
+	tau2, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
 
 	T1 := new(crypto.Point).AddPedersenBase(t1, tau1)
 	T2 := new(crypto.Point).AddPedersenBase(t2, tau2)
@@ -405,11 +462,11 @@ func (wit *BulletWitness) Single_Prove() (*BulletProof, error) {
 	innerProductWit := new(InnerProductWitness)
 	innerProductWit.a = lVector
 	innerProductWit.b = rVector
-	innerProductWit.p, err = encodeVectors(lVector, rVector, newParam.g, newParam.h)
+	innerProductWit.p, err = newParam.encodeGenerators(lVector, rVector)
 	if err != nil {
 		return nil, err
 	}
-	innerProductWit.p = innerProductWit.p.Add(innerProductWit.p, new(crypto.Point).ScalarMult(SingleBulletParam.u, tHat))
+	innerProductWit.p = innerProductWit.p.Add(innerProductWit.p, SingleBulletParam.scalarMultU(tHat))
 
 	innerProductProof, err := innerProductWit.Prove(newParam)
 	if err != nil {
@@ -432,6 +489,9 @@ func (wit *BulletWitness) Single_Prove() (*BulletProof, error) {
 }
 
 func (proof BulletProof) Single_Verify() (bool, error) {
+	if !proof.ValidateSanity() {
+		return false, errors.New("bulletproof is malformed")
+	}
 	numValue := len(proof.comValues)
 
 	if numValue != 1 {
@@ -484,7 +544,7 @@ func (proof BulletProof) Single_Verify() (bool, error) {
 
 	left1 := new(crypto.Point).AddPedersenBase(proof.tHat, proof.tauX)
 
-	right1 := new(crypto.Point).Add(new(crypto.Point).ScalarMult(comValue, zSquare), new(crypto.Point).ScalarMultBase(deltaYZ))
+	right1 := new(crypto.Point).DoubleScalarMultBaseVartime(deltaYZ, comValue, zSquare)
 	right1.Add(right1, new(crypto.Point).AddPedersen(x, proof.t1, xSquare, proof.t2))
 
 	if !crypto.IsPointEqual(left1, right1) {
@@ -517,6 +577,9 @@ func (proof BulletProof) Single_Verify() (bool, error) {
 }
 
 func (proof BulletProof) Single_Verify_Fast() (bool, error) {
+	if !proof.ValidateSanity() {
+		return false, errors.New("bulletproof is malformed")
+	}
 	numValue := len(proof.comValues)
 
 	if numValue != 1 {
@@ -569,7 +632,7 @@ func (proof BulletProof) Single_Verify_Fast() (bool, error) {
 
 	left1 := new(crypto.Point).AddPedersenBase(proof.tHat, proof.tauX)
 
-	right1 := new(crypto.Point).Add(new(crypto.Point).ScalarMult(comValue, zSquare), new(crypto.Point).ScalarMultBase(deltaYZ))
+	right1 := new(crypto.Point).DoubleScalarMultBaseVartime(deltaYZ, comValue, zSquare)
 	right1.Add(right1, new(crypto.Point).AddPedersen(x, proof.t1, xSquare, proof.t2))
 
 	if !crypto.IsPointEqual(left1, right1) {
@@ -601,8 +664,14 @@ func (proof BulletProof) Single_Verify_Fast() (bool, error) {
 	return true, nil
 }
 
-// Single_Prove creates bullet proof with multi elements in values array
+// Agg_Prove creates bullet proof with multi elements in values array
 func (wit *BulletWitness) Agg_Prove() (*BulletProof, error) {
+	return wit.Agg_ProveWithRand(rand.Reader)
+}
+
+// Agg_ProveWithRand creates bullet proof with multi elements in values array,
+// reading its random scalars from rng
+func (wit *BulletWitness) Agg_ProveWithRand(rng io.Reader) (*BulletProof, error) {
 	proof := new(BulletProof)
 
 	numValue := len(wit.values)
@@ -651,33 +720,43 @@ func (wit *BulletWitness) Agg_Prove() (*BulletProof, error) {
 	}
 
 	// random alpha
-	alpha := crypto.RandomScalar()
+	alpha, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
 
 	// Commitment to aL, aR: A = h^alpha * G^aL * H^aR
-	A, err := encodeVectors(aL, aR, aggParam.g, aggParam.h)
+	A, err := aggParam.encodeGenerators(aL, aR)
 	if err != nil {
 		return nil, err
 	}
-	A.Add(A, new(crypto.Point).ScalarMult(crypto.H, alpha))
+	A.Add(A, new(crypto.Point).ScalarMultH(alpha))
 	proof.a = A
 
 	// Random blinding vectors sL, sR
 	sL := make([]*crypto.Scalar, n*numValuePad)
 	sR := make([]*crypto.Scalar, n*numValuePad)
 	for i := range sL {
-		sL[i] = crypto.RandomScalar()
-		sR[i] = crypto.RandomScalar()
+		if sL[i], err = crypto.RandomScalarFrom(rng); err != nil {
+			return nil, err
+		}
+		if sR[i], err = crypto.RandomScalarFrom(rng); err != nil {
+			return nil, err
+		}
 	}
 
 	// random rho
-	rho := crypto.RandomScalar()
+	rho, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
 
 	// Commitment to sL, sR : S = h^rho * G^sL * H^sR
-	S, err := encodeVectors(sL, sR, aggParam.g, aggParam.h)
+	S, err := aggParam.encodeGenerators(sL, sR)
 	if err != nil {
 		return nil, err
 	}
-	S.Add(S, new(crypto.Point).ScalarMult(crypto.H, rho))
+	S.Add(S, new(crypto.Point).ScalarMultH(rho))
 	proof.s = S
 
 	// challenge y, z
@@ -766,8 +845,14 @@ func (wit *BulletWitness) Agg_Prove() (*BulletProof, error) {
 	}
 
 	// commitment to t1, t2
-	tau1 := crypto.RandomScalar()
-	tau2 := crypto.RandomScalar()
+	tau1, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
+	tau2, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
 
 	proof.t1 = new(crypto.Point).AddPedersenBase(t1, tau1)
 	proof.t2 = new(crypto.Point).AddPedersenBase(t2, tau2)
@@ -832,11 +917,11 @@ func (wit *BulletWitness) Agg_Prove() (*BulletProof, error) {
 	innerProductWit := new(InnerProductWitness)
 	innerProductWit.a = lVector
 	innerProductWit.b = rVector
-	innerProductWit.p, err = encodeVectors(lVector, rVector, aggParam.g, aggParam.h)
+	innerProductWit.p, err = aggParam.encodeGenerators(lVector, rVector)
 	if err != nil {
 		return nil, err
 	}
-	innerProductWit.p = innerProductWit.p.Add(innerProductWit.p, new(crypto.Point).ScalarMult(aggParam.u, proof.tHat))
+	innerProductWit.p = innerProductWit.p.Add(innerProductWit.p, aggParam.scalarMultU(proof.tHat))
 
 	proof.innerProductProof, err = innerProductWit.Prove(aggParam)
 	if err != nil {
@@ -847,6 +932,9 @@ func (wit *BulletWitness) Agg_Prove() (*BulletProof, error) {
 }
 
 func (proof BulletProof) Agg_Verify() (bool, error) {
+	if !proof.ValidateSanity() {
+		return false, errors.New("bulletproof is malformed")
+	}
 	numValue := len(proof.comValues)
 	if numValue > maxNOut {
 		return false, errors.New("Must less than maxNOut")
@@ -918,10 +1006,14 @@ func (proof BulletProof) Agg_Verify() (bool, error) {
 	left1 := new(crypto.Point).AddPedersenBase(proof.tHat, proof.tauX)
 
 	right1 := new(crypto.Point).ScalarMult(proof.t2, xSquare)
-	right1.Add(right1, new(crypto.Point).AddPedersen(deltaYZ, crypto.G, x, proof.t1))
+	right1.Add(right1, new(crypto.Point).DoubleScalarMultBaseVartime(deltaYZ, proof.t1, x))
 
 	expVector := vectorMulScalar(powerVector(z, numValuePad), zSquare)
-	right1.Add(right1, new(crypto.Point).MultiScalarMult(expVector, tmpcmsValue))
+	cmsPart, err := new(crypto.Point).CheckedMultiScalarMult(expVector, tmpcmsValue)
+	if err != nil {
+		return false, err
+	}
+	right1.Add(right1, cmsPart)
 
 	if !crypto.IsPointEqual(left1, right1) {
 		fmt.Printf("verify aggregated range proof statement 1 failed")
@@ -938,6 +1030,9 @@ func (proof BulletProof) Agg_Verify() (bool, error) {
 }
 
 func (proof BulletProof) Agg_Verify_Fast() (bool, error) {
+	if !proof.ValidateSanity() {
+		return false, errors.New("bulletproof is malformed")
+	}
 	numValue := len(proof.comValues)
 	if numValue > maxNOut {
 		return false, errors.New("Must less than maxNOut")
@@ -1008,10 +1103,14 @@ func (proof BulletProof) Agg_Verify_Fast() (bool, error) {
 	left1 := new(crypto.Point).AddPedersenBase(proof.tHat, proof.tauX)
 
 	right1 := new(crypto.Point).ScalarMult(proof.t2, xSquare)
-	right1.Add(right1, new(crypto.Point).AddPedersen(deltaYZ, crypto.G, x, proof.t1))
+	right1.Add(right1, new(crypto.Point).DoubleScalarMultBaseVartime(deltaYZ, proof.t1, x))
 
 	expVector := vectorMulScalar(powerVector(z, numValuePad), zSquare)
-	right1.Add(right1, new(crypto.Point).MultiScalarMult(expVector, tmpcmsValue))
+	cmsPart, err := new(crypto.Point).CheckedMultiScalarMult(expVector, tmpcmsValue)
+	if err != nil {
+		return false, err
+	}
+	right1.Add(right1, cmsPart)
 
 	if !crypto.IsPointEqual(left1, right1) {
 		fmt.Printf("verify aggregated range proof statement 1 failed")
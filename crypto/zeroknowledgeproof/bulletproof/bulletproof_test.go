@@ -1,12 +1,16 @@
 package bulletproof
 
 import (
+	crand "crypto/rand"
+	"errors"
 	"fmt"
 	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/oneoutofmany"
 	"github.com/incognitochain/incognito-chain/common"
 	"github.com/stretchr/testify/assert"
 	"math/rand"
 	"testing"
+	"testing/iotest"
 )
 
 func TestPad(t *testing.T) {
@@ -135,6 +139,72 @@ func TestAggregatedRangeProveVerify(t *testing.T) {
 	}
 }
 
+func TestAggregatedRangeProve_Deterministic(t *testing.T) {
+	wit := new(BulletWitness)
+	wit.Set([]uint64{10, 20, 30}, []*crypto.Scalar{crypto.RandomScalar(), crypto.RandomScalar(), crypto.RandomScalar()})
+
+	// nonces derived from the witness only give the same proof twice
+	rng1, err := wit.NonceReader(nil)
+	assert.Equal(t, nil, err)
+	proof1, err := wit.Agg_ProveWithRand(rng1)
+	assert.Equal(t, nil, err)
+	rng2, _ := wit.NonceReader(nil)
+	proof2, err := wit.Agg_ProveWithRand(rng2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, proof1.Bytes(), proof2.Bytes())
+
+	res, err := proof1.Agg_Verify()
+	assert.Equal(t, true, res)
+	assert.Equal(t, nil, err)
+
+	// hedged with fresh entropy, the proof differs but is still valid
+	rng3, err := wit.NonceReader(crand.Reader)
+	assert.Equal(t, nil, err)
+	proof3, err := wit.Agg_ProveWithRand(rng3)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, proof1.Bytes(), proof3.Bytes())
+	res, err = proof3.Agg_Verify()
+	assert.Equal(t, true, res)
+	assert.Equal(t, nil, err)
+
+	// a failing source of randomness is reported
+	rngErr := errors.New("rng failure")
+	_, err = wit.Agg_ProveWithRand(iotest.ErrReader(rngErr))
+	assert.Equal(t, rngErr, err)
+
+	single := new(BulletWitness)
+	single.Set([]uint64{10}, []*crypto.Scalar{crypto.RandomScalar()})
+	rng1, _ = single.NonceReader(nil)
+	proof1, err = single.Single_ProveWithRand(rng1)
+	assert.Equal(t, nil, err)
+	rng2, _ = single.NonceReader(nil)
+	proof2, err = single.Single_ProveWithRand(rng2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, proof1.Bytes(), proof2.Bytes())
+	_, err = single.Single_ProveWithRand(iotest.ErrReader(rngErr))
+	assert.Equal(t, rngErr, err)
+}
+
+func TestAggregatedRangeProve_Balance(t *testing.T) {
+	inputBlind := crypto.RandomScalar()
+	input := crypto.Commit(1000, inputBlind)
+
+	blind := crypto.RandomScalar()
+	lastBlind, err := crypto.BalanceBlind([]*crypto.Scalar{inputBlind}, []*crypto.Scalar{blind})
+	assert.Equal(t, nil, err)
+
+	wit := new(BulletWitness)
+	wit.Set([]uint64{600, 390}, []*crypto.Scalar{blind, lastBlind})
+	proof, err := wit.Agg_Prove()
+	assert.Equal(t, nil, err)
+
+	res, err := proof.Agg_Verify()
+	assert.Equal(t, true, res)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, crypto.VerifyBalance([]*crypto.Commitment{input}, proof.GetCommitments(), 10))
+	assert.Equal(t, false, crypto.VerifyBalance([]*crypto.Commitment{input}, proof.GetCommitments(), 0))
+}
+
 func TestInnerProductProveVerify(t *testing.T) {
 	for k := 0; k < 10; k++ {
 		numValue := rand.Intn(maxNOut)
@@ -144,6 +214,7 @@ func TestInnerProductProveVerify(t *testing.T) {
 		aggParam.h = BulletParam.h[0 : numValuePad*maxExp]
 		aggParam.u = BulletParam.u
 		aggParam.cs = BulletParam.cs
+		aggParam.tables = BulletParam.tables
 
 		wit := new(InnerProductWitness)
 		n := maxExp * numValuePad
@@ -392,4 +463,117 @@ func benchmarkSingleBulletProof_VerifyFast(b *testing.B) {
 
 func BenchmarkSingleBulletProof_Prove(b *testing.B) { benchmarkSingleBulletProof_Prove(b) }
 func BenchmarkSingleBulletProof_Verify(b *testing.B)  { benchmarkSingleBulletProof_Verify(b) }
-func BenchmarkSingleBulletProof_VerifyFast(b *testing.B) { benchmarkSingleBulletProof_VerifyFast(b) }
\ No newline at end of file
+func BenchmarkSingleBulletProof_VerifyFast(b *testing.B) { benchmarkSingleBulletProof_VerifyFast(b) }
+
+/********** BENCHMARK ONE OUT OF MANY PROOF **********/
+// the one out of many proofs of 2^4..2^12 commitments are benchmarked here,
+// to compare them with the range proofs above
+
+func randomOneOutOfManyCommitments(n int, index int, blind *crypto.Scalar) []*crypto.Point {
+	commitments := make([]*crypto.Point, n)
+	for i := range commitments {
+		commitments[i] = crypto.Commit(rand.Uint64(), crypto.RandomScalar()).Point()
+	}
+	commitments[index] = crypto.Commit(0, blind).Point()
+	return commitments
+}
+func benchmarkOneOutOfMany_Prove(n int, b *testing.B) {
+	index := rand.Intn(n)
+	blind := crypto.RandomScalar()
+	commitments := randomOneOutOfManyCommitments(n, index, blind)
+	wit := new(oneoutofmany.OneOutOfManyWitness)
+	wit.Set(commitments, new(crypto.Point).Identity(), index, blind)
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		wit.Prove()
+	}
+}
+
+func benchmarkOneOutOfMany_Verify(n int, b *testing.B) {
+	index := rand.Intn(n)
+	blind := crypto.RandomScalar()
+	commitments := randomOneOutOfManyCommitments(n, index, blind)
+	identity := new(crypto.Point).Identity()
+	wit := new(oneoutofmany.OneOutOfManyWitness)
+	wit.Set(commitments, identity, index, blind)
+	proof, _ := wit.Prove()
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		proof.Verify(commitments, identity)
+	}
+}
+
+func BenchmarkOneOutOfManyWitness_Prove16(b *testing.B)  { benchmarkOneOutOfMany_Prove(1<<4, b) }
+func BenchmarkOneOutOfManyProof_Verify16(b *testing.B)   { benchmarkOneOutOfMany_Verify(1<<4, b) }
+func BenchmarkOneOutOfManyWitness_Prove32(b *testing.B)  { benchmarkOneOutOfMany_Prove(1<<5, b) }
+func BenchmarkOneOutOfManyProof_Verify32(b *testing.B)   { benchmarkOneOutOfMany_Verify(1<<5, b) }
+func BenchmarkOneOutOfManyWitness_Prove64(b *testing.B)  { benchmarkOneOutOfMany_Prove(1<<6, b) }
+func BenchmarkOneOutOfManyProof_Verify64(b *testing.B)   { benchmarkOneOutOfMany_Verify(1<<6, b) }
+func BenchmarkOneOutOfManyWitness_Prove128(b *testing.B) { benchmarkOneOutOfMany_Prove(1<<7, b) }
+func BenchmarkOneOutOfManyProof_Verify128(b *testing.B)  { benchmarkOneOutOfMany_Verify(1<<7, b) }
+func BenchmarkOneOutOfManyWitness_Prove256(b *testing.B) { benchmarkOneOutOfMany_Prove(1<<8, b) }
+func BenchmarkOneOutOfManyProof_Verify256(b *testing.B)  { benchmarkOneOutOfMany_Verify(1<<8, b) }
+func BenchmarkOneOutOfManyWitness_Prove512(b *testing.B) { benchmarkOneOutOfMany_Prove(1<<9, b) }
+func BenchmarkOneOutOfManyProof_Verify512(b *testing.B)  { benchmarkOneOutOfMany_Verify(1<<9, b) }
+func BenchmarkOneOutOfManyWitness_Prove1024(b *testing.B) {
+	benchmarkOneOutOfMany_Prove(1<<10, b)
+}
+func BenchmarkOneOutOfManyProof_Verify1024(b *testing.B) { benchmarkOneOutOfMany_Verify(1<<10, b) }
+func BenchmarkOneOutOfManyWitness_Prove2048(b *testing.B) {
+	benchmarkOneOutOfMany_Prove(1<<11, b)
+}
+func BenchmarkOneOutOfManyProof_Verify2048(b *testing.B) { benchmarkOneOutOfMany_Verify(1<<11, b) }
+func BenchmarkOneOutOfManyWitness_Prove4096(b *testing.B) {
+	benchmarkOneOutOfMany_Prove(1<<12, b)
+}
+func BenchmarkOneOutOfManyProof_Verify4096(b *testing.B) { benchmarkOneOutOfMany_Verify(1<<12, b) }
+
+func TestBulletProof_Malformed(t *testing.T) {
+	wit := new(BulletWitness)
+	wit.Set([]uint64{7, 8}, []*crypto.Scalar{crypto.RandomScalar(), crypto.RandomScalar()})
+	proof, err := wit.Agg_Prove()
+	assert.Equal(t, nil, err)
+	bytes := proof.Bytes()
+
+	// truncated and extended proofs are rejected without a panic
+	for _, n := range []int{1, 2, 33, len(bytes) / 2, len(bytes) - crypto.Ed25519KeySize, len(bytes) - 1} {
+		assert.NotEqual(t, nil, new(BulletProof).SetBytes(bytes[:n]), n)
+	}
+	assert.NotEqual(t, nil, new(BulletProof).SetBytes(append(append([]byte{}, bytes...), 0)))
+
+	// an inner product proof with a round more or less does not verify
+	aggParam := getBulletproofParams(2)
+	inner := *proof.innerProductProof
+	for _, rounds := range []int{len(inner.l) - 1, len(inner.l) + 1} {
+		tampered := inner
+		tampered.l = make([]*crypto.Point, rounds)
+		tampered.r = make([]*crypto.Point, rounds)
+		for i := range tampered.l {
+			tampered.l[i] = crypto.RandomPoint()
+			tampered.r[i] = crypto.RandomPoint()
+		}
+		assert.Equal(t, false, tampered.Verify(aggParam))
+		assert.Equal(t, false, tampered.Verify_Fast(aggParam))
+
+		tamperedProof := *proof
+		tamperedProof.innerProductProof = &tampered
+		res, _ := tamperedProof.Agg_Verify()
+		assert.Equal(t, false, res)
+		res, _ = tamperedProof.Agg_Verify_Fast()
+		assert.Equal(t, false, res)
+	}
+
+	// missing fields
+	empty := new(BulletProof)
+	assert.Equal(t, false, empty.ValidateSanity())
+	res, err := empty.Agg_Verify()
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+	res, err = empty.Single_Verify_Fast()
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+}
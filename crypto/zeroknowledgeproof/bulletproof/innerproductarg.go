@@ -38,7 +38,7 @@ func (proof InnerProductProof) ValidateSanity() bool {
 	}
 
 	for i := 0; i < len(proof.l); i++ {
-		if !proof.l[i].PointValid() || !proof.r[i].PointValid() {
+		if proof.l[i] == nil || proof.r[i] == nil || !proof.l[i].PointValid() || !proof.r[i].PointValid() {
 			return false
 		}
 	}
@@ -47,7 +47,20 @@ func (proof InnerProductProof) ValidateSanity() bool {
 		return false
 	}
 
-	return proof.p.PointValid()
+	return proof.p != nil && proof.p.PointValid()
+}
+
+// hasRounds checks that the proof has the log2(n) rounds of the n generators of aggParam,
+// the verifiers index their challenges by round and must not read past them
+func (proof InnerProductProof) hasRounds(aggParam *bulletproofParams) bool {
+	n := len(aggParam.g)
+	if n == 0 || n&(n-1) != 0 || len(aggParam.h) != n {
+		return false
+	}
+	logN := 0
+	for ; 1<<uint(logN) < n; logN++ {
+	}
+	return len(proof.l) == logN && proof.ValidateSanity()
 }
 
 func (proof InnerProductProof) Bytes() []byte {
@@ -77,6 +90,9 @@ func (proof *InnerProductProof) SetBytes(bytes []byte) error {
 	lenLArray := int(bytes[0])
 	offset := 1
 	var err error
+	if len(bytes) != 1+(2*lenLArray+3)*crypto.Ed25519KeySize {
+		return errors.New("inner product proof has an invalid size")
+	}
 
 	proof.l = make([]*crypto.Point, lenLArray)
 	for i := 0; i < lenLArray; i++ {
@@ -161,14 +177,14 @@ func (wit InnerProductWitness) Prove(aggParam *bulletproofParams) (*InnerProduct
 		if err != nil {
 			return nil, err
 		}
-		L.Add(L, new(crypto.Point).ScalarMult(aggParam.u, cL))
+		L.Add(L, aggParam.scalarMultU(cL))
 		proof.l = append(proof.l, L)
 
 		R, err := encodeVectors(a[nPrime:], b[:nPrime], G[:nPrime], H[nPrime:])
 		if err != nil {
 			return nil, err
 		}
-		R.Add(R, new(crypto.Point).ScalarMult(aggParam.u, cR))
+		R.Add(R, aggParam.scalarMultU(cR))
 		proof.r = append(proof.r, R)
 
 		// calculate challenge x = hash(G || H || u || x || l || r)
@@ -218,6 +234,9 @@ func (wit InnerProductWitness) Prove(aggParam *bulletproofParams) (*InnerProduct
 }
 
 func (proof InnerProductProof) Verify(aggParam *bulletproofParams) bool {
+	if !proof.hasRounds(aggParam) {
+		return false
+	}
 	//var aggParam = newBulletproofParams(1)
 	p := new(crypto.Point)
 	p.Set(proof.p)
@@ -233,6 +252,7 @@ func (proof InnerProductProof) Verify(aggParam *bulletproofParams) bool {
 	for i := range proof.l {
 		nPrime := n / 2
 		// calculate challenge x = hash(G || H || u || p || x || l || r)
+		// the next challenge hashes PPrime, which needs xInverse, so the challenges are inverted one at a time
 		x := generateChallenge([][]byte{aggParam.cs, p.ToBytes(), proof.l[i].ToBytes(), proof.r[i].ToBytes()})
 		xInverse := new(crypto.Scalar).Invert(x)
 		xSquare := new(crypto.Scalar).Mul(x, x)
@@ -258,7 +278,7 @@ func (proof InnerProductProof) Verify(aggParam *bulletproofParams) bool {
 
 	c := new(crypto.Scalar).Mul(proof.a, proof.b)
 	rightPoint := new(crypto.Point).AddPedersen(proof.a, G[0], proof.b, H[0])
-	rightPoint.Add(rightPoint, new(crypto.Point).ScalarMult(aggParam.u, c))
+	rightPoint.Add(rightPoint, aggParam.scalarMultU(c))
 	res := crypto.IsPointEqual(rightPoint, p)
 	if !res {
 		fmt.Println("Inner product argument failed:")
@@ -270,6 +290,9 @@ func (proof InnerProductProof) Verify(aggParam *bulletproofParams) bool {
 }
 
 func (proof InnerProductProof) Verify_Fast(aggParam *bulletproofParams) bool {
+	if !proof.hasRounds(aggParam) {
+		return false
+	}
 	//var aggParam = newBulletproofParams(1)
 	p := new(crypto.Point)
 	p.Set(proof.p)
@@ -277,13 +300,11 @@ func (proof InnerProductProof) Verify_Fast(aggParam *bulletproofParams) bool {
 	G := make([]*crypto.Point, n)
 	H := make([]*crypto.Point, n)
 	s := make([]*crypto.Scalar, n)
-	sInverse := make([]*crypto.Scalar, n)
 
 	for i := range G {
 		G[i] = new(crypto.Point).Set(aggParam.g[i])
 		H[i] = new(crypto.Point).Set(aggParam.h[i])
 		s[i] = new(crypto.Scalar).FromUint64(1)
-		sInverse[i] = new(crypto.Scalar).FromUint64(1)
 	}
 	logN := int(math.Log2(float64(n)))
 	xList := make([]*crypto.Scalar, logN)
@@ -295,19 +316,19 @@ func (proof InnerProductProof) Verify_Fast(aggParam *bulletproofParams) bool {
 
 	for i := range proof.l {
 		// calculate challenge x = hash(hash(G || H || u || p) || x || l || r)
+		// xList can not be collected for crypto.BatchInvert: the challenge of the next round hashes
+		// PPrime, which needs xInverse of this round, and batching would change the proof transcript
 		xList[i] = generateChallenge([][]byte{aggParam.cs, p.ToBytes(), proof.l[i].ToBytes(), proof.r[i].ToBytes()})
 		xInverseList[i] = new(crypto.Scalar).Invert(xList[i])
 		xSquareList[i] = new(crypto.Scalar).Mul(xList[i], xList[i])
 		xInverseSquare_List[i] = new(crypto.Scalar).Mul(xInverseList[i], xInverseList[i])
 
-		//Update s, s^-1
+		//Update s
 		for j := 0; j < n; j++ {
 			if j&int(math.Pow(2, float64(logN-i-1))) != 0 {
 				s[j] = new(crypto.Scalar).Mul(s[j], xList[i])
-				sInverse[j] = new(crypto.Scalar).Mul(sInverse[j], xInverseList[i])
 			} else {
 				s[j] = new(crypto.Scalar).Mul(s[j], xInverseList[i])
-				sInverse[j] = new(crypto.Scalar).Mul(sInverse[j], xList[i])
 			}
 		}
 		PPrime := new(crypto.Point).AddPedersen(xSquareList[i], proof.l[i], xInverseSquare_List[i], proof.r[i])
@@ -315,18 +336,34 @@ func (proof InnerProductProof) Verify_Fast(aggParam *bulletproofParams) bool {
 		p = PPrime
 	}
 
+	// s^-1 with a single inversion instead of updating it in every round
+	sInverse := crypto.BatchInvert(s)
+
 	// Compute (g^s)^a (h^-s)^b u^(ab) = p l^(x^2) r^(-x^2)
 	c := new(crypto.Scalar).Mul(proof.a, proof.b)
-	rightHSPart1 := new(crypto.Point).MultiScalarMult(s, G)
+	tables := aggParam.getTables()
+	rightHSPart1, err := new(crypto.Point).CheckedMultiScalarMultCached(s, tables.gPrecomputed[:n])
+	if err != nil {
+		return false
+	}
 	rightHSPart1.ScalarMult(rightHSPart1, proof.a)
-	rightHSPart2 := new(crypto.Point).MultiScalarMult(sInverse, H)
+	rightHSPart2, err := new(crypto.Point).CheckedMultiScalarMultCached(sInverse, tables.hPrecomputed[:n])
+	if err != nil {
+		return false
+	}
 	rightHSPart2.ScalarMult(rightHSPart2, proof.b)
 
 	rightHS := new(crypto.Point).Add(rightHSPart1, rightHSPart2)
-	rightHS.Add(rightHS, new(crypto.Point).ScalarMult(aggParam.u, c))
+	rightHS.Add(rightHS, aggParam.scalarMultU(c))
 
-	leftHSPart1 := new(crypto.Point).MultiScalarMult(xSquareList, proof.l)
-	leftHSPart2 := new(crypto.Point).MultiScalarMult(xInverseSquare_List, proof.r)
+	leftHSPart1, err := new(crypto.Point).CheckedMultiScalarMult(xSquareList, proof.l)
+	if err != nil {
+		return false
+	}
+	leftHSPart2, err := new(crypto.Point).CheckedMultiScalarMult(xInverseSquare_List, proof.r)
+	if err != nil {
+		return false
+	}
 
 	leftHS := new(crypto.Point).Add(leftHSPart1, leftHSPart2)
 	leftHS.Add(leftHS, proof.p)
@@ -0,0 +1,132 @@
+package bulletproof
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Hashing the generators to points is the main cost of starting the package,
+// so BulletParam is loaded from a generated file instead.
+//
+// layout:  magic (4) | version (1) | m (2) | g (m*maxExp points) | h (m*maxExp points) | u | keccak256 of all previous bytes (32)
+
+//go:generate go test -run TestBulletproofParams_EmbeddedFile -update-params
+
+const ParamsFileVersion = 1
+
+const paramsFileName = "bulletproof_params.bin"
+
+var paramsMagic = []byte("ICBP")
+
+const paramsHeaderSize = 4 + 1 + 2
+
+//go:embed bulletproof_params.bin
+var embeddedParams []byte
+
+// embeddedParamsHash pins the content hash of bulletproof_params.bin.
+// The hash stored in a params file only detects corruption, anyone replacing the file
+// can recompute it, so the generators of a file are trusted without hashing them again
+// only if its hash is this one. TestBulletproofParams_EmbeddedFile fails until it is
+// updated after go generate
+const embeddedParamsHash = "c69c4e2a70f7943245ee392ffebf4632df58ae692b961f12a6c178f04783f9a2"
+
+// Bytes serializes the generators of the param in the params file format
+func (param bulletproofParams) Bytes() []byte {
+	m := len(param.g) / maxExp
+	res := make([]byte, 0, paramsHeaderSize+(2*len(param.g)+1)*crypto.Ed25519KeySize+crypto.Ed25519KeySize)
+	res = append(res, paramsMagic...)
+	res = append(res, ParamsFileVersion)
+	res = append(res, byte(m>>8), byte(m))
+	for i := range param.g {
+		res = append(res, param.g[i].ToBytes()...)
+	}
+	for i := range param.h {
+		res = append(res, param.h[i].ToBytes()...)
+	}
+	res = append(res, param.u.ToBytes()...)
+	return append(res, crypto.Keccak256(res)...)
+}
+
+// SetBytes loads the first m*maxExp generators of a params file.
+// Every generator of a file other than the embedded one is hashed again and compared,
+// so that a file generated by another version of newBulletproofParams, or crafted, is rejected
+func (param *bulletproofParams) SetBytes(data []byte, m int) error {
+	if len(data) < paramsHeaderSize+crypto.Ed25519KeySize {
+		return errors.New("invalid params file size")
+	}
+	if !bytes.Equal(data[:4], paramsMagic) {
+		return errors.New("invalid params file magic")
+	}
+	if data[4] != ParamsFileVersion {
+		return fmt.Errorf("unsupported params file version %d", data[4])
+	}
+	mFile := int(binary.BigEndian.Uint16(data[5:paramsHeaderSize]))
+	if m <= 0 || m > mFile {
+		return fmt.Errorf("params file has %d outputs, %d are required", mFile, m)
+	}
+	capacityFile := mFile * maxExp
+	size := paramsHeaderSize + (2*capacityFile+1)*crypto.Ed25519KeySize + crypto.Ed25519KeySize
+	if len(data) != size {
+		return errors.New("invalid params file size")
+	}
+	if !bytes.Equal(crypto.Keccak256(data[:size-crypto.Ed25519KeySize]), data[size-crypto.Ed25519KeySize:]) {
+		return errors.New("params file hash mismatch")
+	}
+
+	capacity := m * maxExp
+	readPoint := func(i int) (*crypto.Point, error) {
+		offset := paramsHeaderSize + i*crypto.Ed25519KeySize
+		return new(crypto.Point).FromBytes(data[offset : offset+crypto.Ed25519KeySize])
+	}
+	var err error
+	g := make([]*crypto.Point, capacity)
+	h := make([]*crypto.Point, capacity)
+	for i := 0; i < capacity; i++ {
+		if g[i], err = readPoint(i); err != nil {
+			return err
+		}
+		if h[i], err = readPoint(capacityFile + i); err != nil {
+			return err
+		}
+	}
+	u, err := readPoint(2 * capacityFile)
+	if err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(data[size-crypto.Ed25519KeySize:]) != embeddedParamsHash {
+		maxCapacity := maxNOutParam * maxExp
+		for i := 0; i < capacity; i++ {
+			if !crypto.IsPointEqual(g[i], crypto.HashToPointFromIndex(int64(i), crypto.CStringBulletProof)) ||
+				!crypto.IsPointEqual(h[i], crypto.HashToPointFromIndex(int64(i+maxCapacity), crypto.CStringBulletProof)) {
+				return errors.New("params file does not match the generators")
+			}
+		}
+		if !crypto.IsPointEqual(u, crypto.HashToPointFromIndex(int64(2*maxCapacity), crypto.CStringBulletProof)) {
+			return errors.New("params file does not match the generators")
+		}
+	}
+
+	param.g = g
+	param.h = h
+	param.u = u
+	param.cs = computeCS(g, h, u)
+	param.tables = newGeneratorTables(g, h, u)
+	return nil
+}
+
+// loadBulletproofParams loads the params of m outputs from data,
+// falling back to newBulletproofParams if data is not a valid params file
+func loadBulletproofParams(data []byte, m int) *bulletproofParams {
+	param := new(bulletproofParams)
+	if err := param.SetBytes(data, m); err != nil {
+		return newBulletproofParams(m)
+	}
+	return param
+}
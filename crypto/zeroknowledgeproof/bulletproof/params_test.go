@@ -0,0 +1,82 @@
+package bulletproof
+
+import (
+	"encoding/hex"
+	"flag"
+	"io/ioutil"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+var updateParams = flag.Bool("update-params", false, "regenerate "+paramsFileName)
+
+func assertParamsEqual(t *testing.T, expected *bulletproofParams, actual *bulletproofParams) {
+	assert.Equal(t, len(expected.g), len(actual.g))
+	assert.Equal(t, len(expected.h), len(actual.h))
+	for i := range expected.g {
+		assert.Equal(t, true, crypto.IsPointEqual(expected.g[i], actual.g[i]))
+		assert.Equal(t, true, crypto.IsPointEqual(expected.h[i], actual.h[i]))
+	}
+	assert.Equal(t, true, crypto.IsPointEqual(expected.u, actual.u))
+	assert.Equal(t, expected.cs, actual.cs)
+}
+
+func TestBulletproofParams_EmbeddedFile(t *testing.T) {
+	expected := newBulletproofParams(nOutPreComputeParam)
+	if *updateParams {
+		data := expected.Bytes()
+		err := ioutil.WriteFile(paramsFileName, data, 0644)
+		assert.Equal(t, nil, err)
+		t.Logf("update embeddedParamsHash to %x", data[len(data)-crypto.Ed25519KeySize:])
+		return
+	}
+
+	data := expected.Bytes()
+	assert.Equal(t, embeddedParamsHash, hex.EncodeToString(data[len(data)-crypto.Ed25519KeySize:]))
+
+	loaded := new(bulletproofParams)
+	err := loaded.SetBytes(embeddedParams, nOutPreComputeParam)
+	assert.Equal(t, nil, err, "run go generate to update "+paramsFileName)
+	assertParamsEqual(t, expected, loaded)
+	assertParamsEqual(t, expected, BulletParam)
+	assertParamsEqual(t, newBulletproofParams(1), SingleBulletParam)
+}
+
+func TestBulletproofParams_SetBytes(t *testing.T) {
+	data := SingleBulletParam.Bytes()
+
+	loaded := new(bulletproofParams)
+	assert.Equal(t, nil, loaded.SetBytes(data, 1))
+	assertParamsEqual(t, SingleBulletParam, loaded)
+
+	// more outputs than the file has
+	assert.NotEqual(t, nil, loaded.SetBytes(data, 2))
+
+	// corrupted content
+	corrupted := append([]byte{}, data...)
+	corrupted[len(corrupted)/2] ^= 1
+	assert.NotEqual(t, nil, loaded.SetBytes(corrupted, 1))
+
+	// unknown version
+	corrupted = append([]byte{}, data...)
+	corrupted[4] = ParamsFileVersion + 1
+	assert.NotEqual(t, nil, loaded.SetBytes(corrupted, 1))
+
+	// a valid file of other generators, whichever generator differs
+	for _, i := range []int{0, maxExp / 2, maxExp - 1} {
+		other := newBulletproofParams(1)
+		other.g[i] = crypto.RandomPoint()
+		assert.NotEqual(t, nil, loaded.SetBytes(other.Bytes(), 1))
+		other = newBulletproofParams(1)
+		other.h[i] = crypto.RandomPoint()
+		assert.NotEqual(t, nil, loaded.SetBytes(other.Bytes(), 1))
+	}
+	other := newBulletproofParams(1)
+	other.u = crypto.RandomPoint()
+	assert.NotEqual(t, nil, loaded.SetBytes(other.Bytes(), 1))
+
+	// loading falls back to computing the generators
+	assertParamsEqual(t, SingleBulletParam, loadBulletproofParams(corrupted, 1))
+}
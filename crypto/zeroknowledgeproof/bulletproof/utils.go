@@ -3,7 +3,9 @@ package bulletproof
 import (
 	"errors"
 	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
 	"math"
+	"sync"
 )
 
 const (
@@ -19,10 +21,67 @@ type bulletproofParams struct {
 	h  []*crypto.Point
 	u  *crypto.Point
 	cs []byte
+
+	// tables of the generators, shared by the params derived from BulletParam
+	tables *generatorTables
+}
+
+// generatorTables holds precomputed tables of the fixed generators g, h, u.
+// They are built once on first use, as building them for BulletParam takes
+// a noticeable time
+type generatorTables struct {
+	once sync.Once
+	g    []*crypto.Point
+	h    []*crypto.Point
+
+	gPrecomputed [][8]C25519.CachedGroupElement
+	hPrecomputed [][8]C25519.CachedGroupElement
+	u            *crypto.FixedBase
+}
+
+func newGeneratorTables(g []*crypto.Point, h []*crypto.Point, u *crypto.Point) *generatorTables {
+	return &generatorTables{
+		g: g,
+		h: h,
+		u: crypto.NewFixedBase(u),
+	}
+}
+
+func (tables *generatorTables) build() {
+	tables.once.Do(func() {
+		tables.gPrecomputed = crypto.PreComputeForMultiScalar(tables.g)
+		tables.hPrecomputed = crypto.PreComputeForMultiScalar(tables.h)
+	})
+}
+
+// getTables returns the built generator tables of the param.
+// tables is set once by every constructor of bulletproofParams and never written after,
+// so params shared between goroutines only synchronize through the sync.Once of build
+func (param *bulletproofParams) getTables() *generatorTables {
+	param.tables.build()
+	return param.tables
+}
+
+// encodeGenerators encodes two value vectors l, r with the first len(l) generators g, h of the param
+func (param *bulletproofParams) encodeGenerators(l []*crypto.Scalar, r []*crypto.Scalar) (*crypto.Point, error) {
+	if len(l) != len(r) || len(l) > len(param.g) {
+		return nil, errors.New("invalid input")
+	}
+	tables := param.getTables()
+	tmp1 := new(crypto.Point).MultiScalarMultCached(l, tables.gPrecomputed[:len(l)])
+	tmp2 := new(crypto.Point).MultiScalarMultCached(r, tables.hPrecomputed[:len(r)])
+
+	res := new(crypto.Point).Add(tmp1, tmp2)
+	return res, nil
 }
 
-var BulletParam = newBulletproofParams(nOutPreComputeParam)
-var SingleBulletParam = newBulletproofParams(1)
+// scalarMultU returns a * u
+func (param *bulletproofParams) scalarMultU(a *crypto.Scalar) *crypto.Point {
+	return new(crypto.Point).ScalarMultFixedBase(param.getTables().u, a)
+}
+
+var BulletParam = loadBulletproofParams(embeddedParams, nOutPreComputeParam)
+var SingleBulletParam = loadBulletproofParams(embeddedParams, 1)
 
 func newBulletproofParams(m int) *bulletproofParams {
 	param := new(bulletproofParams)
@@ -31,27 +90,35 @@ func newBulletproofParams(m int) *bulletproofParams {
 	maxCapacity := maxNOutParam * maxExp
 	param.g = make([]*crypto.Point, capacity)
 	param.h = make([]*crypto.Point, capacity)
-	csByteH := []byte{}
-	csByteG := []byte{}
 
 	for i := 0; i < capacity; i++ {
 		param.g[i] = crypto.HashToPointFromIndex(int64(i), crypto.CStringBulletProof)
 		param.h[i] = crypto.HashToPointFromIndex(int64(i + maxCapacity), crypto.CStringBulletProof)
-		csByteG = append(csByteG, param.g[i].ToBytes()...)
-		csByteH = append(csByteH, param.h[i].ToBytes()...)
 	}
 
 	param.u = new(crypto.Point)
 	param.u = crypto.HashToPointFromIndex(int64(2 * maxCapacity), crypto.CStringBulletProof)
 
-	param.cs = append(param.cs, csByteG...)
-	param.cs = append(param.cs, csByteH...)
-	param.cs = append(param.cs, param.u.ToBytes()...)
-	param.cs = crypto.HashToScalar(param.cs).ToBytes()
+	param.cs = computeCS(param.g, param.h, param.u)
+
+	param.tables = newGeneratorTables(param.g, param.h, param.u)
 
 	return param
 }
 
+// computeCS returns the hash of the generators, which is bound to every challenge
+func computeCS(g []*crypto.Point, h []*crypto.Point, u *crypto.Point) []byte {
+	csBytes := []byte{}
+	for i := range g {
+		csBytes = append(csBytes, g[i].ToBytes()...)
+	}
+	for i := range h {
+		csBytes = append(csBytes, h[i].ToBytes()...)
+	}
+	csBytes = append(csBytes, u.ToBytes()...)
+	return crypto.HashToScalar(csBytes).ToBytes()
+}
+
 func getBulletproofParams(m int) *bulletproofParams {
 	newParam := new(bulletproofParams)
 	newParam.u = BulletParam.u
@@ -63,6 +130,7 @@ func getBulletproofParams(m int) *bulletproofParams {
 		newParam.g[i] = new(crypto.Point).Set(BulletParam.g[i])
 		newParam.h[i] = new(crypto.Point).Set(BulletParam.h[i])
 	}
+	newParam.tables = BulletParam.tables
 
 	return newParam
 }
@@ -77,22 +145,13 @@ func setBulletproofParams(g []*crypto.Point, h []*crypto.Point) (*bulletproofPar
 	newParam.g = make([]*crypto.Point, len(g))
 	newParam.h = make([]*crypto.Point, len(h))
 
-	csBytes := []byte{}
-	gBytes := []byte{}
-	hBytes := []byte{}
-
 	for i := range newParam.g {
 		newParam.g[i] = new(crypto.Point).Set(BulletParam.g[i])
 		newParam.h[i] = new(crypto.Point).Set(BulletParam.h[i])
-
-		gBytes = append(gBytes, newParam.g[i].ToBytes()...)
-		hBytes = append(hBytes, newParam.h[i].ToBytes()...)
 	}
 
-	csBytes = append(csBytes, gBytes...)
-	csBytes = append(csBytes, hBytes...)
-	csBytes = append(csBytes, newParam.u.ToBytes()...)
-	newParam.cs = crypto.HashToScalar(csBytes).ToBytes()
+	newParam.cs = computeCS(newParam.g, newParam.h, newParam.u)
+	newParam.tables = BulletParam.tables
 
 	return newParam, nil
 }
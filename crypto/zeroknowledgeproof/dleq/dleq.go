@@ -0,0 +1,220 @@
+package dleq
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Discrete log equality proof (Chaum-Pedersen)
+// convinces the verifier that points[i] = x * bases[i] for every i with the same x, without revealing x.
+// For instance bases = (G, HashToPoint(P)) and points = (P, keyImage) proves that the key image is well formed.
+//
+// Prover:   k random, A_i = k * bases[i], c = H(bases || points || A), z = k - c*x
+// Verifier: A_i = z * bases[i] + c * points[i], checks c == H(bases || points || A)
+
+const cStringDLEQ = "dleq"
+
+// max number of bases, the number is encoded in one byte
+const MaxBases = 255
+
+type DLEQWitness struct {
+	x      *crypto.Scalar
+	bases  []*crypto.Point
+	points []*crypto.Point
+}
+
+type DLEQProof struct {
+	bases  []*crypto.Point
+	points []*crypto.Point
+	c      *crypto.Scalar
+	z      *crypto.Scalar
+}
+
+// Set sets the witness of x for the bases, the points are computed as x * bases[i]
+func (wit *DLEQWitness) Set(x *crypto.Scalar, bases []*crypto.Point) {
+	wit.x = new(crypto.Scalar).Set(x)
+	wit.bases = make([]*crypto.Point, len(bases))
+	wit.points = make([]*crypto.Point, len(bases))
+	for i := range bases {
+		wit.bases[i] = new(crypto.Point).Set(bases[i])
+		wit.points[i] = new(crypto.Point).ScalarMult(bases[i], x)
+	}
+}
+
+func (wit DLEQWitness) GetPoints() []*crypto.Point {
+	return wit.points
+}
+
+func generateChallenge(bases []*crypto.Point, points []*crypto.Point, commitments []*crypto.Point) *crypto.Scalar {
+	bytes := []byte(cStringDLEQ)
+	bytes = append(bytes, byte(len(bases)))
+	bytes = crypto.AppendPointsToBytesArray(bytes, bases)
+	bytes = crypto.AppendPointsToBytesArray(bytes, points)
+	bytes = crypto.AppendPointsToBytesArray(bytes, commitments)
+	return crypto.HashToScalar(bytes)
+}
+
+func (wit DLEQWitness) Prove() (*DLEQProof, error) {
+	return wit.ProveWithRand(rand.Reader)
+}
+
+// ProveWithRand creates the proof, reading the nonce from rng
+func (wit DLEQWitness) ProveWithRand(rng io.Reader) (*DLEQProof, error) {
+	n := len(wit.bases)
+	if n == 0 || n > MaxBases || len(wit.points) != n {
+		return nil, errors.New("invalid witness of dleq protocol")
+	}
+
+	k, err := crypto.RandomScalarFrom(rng)
+	if err != nil {
+		return nil, err
+	}
+	commitments := make([]*crypto.Point, n)
+	for i := 0; i < n; i++ {
+		commitments[i] = new(crypto.Point).ScalarMult(wit.bases[i], k)
+	}
+
+	c := generateChallenge(wit.bases, wit.points, commitments)
+	z := new(crypto.Scalar).Sub(k, new(crypto.Scalar).Mul(c, wit.x))
+
+	return &DLEQProof{
+		bases:  wit.bases,
+		points: wit.points,
+		c:      c,
+		z:      z,
+	}, nil
+}
+
+func (proof DLEQProof) GetBases() []*crypto.Point {
+	return proof.bases
+}
+
+func (proof DLEQProof) GetPoints() []*crypto.Point {
+	return proof.points
+}
+
+// Point returns points[0], the point over the first base, so that a proof with the base G
+// can be given as the CrossGroupProof of an adaptor point
+func (proof DLEQProof) Point() *crypto.Point {
+	if len(proof.points) == 0 {
+		return nil
+	}
+	return proof.points[0]
+}
+
+func (proof DLEQProof) ValidateSanity() bool {
+	n := len(proof.bases)
+	if n == 0 || n > MaxBases || len(proof.points) != n {
+		return false
+	}
+	for i := 0; i < n; i++ {
+		// a component of small order T in a point would let a prover who guesses c mod 8
+		// pass with points[i] + T, for instance a second key image of the same output
+		if proof.bases[i] == nil || !proof.bases[i].InPrimeOrderSubgroup() || proof.bases[i].IsIdentity() {
+			return false
+		}
+		if proof.points[i] == nil || !proof.points[i].InPrimeOrderSubgroup() {
+			return false
+		}
+	}
+	return proof.c != nil && proof.c.ScalarValid() && proof.z != nil && proof.z.ScalarValid()
+}
+
+func (proof DLEQProof) Verify() (bool, error) {
+	if !proof.ValidateSanity() {
+		return false, errors.New("invalid dleq proof")
+	}
+
+	commitments := make([]*crypto.Point, len(proof.bases))
+	for i := range proof.bases {
+		commitments[i] = new(crypto.Point).AddPedersen(proof.z, proof.bases[i], proof.c, proof.points[i])
+	}
+
+	c := generateChallenge(proof.bases, proof.points, commitments)
+	if crypto.CompareScalar(c, proof.c) != 0 {
+		return false, errors.New("verify dleq proof failed")
+	}
+	return true, nil
+}
+
+// Bytes encodes the proof as n || bases || points || c || z
+func (proof DLEQProof) Bytes() []byte {
+	n := len(proof.bases)
+	if n == 0 || n > MaxBases || len(proof.points) != n || proof.c == nil || proof.z == nil {
+		return []byte{}
+	}
+	res := make([]byte, 0, 1+(2*n+2)*crypto.Ed25519KeySize)
+	res = append(res, byte(n))
+	res = crypto.AppendPointsToBytesArray(res, proof.bases)
+	res = crypto.AppendPointsToBytesArray(res, proof.points)
+	res = append(res, proof.c.ToBytes()...)
+	res = append(res, proof.z.ToBytes()...)
+	return res
+}
+
+func (proof *DLEQProof) SetBytes(bytes []byte) error {
+	if len(bytes) == 0 {
+		return errors.New("dleq proof bytes is empty")
+	}
+	n := int(bytes[0])
+	if n == 0 || len(bytes) != 1+(2*n+2)*crypto.Ed25519KeySize {
+		return errors.New("invalid dleq proof size")
+	}
+
+	offset := 1
+	var err error
+	points := make([]*crypto.Point, 2*n)
+	for i := range points {
+		points[i], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+		offset += crypto.Ed25519KeySize
+	}
+	c, err := new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	offset += crypto.Ed25519KeySize
+	z, err := new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+
+	proof.bases = points[:n]
+	proof.points = points[n:]
+	proof.c = c
+	proof.z = z
+	return nil
+}
+
+// CrossGroupProof proves that a point of this curve and a point of another group, such as the
+// secp256k1 key of an atomic swap counterparty, have the same discrete log.
+// The other groups are not part of this library, a swap protocol plugs its own proof in here.
+// DLEQProof is the CrossGroupProof of the case where both points are on this curve
+type CrossGroupProof interface {
+	// Point returns the point of this curve the proof is about
+	Point() *crypto.Point
+	Verify() (bool, error)
+}
+
+// VerifyCrossGroup checks that proof is a valid proof about point
+func VerifyCrossGroup(point *crypto.Point, proof CrossGroupProof) error {
+	if point == nil || proof == nil || proof.Point() == nil {
+		return errors.New("VerifyCrossGroup input is nil")
+	}
+	if !crypto.IsPointEqual(point, proof.Point()) {
+		return errors.New("VerifyCrossGroup proof is about another point")
+	}
+	res, err := proof.Verify()
+	if err != nil {
+		return err
+	}
+	if !res {
+		return errors.New("VerifyCrossGroup invalid proof")
+	}
+	return nil
+}
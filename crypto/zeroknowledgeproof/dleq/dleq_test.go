@@ -0,0 +1,150 @@
+package dleq
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDLEQ_KeyImage(t *testing.T) {
+	// the key image of the spend key x with P = x*G is x * HashToPoint(P)
+	x := crypto.RandomScalar()
+	P := new(crypto.Point).ScalarMultBase(x)
+	Hp := crypto.HashToPoint(P.ToBytes())
+
+	wit := new(DLEQWitness)
+	wit.Set(x, []*crypto.Point{crypto.G, Hp})
+	proof, err := wit.Prove()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, crypto.IsPointEqual(P, proof.GetPoints()[0]))
+
+	res, err := proof.Verify()
+	assert.Equal(t, true, res)
+	assert.Equal(t, nil, err)
+
+	// a key image made with another scalar is rejected
+	proof.points[1] = new(crypto.Point).ScalarMult(Hp, crypto.RandomScalar())
+	res, err = proof.Verify()
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestDLEQ_MultiBase(t *testing.T) {
+	for n := 1; n <= 8; n++ {
+		bases := make([]*crypto.Point, n)
+		for i := range bases {
+			bases[i] = crypto.RandomPoint()
+		}
+		wit := new(DLEQWitness)
+		wit.Set(crypto.RandomScalar(), bases)
+		proof, err := wit.Prove()
+		assert.Equal(t, nil, err)
+
+		bytes := proof.Bytes()
+		assert.Equal(t, 1+(2*n+2)*crypto.Ed25519KeySize, len(bytes))
+
+		proof2 := new(DLEQProof)
+		assert.Equal(t, nil, proof2.SetBytes(bytes))
+		res, err := proof2.Verify()
+		assert.Equal(t, true, res)
+		assert.Equal(t, nil, err)
+
+		// tampered z
+		proof2.z = crypto.RandomScalar()
+		res, _ = proof2.Verify()
+		assert.Equal(t, false, res)
+	}
+}
+
+func TestDLEQ_TorsionedKeyImage(t *testing.T) {
+	x := crypto.RandomScalar()
+	P := new(crypto.Point).ScalarMultBase(x)
+	Hp := crypto.HashToPoint(P.ToBytes())
+	key := C25519.HexToKey("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	T, err := new(crypto.Point).SetKey(&key) // a point of order 8
+	assert.Equal(t, nil, err)
+
+	// the prover adds T to the key image and guesses j = c mod 8 in its commitment,
+	// then A_1 = k*Hp + j*T equals z*Hp + c*(I + T) as the verifier computes it
+	bases := []*crypto.Point{crypto.G, Hp}
+	points := []*crypto.Point{P, new(crypto.Point).Add(new(crypto.Point).ScalarMult(Hp, x), T)}
+	var proof *DLEQProof
+	for j := 0; proof == nil; j = (j + 1) % 8 {
+		k := crypto.RandomScalar()
+		commitments := []*crypto.Point{
+			new(crypto.Point).ScalarMultBase(k),
+			new(crypto.Point).Add(new(crypto.Point).ScalarMult(Hp, k), new(crypto.Point).ScalarMult(T, new(crypto.Scalar).FromUint64(uint64(j)))),
+		}
+		c := generateChallenge(bases, points, commitments)
+		if int(c.ToBytes()[0]&7) != j {
+			continue
+		}
+		z := new(crypto.Scalar).Sub(k, new(crypto.Scalar).Mul(c, x))
+		proof = &DLEQProof{bases: bases, points: points, c: c, z: z}
+	}
+
+	// the challenge equation holds, only the subgroup check rejects the proof
+	commitments := []*crypto.Point{
+		new(crypto.Point).AddPedersen(proof.z, bases[0], proof.c, points[0]),
+		new(crypto.Point).AddPedersen(proof.z, bases[1], proof.c, points[1]),
+	}
+	assert.Equal(t, 0, crypto.CompareScalar(proof.c, generateChallenge(bases, points, commitments)))
+	res, err := proof.Verify()
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+
+	// so is a torsioned base
+	proof = &DLEQProof{bases: []*crypto.Point{crypto.G, new(crypto.Point).Add(Hp, T)}, points: points, c: proof.c, z: proof.z}
+	res, _ = proof.Verify()
+	assert.Equal(t, false, res)
+}
+
+func TestDLEQ_Invalid(t *testing.T) {
+	wit := new(DLEQWitness)
+	_, err := wit.Prove()
+	assert.NotEqual(t, nil, err)
+
+	proof := new(DLEQProof)
+	assert.NotEqual(t, nil, proof.SetBytes(nil))
+	assert.NotEqual(t, nil, proof.SetBytes([]byte{1, 2, 3}))
+	res, err := proof.Verify()
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+
+	// the identity as a base proves nothing about x, so it is rejected
+	wit.Set(crypto.RandomScalar(), []*crypto.Point{crypto.G, new(crypto.Point).Identity()})
+	proof, err = wit.Prove()
+	assert.Equal(t, nil, err)
+	res, _ = proof.Verify()
+	assert.Equal(t, false, res)
+}
+
+func BenchmarkDLEQ_Verify(b *testing.B) {
+	x := crypto.RandomScalar()
+	wit := new(DLEQWitness)
+	wit.Set(x, []*crypto.Point{crypto.G, crypto.RandomPoint()})
+	proof, _ := wit.Prove()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		proof.Verify()
+	}
+}
+
+func TestDLEQ_CrossGroup(t *testing.T) {
+	x := crypto.RandomScalar()
+	wit := new(DLEQWitness)
+	wit.Set(x, []*crypto.Point{crypto.G, crypto.RandomPoint()})
+	proof, err := wit.Prove()
+	assert.Equal(t, nil, err)
+
+	point := new(crypto.Point).ScalarMultBase(x)
+	assert.Equal(t, nil, VerifyCrossGroup(point, proof))
+	assert.NotEqual(t, nil, VerifyCrossGroup(crypto.RandomPoint(), proof))
+	assert.NotEqual(t, nil, VerifyCrossGroup(point, nil))
+
+	proof.z = crypto.RandomScalar()
+	assert.NotEqual(t, nil, VerifyCrossGroup(point, proof))
+}
@@ -0,0 +1,339 @@
+package frost
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/secretsharing"
+)
+
+// Pedersen DKG with proofs of knowledge, FROST paper Figure 1.
+//
+// Round 1: participant i draws f_i of degree t-1 and broadcasts the commitments C_ik = a_ik*G
+//          with a schnorr proof of knowledge of a_i0
+// Round 2: participant i checks the proofs and sends f_i(l) to each other participant l
+//          over a confidential channel
+// Finish:  participant l checks f_i(l)*G == sum_k l^k*C_ik, its share is s_l = sum_i f_i(l),
+//          the group key is sum_i C_i0
+//
+// A participant which sends an invalid proof or share is reported by a ParticipantError.
+
+const cStringDKG = "frost/dkg"
+
+// DKGRound1Package is broadcast to all the participants
+type DKGRound1Package struct {
+	identifier  Identifier
+	commitments []*crypto.Point
+	proofR      *crypto.Point
+	proofZ      *crypto.Scalar
+}
+
+// DKGRound2Package is sent privately from sender to receiver, it holds a secret share
+type DKGRound2Package struct {
+	sender   Identifier
+	receiver Identifier
+	share    *crypto.Scalar
+}
+
+// DKGParticipant is the state of a participant during the DKG
+type DKGParticipant struct {
+	identifier  Identifier
+	threshold   int
+	n           int
+	coeffs      []*crypto.Scalar
+	commitments []*crypto.Point
+	round1      map[Identifier]*DKGRound1Package
+}
+
+func dkgChallenge(id Identifier, threshold int, n int, c0 *crypto.Point, r *crypto.Point) *crypto.Scalar {
+	bytes := appendIdentifier([]byte(cStringDKG), id)
+	bytes = appendIdentifier(bytes, Identifier(threshold))
+	bytes = appendIdentifier(bytes, Identifier(n))
+	bytes = crypto.AppendPointsToBytesArray(bytes, []*crypto.Point{c0, r})
+	return crypto.HashToScalar(bytes)
+}
+
+// NewDKGParticipant starts the DKG for the participant id out of n with the threshold,
+// it returns the round 1 package to broadcast
+func NewDKGParticipant(id Identifier, threshold int, n int, rng io.Reader) (*DKGParticipant, *DKGRound1Package, error) {
+	if err := validateParams(threshold, n); err != nil {
+		return nil, nil, err
+	}
+	if id == 0 || int(id) > n {
+		return nil, nil, errors.New("frost identifier must be in 1..n")
+	}
+	if rng == nil {
+		rng = rand.Reader
+	}
+	randoms, err := crypto.RandomScalarsFrom(rng, threshold+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	coeffs, k := randoms[:threshold], randoms[threshold]
+
+	p := &DKGParticipant{
+		identifier:  id,
+		threshold:   threshold,
+		n:           n,
+		coeffs:      coeffs,
+		commitments: make([]*crypto.Point, threshold),
+	}
+	for i := range coeffs {
+		p.commitments[i] = new(crypto.Point).ScalarMultBase(coeffs[i])
+	}
+
+	// proof of knowledge of a_0: R = k*G, z = k + a_0*c
+	r := new(crypto.Point).ScalarMultBase(k)
+	c := dkgChallenge(id, threshold, n, p.commitments[0], r)
+	z := new(crypto.Scalar).MulAdd(coeffs[0], c, k)
+	k.FromUint64(0)
+
+	return p, &DKGRound1Package{
+		identifier:  id,
+		commitments: p.commitments,
+		proofR:      r,
+		proofZ:      z,
+	}, nil
+}
+
+func (p DKGParticipant) Identifier() Identifier {
+	return p.identifier
+}
+
+// verify checks the proof of knowledge of the package, z*G - c*C_0 == R
+func (pkg DKGRound1Package) verify(threshold int, n int) error {
+	if len(pkg.commitments) != threshold {
+		return &ParticipantError{pkg.identifier, "wrong number of commitments"}
+	}
+	for _, c := range pkg.commitments {
+		if c == nil || !c.PointValid() {
+			return &ParticipantError{pkg.identifier, "invalid commitment"}
+		}
+	}
+	if pkg.proofR == nil || pkg.proofZ == nil || !pkg.proofZ.ScalarValid() {
+		return &ParticipantError{pkg.identifier, "invalid proof of knowledge"}
+	}
+	c := dkgChallenge(pkg.identifier, threshold, n, pkg.commitments[0], pkg.proofR)
+	cNeg := new(crypto.Scalar).Sub(new(crypto.Scalar).FromUint64(0), c)
+	r := new(crypto.Point).DoubleScalarMultBaseVartime(pkg.proofZ, pkg.commitments[0], cNeg)
+	if !crypto.IsPointEqual(r, pkg.proofR) {
+		return &ParticipantError{pkg.identifier, "invalid proof of knowledge"}
+	}
+	return nil
+}
+
+// Round2 checks the round 1 packages of all the other participants and returns the
+// round 2 packages to send to each of them
+func (p *DKGParticipant) Round2(packages []*DKGRound1Package) ([]*DKGRound2Package, error) {
+	if p.coeffs == nil {
+		return nil, errors.New("frost dkg already finished")
+	}
+	round1 := make(map[Identifier]*DKGRound1Package, p.n)
+	for _, pkg := range packages {
+		if pkg == nil || pkg.identifier == p.identifier {
+			continue
+		}
+		if pkg.identifier == 0 || int(pkg.identifier) > p.n {
+			return nil, &ParticipantError{pkg.identifier, "identifier out of range"}
+		}
+		if _, ok := round1[pkg.identifier]; ok {
+			return nil, &ParticipantError{pkg.identifier, "duplicated round 1 package"}
+		}
+		if err := pkg.verify(p.threshold, p.n); err != nil {
+			return nil, err
+		}
+		round1[pkg.identifier] = pkg
+	}
+	if len(round1) != p.n-1 {
+		return nil, errors.New("frost dkg need the round 1 packages of all the other participants")
+	}
+	p.round1 = round1
+
+	res := make([]*DKGRound2Package, 0, p.n-1)
+	for i := 1; i <= p.n; i++ {
+		id := Identifier(i)
+		if id == p.identifier {
+			continue
+		}
+		res = append(res, &DKGRound2Package{
+			sender:   p.identifier,
+			receiver: id,
+			share:    secretsharing.EvaluatePolynomial(p.coeffs, id.scalar()),
+		})
+	}
+	return res, nil
+}
+
+// Finish checks the shares received from all the other participants and returns the key packages.
+// It erases the secret polynomial, the DKG cannot be run again
+func (p *DKGParticipant) Finish(packages []*DKGRound2Package) (*KeyPackage, *PublicKeyPackage, error) {
+	if p.coeffs == nil {
+		return nil, nil, errors.New("frost dkg already finished")
+	}
+	if p.round1 == nil {
+		return nil, nil, errors.New("frost dkg round 2 is not done")
+	}
+
+	share := secretsharing.EvaluatePolynomial(p.coeffs, p.identifier.scalar())
+	received := make(map[Identifier]bool, p.n)
+	for _, pkg := range packages {
+		if pkg == nil || pkg.receiver != p.identifier {
+			continue
+		}
+		round1, ok := p.round1[pkg.sender]
+		if !ok {
+			return nil, nil, &ParticipantError{pkg.sender, "unknown sender"}
+		}
+		if received[pkg.sender] {
+			return nil, nil, &ParticipantError{pkg.sender, "duplicated round 2 package"}
+		}
+		if pkg.share == nil || !pkg.share.ScalarValid() {
+			return nil, nil, &ParticipantError{pkg.sender, "invalid share"}
+		}
+		expected, err := secretsharing.EvaluateCommitment(round1.commitments, p.identifier.scalar())
+		if err != nil {
+			return nil, nil, &ParticipantError{pkg.sender, err.Error()}
+		}
+		if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(pkg.share), expected) {
+			return nil, nil, &ParticipantError{pkg.sender, "share does not match the commitments"}
+		}
+		received[pkg.sender] = true
+		share.Add(share, pkg.share)
+	}
+	if len(received) != p.n-1 {
+		return nil, nil, errors.New("frost dkg need the shares of all the other participants")
+	}
+
+	// the commitments to the coefficients of the sum of the polynomials
+	sum := make([]*crypto.Point, p.threshold)
+	for k := range sum {
+		sum[k] = new(crypto.Point).Set(p.commitments[k])
+		for _, round1 := range p.round1 {
+			sum[k].Add(sum[k], round1.commitments[k])
+		}
+	}
+
+	pub := &PublicKeyPackage{
+		verifyingShares: make(map[Identifier]*crypto.Point, p.n),
+		groupKey:        sum[0],
+		threshold:       p.threshold,
+	}
+	for i := 1; i <= p.n; i++ {
+		id := Identifier(i)
+		verifyingShare, err := secretsharing.EvaluateCommitment(sum, id.scalar())
+		if err != nil {
+			return nil, nil, err
+		}
+		pub.verifyingShares[id] = verifyingShare
+	}
+	key := &KeyPackage{
+		identifier:     p.identifier,
+		secretShare:    share,
+		verifyingShare: pub.verifyingShares[p.identifier],
+		groupKey:       pub.groupKey,
+		threshold:      p.threshold,
+	}
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(share), key.verifyingShare) {
+		return nil, nil, errors.New("frost dkg secret share does not match the verifying share")
+	}
+
+	for _, c := range p.coeffs {
+		c.FromUint64(0)
+	}
+	p.coeffs = nil
+	p.round1 = nil
+	return key, pub, nil
+}
+
+// Bytes encodes the package as identifier || t || commitments || R || z
+func (pkg DKGRound1Package) Bytes() []byte {
+	t := len(pkg.commitments)
+	if t == 0 || pkg.proofR == nil || pkg.proofZ == nil {
+		return []byte{}
+	}
+	res := make([]byte, 0, 2*identifierSize+(t+2)*crypto.Ed25519KeySize)
+	res = appendIdentifier(res, pkg.identifier)
+	res = appendIdentifier(res, Identifier(t))
+	res = crypto.AppendPointsToBytesArray(res, pkg.commitments)
+	res = append(res, pkg.proofR.ToBytes()...)
+	res = append(res, pkg.proofZ.ToBytes()...)
+	return res
+}
+
+func (pkg *DKGRound1Package) SetBytes(bytes []byte) error {
+	id, err := readIdentifier(bytes)
+	if err != nil {
+		return err
+	}
+	if len(bytes) < 2*identifierSize {
+		return errors.New("invalid frost dkg round 1 package size")
+	}
+	t := int(bytes[2])<<8 | int(bytes[3])
+	if t == 0 || len(bytes) != 2*identifierSize+(t+2)*crypto.Ed25519KeySize {
+		return errors.New("invalid frost dkg round 1 package size")
+	}
+
+	offset := 2 * identifierSize
+	points := make([]*crypto.Point, t+1)
+	for i := range points {
+		points[i], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+		offset += crypto.Ed25519KeySize
+	}
+	z, err := new(crypto.Scalar).FromBytes(bytes[offset:])
+	if err != nil {
+		return err
+	}
+
+	pkg.identifier = id
+	pkg.commitments = points[:t]
+	pkg.proofR = points[t]
+	pkg.proofZ = z
+	return nil
+}
+
+func (pkg DKGRound2Package) Sender() Identifier {
+	return pkg.sender
+}
+
+func (pkg DKGRound2Package) Receiver() Identifier {
+	return pkg.receiver
+}
+
+// Bytes encodes the package as sender || receiver || share, it must be encrypted for the receiver
+func (pkg DKGRound2Package) Bytes() []byte {
+	if pkg.share == nil {
+		return []byte{}
+	}
+	res := make([]byte, 0, 2*identifierSize+crypto.Ed25519KeySize)
+	res = appendIdentifier(res, pkg.sender)
+	res = appendIdentifier(res, pkg.receiver)
+	res = append(res, pkg.share.ToBytes()...)
+	return res
+}
+
+func (pkg *DKGRound2Package) SetBytes(bytes []byte) error {
+	if len(bytes) != 2*identifierSize+crypto.Ed25519KeySize {
+		return errors.New("invalid frost dkg round 2 package size")
+	}
+	sender, err := readIdentifier(bytes)
+	if err != nil {
+		return err
+	}
+	receiver, err := readIdentifier(bytes[identifierSize:])
+	if err != nil {
+		return err
+	}
+	share, err := new(crypto.Scalar).FromBytes(bytes[2*identifierSize:])
+	if err != nil {
+		return err
+	}
+	pkg.sender = sender
+	pkg.receiver = receiver
+	pkg.share = share
+	return nil
+}
@@ -0,0 +1,254 @@
+package frost
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+// runDKG runs the DKG between n participants, passing every package through its bytes
+func runDKG(t *testing.T, threshold int, n int) ([]*KeyPackage, []*PublicKeyPackage) {
+	participants := make([]*DKGParticipant, n)
+	round1 := make([]*DKGRound1Package, n)
+	for i := 0; i < n; i++ {
+		p, pkg, err := NewDKGParticipant(Identifier(i+1), threshold, n, nil)
+		assert.Equal(t, nil, err)
+		participants[i] = p
+		round1[i] = new(DKGRound1Package)
+		assert.Equal(t, nil, round1[i].SetBytes(pkg.Bytes()))
+	}
+
+	round2 := make([]*DKGRound2Package, 0, n*(n-1))
+	for _, p := range participants {
+		pkgs, err := p.Round2(round1)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, n-1, len(pkgs))
+		for _, pkg := range pkgs {
+			decoded := new(DKGRound2Package)
+			assert.Equal(t, nil, decoded.SetBytes(pkg.Bytes()))
+			round2 = append(round2, decoded)
+		}
+	}
+
+	keys := make([]*KeyPackage, n)
+	pubs := make([]*PublicKeyPackage, n)
+	for i, p := range participants {
+		var err error
+		keys[i], pubs[i], err = p.Finish(round2)
+		assert.Equal(t, nil, err)
+	}
+	return keys, pubs
+}
+
+// runSigning signs the message with the keys, passing every message through its bytes
+func runSigning(t *testing.T, coord *Coordinator, keys []*KeyPackage, message []byte) (*SigningPackage, []*SignatureShare) {
+	sessions := make([]*SigningSession, len(keys))
+	commitments := make([]*SigningCommitment, len(keys))
+	for i, key := range keys {
+		var err error
+		sessions[i], err = NewSigningSession(key, nil)
+		assert.Equal(t, nil, err)
+		commitments[i] = new(SigningCommitment)
+		assert.Equal(t, nil, commitments[i].SetBytes(sessions[i].Commitment().Bytes()))
+	}
+
+	pkg, err := coord.NewSigningPackage(commitments, message)
+	assert.Equal(t, nil, err)
+	received := new(SigningPackage)
+	assert.Equal(t, nil, received.SetBytes(pkg.Bytes()))
+	assert.Equal(t, message, received.Message())
+
+	shares := make([]*SignatureShare, len(keys))
+	for i, session := range sessions {
+		share, err := session.Sign(received)
+		assert.Equal(t, nil, err)
+		shares[i] = new(SignatureShare)
+		assert.Equal(t, nil, shares[i].SetBytes(share.Bytes()))
+		assert.Equal(t, nil, coord.VerifySignatureShare(pkg, shares[i]))
+	}
+	return pkg, shares
+}
+
+func TestFROST_TrustedDealer(t *testing.T) {
+	for _, tn := range [][2]int{{1, 1}, {2, 3}, {3, 5}, {5, 5}} {
+		threshold, n := tn[0], tn[1]
+		secret := crypto.RandomScalar()
+		keys, pub, err := TrustedDealerKeygen(secret, threshold, n, nil)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(secret), pub.GroupKey()))
+
+		// any threshold of the shares interpolates to the secret
+		ids := make([]Identifier, threshold)
+		for i := range ids {
+			ids[i] = keys[n-threshold+i].identifier
+		}
+		sum := new(crypto.Scalar).FromUint64(0)
+		for i, id := range ids {
+			lambda, err := lagrangeCoefficient(id, ids)
+			assert.Equal(t, nil, err)
+			sum.MulAdd(lambda, keys[n-threshold+i].secretShare, sum)
+		}
+		assert.Equal(t, 0, crypto.CompareScalar(secret, sum))
+
+		// sign with the last threshold participants
+		coord := NewCoordinator(pub)
+		message := []byte("bridge withdrawal")
+		pkg, shares := runSigning(t, coord, keys[n-threshold:], message)
+		sig, err := coord.Aggregate(pkg, shares)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, schnorr.Verify(pub.GroupKey(), message, sig))
+		assert.Equal(t, false, schnorr.Verify(pub.GroupKey(), []byte("other"), sig))
+	}
+
+	_, _, err := TrustedDealerKeygen(nil, 0, 3, nil)
+	assert.NotEqual(t, nil, err)
+	_, _, err = TrustedDealerKeygen(nil, 4, 3, nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFROST_DKG(t *testing.T) {
+	threshold, n := 3, 5
+	keys, pubs := runDKG(t, threshold, n)
+
+	// every participant computes the same public key package
+	for i := 1; i < n; i++ {
+		assert.Equal(t, true, crypto.IsPointEqual(pubs[0].GroupKey(), pubs[i].GroupKey()))
+		for id := Identifier(1); id <= Identifier(n); id++ {
+			assert.Equal(t, true, crypto.IsPointEqual(pubs[0].VerifyingShare(id), pubs[i].VerifyingShare(id)))
+		}
+	}
+	assert.Equal(t, (*crypto.Point)(nil), pubs[0].VerifyingShare(Identifier(n+1)))
+
+	// any threshold subset signs
+	coord := NewCoordinator(pubs[0])
+	for _, subset := range [][]int{{0, 1, 2}, {1, 3, 4}, {0, 2, 3, 4}} {
+		signers := make([]*KeyPackage, len(subset))
+		for i, j := range subset {
+			signers[i] = keys[j]
+		}
+		message := []byte("custody key")
+		pkg, shares := runSigning(t, coord, signers, message)
+		sig, err := coord.Aggregate(pkg, shares)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, schnorr.Verify(pubs[0].GroupKey(), message, sig))
+	}
+
+	// less than threshold signers cannot make a signing package
+	commitments := make([]*SigningCommitment, threshold-1)
+	for i := range commitments {
+		session, _ := NewSigningSession(keys[i], nil)
+		commitments[i] = session.Commitment()
+	}
+	_, err := coord.NewSigningPackage(commitments, []byte("m"))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFROST_DKG_IdentifiableAbort(t *testing.T) {
+	threshold, n := 2, 3
+	participants := make([]*DKGParticipant, n)
+	round1 := make([]*DKGRound1Package, n)
+	for i := 0; i < n; i++ {
+		participants[i], round1[i], _ = NewDKGParticipant(Identifier(i+1), threshold, n, nil)
+	}
+
+	// participant 2 changes its commitment after the proof of knowledge
+	forged := *round1[1]
+	forged.commitments = append([]*crypto.Point{crypto.RandomPoint()}, round1[1].commitments[1:]...)
+	_, err := participants[0].Round2([]*DKGRound1Package{round1[0], &forged, round1[2]})
+	var perr *ParticipantError
+	assert.Equal(t, true, errors.As(err, &perr))
+	assert.Equal(t, Identifier(2), perr.Identifier)
+
+	// participant 3 sends a wrong share to participant 1
+	round2 := make([]*DKGRound2Package, 0)
+	for _, p := range participants {
+		pkgs, err := p.Round2(round1)
+		assert.Equal(t, nil, err)
+		round2 = append(round2, pkgs...)
+	}
+	for _, pkg := range round2 {
+		if pkg.sender == 3 && pkg.receiver == 1 {
+			pkg.share = crypto.RandomScalar()
+		}
+	}
+	_, _, err = participants[0].Finish(round2)
+	assert.Equal(t, true, errors.As(err, &perr))
+	assert.Equal(t, Identifier(3), perr.Identifier)
+
+	// the others are not affected
+	_, _, err = participants[1].Finish(round2)
+	assert.Equal(t, nil, err)
+	_, _, err = participants[1].Finish(round2)
+	assert.NotEqual(t, nil, err)
+
+	// missing packages
+	_, err = participants[2].Round2(round1[1:2])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFROST_SigningIdentifiableAbort(t *testing.T) {
+	keys, pub, _ := TrustedDealerKeygen(nil, 3, 4, nil)
+	coord := NewCoordinator(pub)
+	pkg, shares := runSigning(t, coord, keys[:3], []byte("message"))
+
+	// participant 2 sends a wrong share
+	bad := &SignatureShare{identifier: shares[1].identifier, z: crypto.RandomScalar()}
+	_, err := coord.Aggregate(pkg, []*SignatureShare{shares[0], bad, shares[2]})
+	var perr *ParticipantError
+	assert.Equal(t, true, errors.As(err, &perr))
+	assert.Equal(t, Identifier(2), perr.Identifier)
+
+	// a participant out of the signing package
+	outsider := &SignatureShare{identifier: keys[3].identifier, z: shares[2].z}
+	_, err = coord.Aggregate(pkg, []*SignatureShare{shares[0], shares[1], outsider})
+	assert.Equal(t, true, errors.As(err, &perr))
+	assert.Equal(t, Identifier(4), perr.Identifier)
+
+	// duplicated and missing shares
+	_, err = coord.Aggregate(pkg, []*SignatureShare{shares[0], shares[1], shares[1]})
+	assert.NotEqual(t, nil, err)
+	_, err = coord.Aggregate(pkg, shares[:2])
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFROST_NonceReuse(t *testing.T) {
+	keys, pub, _ := TrustedDealerKeygen(nil, 2, 2, nil)
+	coord := NewCoordinator(pub)
+
+	sessions := make([]*SigningSession, 2)
+	commitments := make([]*SigningCommitment, 2)
+	for i := range keys {
+		sessions[i], _ = NewSigningSession(keys[i], nil)
+		commitments[i] = sessions[i].Commitment()
+	}
+	pkg1, _ := coord.NewSigningPackage(commitments, []byte("message 1"))
+	pkg2, _ := coord.NewSigningPackage(commitments, []byte("message 2"))
+
+	_, err := sessions[0].Sign(pkg1)
+	assert.Equal(t, nil, err)
+	_, err = sessions[0].Sign(pkg2)
+	assert.NotEqual(t, nil, err)
+
+	// a copy of the session shares the erased nonces
+	copied := *sessions[1]
+	_, err = sessions[1].Sign(pkg1)
+	assert.Equal(t, nil, err)
+	_, err = copied.Sign(pkg2)
+	assert.NotEqual(t, nil, err)
+
+	// a package without the commitment of the session
+	session, _ := NewSigningSession(keys[0], nil)
+	_, err = session.Sign(pkg1)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestFROST_Bytes(t *testing.T) {
+	assert.NotEqual(t, nil, new(SigningCommitment).SetBytes(make([]byte, SigningCommitmentSize-1)))
+	assert.NotEqual(t, nil, new(SignatureShare).SetBytes(make([]byte, SignatureShareSize)))
+	assert.NotEqual(t, nil, new(SigningPackage).SetBytes([]byte{0, 2}))
+	assert.NotEqual(t, nil, new(DKGRound1Package).SetBytes([]byte{0, 1, 0, 1}))
+	assert.NotEqual(t, nil, new(DKGRound2Package).SetBytes(make([]byte, 2*identifierSize+crypto.Ed25519KeySize)))
+}
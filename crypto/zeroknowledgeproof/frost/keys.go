@@ -0,0 +1,162 @@
+package frost
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/secretsharing"
+)
+
+// FROST t-of-n threshold schnorr signatures, following RFC 9591.
+// The signatures verify with schnorr.Verify under the group key, so the challenge is
+// schnorr.Challenge instead of the one of the RFC ciphersuites.
+//
+// The keys are made either by a trusted dealer or by the Pedersen DKG of the FROST paper.
+// A participant with the identifier i holds the secret share s_i = f(i) of the group secret f(0),
+// every party knows the verifying shares Y_i = s_i*G and the group key Y = f(0)*G.
+//
+// See reference: https://www.rfc-editor.org/rfc/rfc9591.html, https://eprint.iacr.org/2020/852.pdf
+
+// Identifier is the non-zero x-coordinate of the share of a participant
+type Identifier uint16
+
+// MaxParticipants is the max number of participants, the identifiers are 1..n
+const MaxParticipants = 1<<16 - 1
+
+const identifierSize = 2
+
+func (id Identifier) scalar() *crypto.Scalar {
+	return new(crypto.Scalar).FromUint64(uint64(id))
+}
+
+func appendIdentifier(bytes []byte, id Identifier) []byte {
+	return append(bytes, byte(id>>8), byte(id))
+}
+
+func readIdentifier(bytes []byte) (Identifier, error) {
+	if len(bytes) < identifierSize {
+		return 0, errors.New("frost identifier is too short")
+	}
+	id := Identifier(bytes[0])<<8 | Identifier(bytes[1])
+	if id == 0 {
+		return 0, errors.New("frost identifier must not be zero")
+	}
+	return id, nil
+}
+
+// ParticipantError reports the participant which misbehaved, so that it can be excluded
+type ParticipantError struct {
+	Identifier Identifier
+	Reason     string
+}
+
+func (err *ParticipantError) Error() string {
+	return fmt.Sprintf("frost participant %d: %s", err.Identifier, err.Reason)
+}
+
+// KeyPackage is the long-lived secret of a participant
+type KeyPackage struct {
+	identifier     Identifier
+	secretShare    *crypto.Scalar
+	verifyingShare *crypto.Point
+	groupKey       *crypto.Point
+	threshold      int
+}
+
+// PublicKeyPackage is known to every party, the coordinator verifies the signature shares with it
+type PublicKeyPackage struct {
+	verifyingShares map[Identifier]*crypto.Point
+	groupKey        *crypto.Point
+	threshold       int
+}
+
+func (key KeyPackage) Identifier() Identifier {
+	return key.identifier
+}
+
+func (key KeyPackage) GroupKey() *crypto.Point {
+	return new(crypto.Point).Set(key.groupKey)
+}
+
+func (key KeyPackage) Threshold() int {
+	return key.threshold
+}
+
+func (pub PublicKeyPackage) GroupKey() *crypto.Point {
+	return new(crypto.Point).Set(pub.groupKey)
+}
+
+func (pub PublicKeyPackage) Threshold() int {
+	return pub.threshold
+}
+
+// VerifyingShare returns Y_i of the participant, nil if id is not a participant
+func (pub PublicKeyPackage) VerifyingShare(id Identifier) *crypto.Point {
+	share, ok := pub.verifyingShares[id]
+	if !ok {
+		return nil
+	}
+	return new(crypto.Point).Set(share)
+}
+
+func validateParams(threshold int, n int) error {
+	if threshold < 1 || n < threshold || n > MaxParticipants {
+		return errors.New("frost invalid threshold or number of participants")
+	}
+	return nil
+}
+
+// lagrangeCoefficient returns the coefficient of the share of id for the interpolation
+// at zero over the participants ids, see secretsharing.LagrangeCoefficient
+func lagrangeCoefficient(id Identifier, ids []Identifier) (*crypto.Scalar, error) {
+	indices := make([]uint16, len(ids))
+	for i, j := range ids {
+		indices[i] = uint16(j)
+	}
+	return secretsharing.LagrangeCoefficient(uint16(id), indices)
+}
+
+// TrustedDealerKeygen splits the secret into n shares, any threshold of them can sign.
+// A nil secret draws a random group secret. The dealer learns the secret and must erase it
+func TrustedDealerKeygen(secret *crypto.Scalar, threshold int, n int, rng io.Reader) ([]*KeyPackage, *PublicKeyPackage, error) {
+	if err := validateParams(threshold, n); err != nil {
+		return nil, nil, err
+	}
+	if rng == nil {
+		rng = rand.Reader
+	}
+	coeffs, err := crypto.RandomScalarsFrom(rng, threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	if secret != nil {
+		coeffs[0].Set(secret)
+	}
+
+	pub := &PublicKeyPackage{
+		verifyingShares: make(map[Identifier]*crypto.Point, n),
+		groupKey:        new(crypto.Point).ScalarMultBase(coeffs[0]),
+		threshold:       threshold,
+	}
+	keys := make([]*KeyPackage, n)
+	for i := 0; i < n; i++ {
+		id := Identifier(i + 1)
+		share := secretsharing.EvaluatePolynomial(coeffs, id.scalar())
+		keys[i] = &KeyPackage{
+			identifier:     id,
+			secretShare:    share,
+			verifyingShare: new(crypto.Point).ScalarMultBase(share),
+			groupKey:       pub.groupKey,
+			threshold:      threshold,
+		}
+		pub.verifyingShares[id] = keys[i].verifyingShare
+	}
+
+	for _, c := range coeffs {
+		c.FromUint64(0)
+	}
+	return keys, pub, nil
+}
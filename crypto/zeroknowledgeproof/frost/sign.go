@@ -0,0 +1,393 @@
+package frost
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/schnorr"
+)
+
+// Two-round signing, RFC 9591 section 5.
+//
+// Round 1:  each participant draws the hiding and binding nonces d_i, e_i and sends the
+//           commitments D_i = d_i*G, E_i = e_i*G to the coordinator
+// Round 2:  the coordinator sends the signing package, the commitments of at least t participants
+//           and the message. Each of them computes the binding factors rho_i, the group commitment
+//           R = sum D_i + rho_i*E_i, c = schnorr.Challenge(R, Y, m) and sends z_i = d_i + e_i*rho_i + lambda_i*s_i*c
+// Aggregate: the coordinator checks z_i*G == D_i + rho_i*E_i + (c*lambda_i)*Y_i for each share,
+//           reporting the participant of an invalid share, the signature is (R, sum z_i)
+//
+// The nonces live only inside a SigningSession and are erased by its first Sign.
+
+const (
+	SigningCommitmentSize = identifierSize + 2*crypto.Ed25519KeySize
+	SignatureShareSize    = identifierSize + crypto.Ed25519KeySize
+)
+
+const (
+	cStringNonce       = "frost/nonce"
+	cStringRho         = "frost/rho"
+	cStringMessage     = "frost/msg"
+	cStringCommitments = "frost/com"
+)
+
+// SigningCommitment is the round 1 message of a participant
+type SigningCommitment struct {
+	identifier Identifier
+	hiding     *crypto.Point
+	binding    *crypto.Point
+}
+
+// SigningPackage is sent by the coordinator to the signers, the commitments are sorted by identifier
+type SigningPackage struct {
+	commitments []*SigningCommitment
+	message     []byte
+}
+
+// SignatureShare is the round 2 message of a participant
+type SignatureShare struct {
+	identifier Identifier
+	z          *crypto.Scalar
+}
+
+// signingNonces is shared by all the copies of a SigningSession, so that erasing it erases it everywhere
+type signingNonces struct {
+	hiding  *crypto.Scalar
+	binding *crypto.Scalar
+}
+
+// SigningSession is the state of a participant for one signature
+type SigningSession struct {
+	key        *KeyPackage
+	nonces     *signingNonces
+	commitment *SigningCommitment
+}
+
+// Coordinator collects the commitments and the signature shares of the signers
+type Coordinator struct {
+	pub *PublicKeyPackage
+}
+
+// NewSigningSession draws the nonces of the participant from rng mixed with its secret share,
+// crypto/rand if rng is nil. rng must never repeat its output
+func NewSigningSession(key *KeyPackage, rng io.Reader) (*SigningSession, error) {
+	if key == nil || key.secretShare == nil {
+		return nil, errors.New("frost key package is nil")
+	}
+	if rng == nil {
+		rng = rand.Reader
+	}
+	nonceReader, err := crypto.NewNonceReader(key.secretShare.ToBytes(), rng, []byte(cStringNonce))
+	if err != nil {
+		return nil, err
+	}
+	k, err := crypto.RandomScalarsFrom(nonceReader, 2)
+	if err != nil {
+		return nil, err
+	}
+	return &SigningSession{
+		key:    key,
+		nonces: &signingNonces{hiding: k[0], binding: k[1]},
+		commitment: &SigningCommitment{
+			identifier: key.identifier,
+			hiding:     new(crypto.Point).ScalarMultBase(k[0]),
+			binding:    new(crypto.Point).ScalarMultBase(k[1]),
+		},
+	}, nil
+}
+
+// Commitment returns the round 1 message of the participant
+func (session SigningSession) Commitment() *SigningCommitment {
+	return &SigningCommitment{
+		identifier: session.commitment.identifier,
+		hiding:     new(crypto.Point).Set(session.commitment.hiding),
+		binding:    new(crypto.Point).Set(session.commitment.binding),
+	}
+}
+
+// NewSigningPackage sorts the commitments of the signers by identifier
+func NewSigningPackage(commitments []*SigningCommitment, message []byte) (*SigningPackage, error) {
+	sorted := make([]*SigningCommitment, len(commitments))
+	for i, c := range commitments {
+		if c == nil || c.hiding == nil || c.binding == nil || c.identifier == 0 {
+			return nil, errors.New("frost invalid signing commitment")
+		}
+		sorted[i] = c
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].identifier < sorted[j].identifier
+	})
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].identifier == sorted[i-1].identifier {
+			return nil, &ParticipantError{sorted[i].identifier, "duplicated signing commitment"}
+		}
+	}
+	return &SigningPackage{
+		commitments: sorted,
+		message:     append([]byte{}, message...),
+	}, nil
+}
+
+func (pkg SigningPackage) Message() []byte {
+	return append([]byte{}, pkg.message...)
+}
+
+func (pkg SigningPackage) identifiers() []Identifier {
+	ids := make([]Identifier, len(pkg.commitments))
+	for i, c := range pkg.commitments {
+		ids[i] = c.identifier
+	}
+	return ids
+}
+
+func (pkg SigningPackage) commitment(id Identifier) *SigningCommitment {
+	for _, c := range pkg.commitments {
+		if c.identifier == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// bindingFactors returns rho_i = H(Y || H(m) || H(commitments) || i) for each signer
+func (pkg SigningPackage) bindingFactors(groupKey *crypto.Point) map[Identifier]*crypto.Scalar {
+	encoded := []byte(cStringCommitments)
+	for _, c := range pkg.commitments {
+		encoded = append(encoded, c.Bytes()...)
+	}
+	prefix := append([]byte(cStringRho), groupKey.ToBytes()...)
+	prefix = append(prefix, crypto.HashToScalar(append([]byte(cStringMessage), pkg.message...)).ToBytes()...)
+	prefix = append(prefix, crypto.HashToScalar(encoded).ToBytes()...)
+
+	res := make(map[Identifier]*crypto.Scalar, len(pkg.commitments))
+	for _, c := range pkg.commitments {
+		input := appendIdentifier(append([]byte{}, prefix...), c.identifier)
+		res[c.identifier] = crypto.HashToScalar(input)
+	}
+	return res
+}
+
+// groupCommitment returns R = sum D_i + rho_i*E_i and the challenge c
+func (pkg SigningPackage) groupCommitment(groupKey *crypto.Point, rhos map[Identifier]*crypto.Scalar) (*crypto.Point, *crypto.Scalar) {
+	scalars := make([]*crypto.Scalar, 0, 2*len(pkg.commitments))
+	points := make([]*crypto.Point, 0, 2*len(pkg.commitments))
+	for _, c := range pkg.commitments {
+		scalars = append(scalars, new(crypto.Scalar).FromUint64(1), rhos[c.identifier])
+		points = append(points, c.hiding, c.binding)
+	}
+	r := new(crypto.Point).MultiScalarMult(scalars, points)
+	return r, schnorr.Challenge(r, groupKey, pkg.message)
+}
+
+// Sign returns the round 2 message of the participant for the signing package.
+// It erases the nonces, any later call fails
+func (session *SigningSession) Sign(pkg *SigningPackage) (*SignatureShare, error) {
+	if pkg == nil || len(pkg.commitments) < session.key.threshold {
+		return nil, errors.New("frost signing package needs the commitments of at least threshold signers")
+	}
+	own := pkg.commitment(session.key.identifier)
+	if own == nil || !crypto.IsPointEqual(own.hiding, session.commitment.hiding) ||
+		!crypto.IsPointEqual(own.binding, session.commitment.binding) {
+		return nil, errors.New("frost signing package does not hold the commitment of this session")
+	}
+	nonces := session.nonces
+	if nonces.hiding == nil || nonces.binding == nil {
+		return nil, errors.New("frost nonce already used")
+	}
+	d, e := nonces.hiding, nonces.binding
+	nonces.hiding, nonces.binding = nil, nil
+
+	lambda, err := lagrangeCoefficient(session.key.identifier, pkg.identifiers())
+	if err != nil {
+		return nil, err
+	}
+	rhos := pkg.bindingFactors(session.key.groupKey)
+	_, c := pkg.groupCommitment(session.key.groupKey, rhos)
+
+	// z_i = d_i + e_i*rho_i + lambda_i*s_i*c
+	z := new(crypto.Scalar).Mul(lambda, session.key.secretShare)
+	z.MulAdd(z, c, d)
+	z.MulAdd(e, rhos[session.key.identifier], z)
+
+	d.FromUint64(0)
+	e.FromUint64(0)
+	return &SignatureShare{identifier: session.key.identifier, z: z}, nil
+}
+
+func NewCoordinator(pub *PublicKeyPackage) *Coordinator {
+	return &Coordinator{pub: pub}
+}
+
+// NewSigningPackage checks that the commitments come from at least threshold known participants
+func (coord Coordinator) NewSigningPackage(commitments []*SigningCommitment, message []byte) (*SigningPackage, error) {
+	if len(commitments) < coord.pub.threshold {
+		return nil, errors.New("frost signing package needs the commitments of at least threshold signers")
+	}
+	for _, c := range commitments {
+		if c != nil && coord.pub.verifyingShares[c.identifier] == nil {
+			return nil, &ParticipantError{c.identifier, "unknown participant"}
+		}
+	}
+	return NewSigningPackage(commitments, message)
+}
+
+// VerifySignatureShare checks the share of a signer, z_i*G == D_i + rho_i*E_i + (c*lambda_i)*Y_i
+func (coord Coordinator) VerifySignatureShare(pkg *SigningPackage, share *SignatureShare) error {
+	rhos := pkg.bindingFactors(coord.pub.groupKey)
+	_, c := pkg.groupCommitment(coord.pub.groupKey, rhos)
+	return coord.verifyShare(pkg, share, rhos, c)
+}
+
+func (coord Coordinator) verifyShare(pkg *SigningPackage, share *SignatureShare, rhos map[Identifier]*crypto.Scalar, c *crypto.Scalar) error {
+	if share == nil {
+		return errors.New("frost signature share is nil")
+	}
+	commitment := pkg.commitment(share.identifier)
+	verifyingShare := coord.pub.verifyingShares[share.identifier]
+	if commitment == nil || verifyingShare == nil {
+		return &ParticipantError{share.identifier, "not a signer of the signing package"}
+	}
+	if share.z == nil || !share.z.ScalarValid() {
+		return &ParticipantError{share.identifier, "invalid signature share"}
+	}
+	lambda, err := lagrangeCoefficient(share.identifier, pkg.identifiers())
+	if err != nil {
+		return err
+	}
+
+	expected := new(crypto.Point).AddPedersen(rhos[share.identifier], commitment.binding, new(crypto.Scalar).Mul(c, lambda), verifyingShare)
+	expected.Add(expected, commitment.hiding)
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(share.z), expected) {
+		return &ParticipantError{share.identifier, "invalid signature share"}
+	}
+	return nil
+}
+
+// Aggregate checks the shares of all the signers of the signing package and sums them into the signature.
+// An invalid share is reported by a ParticipantError
+func (coord Coordinator) Aggregate(pkg *SigningPackage, shares []*SignatureShare) (*schnorr.Signature, error) {
+	if pkg == nil || len(shares) != len(pkg.commitments) {
+		return nil, errors.New("frost aggregate needs one signature share per signer")
+	}
+	rhos := pkg.bindingFactors(coord.pub.groupKey)
+	r, c := pkg.groupCommitment(coord.pub.groupKey, rhos)
+
+	z := new(crypto.Scalar).FromUint64(0)
+	seen := make(map[Identifier]bool, len(shares))
+	for _, share := range shares {
+		if err := coord.verifyShare(pkg, share, rhos, c); err != nil {
+			return nil, err
+		}
+		if seen[share.identifier] {
+			return nil, &ParticipantError{share.identifier, "duplicated signature share"}
+		}
+		seen[share.identifier] = true
+		z.Add(z, share.z)
+	}
+	return schnorr.NewSignature(r, z), nil
+}
+
+func (c SigningCommitment) Identifier() Identifier {
+	return c.identifier
+}
+
+// Bytes encodes the commitment as identifier || D || E
+func (c SigningCommitment) Bytes() []byte {
+	if c.hiding == nil || c.binding == nil {
+		return []byte{}
+	}
+	res := make([]byte, 0, SigningCommitmentSize)
+	res = appendIdentifier(res, c.identifier)
+	res = append(res, c.hiding.ToBytes()...)
+	res = append(res, c.binding.ToBytes()...)
+	return res
+}
+
+func (c *SigningCommitment) SetBytes(bytes []byte) error {
+	if len(bytes) != SigningCommitmentSize {
+		return errors.New("invalid frost signing commitment size")
+	}
+	id, err := readIdentifier(bytes)
+	if err != nil {
+		return err
+	}
+	hiding, err := new(crypto.Point).FromBytes(bytes[identifierSize : identifierSize+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	binding, err := new(crypto.Point).FromBytes(bytes[identifierSize+crypto.Ed25519KeySize:])
+	if err != nil {
+		return err
+	}
+	c.identifier = id
+	c.hiding = hiding
+	c.binding = binding
+	return nil
+}
+
+// Bytes encodes the signing package as number of signers || commitments || message
+func (pkg SigningPackage) Bytes() []byte {
+	res := make([]byte, 0, identifierSize+len(pkg.commitments)*SigningCommitmentSize+len(pkg.message))
+	res = appendIdentifier(res, Identifier(len(pkg.commitments)))
+	for _, c := range pkg.commitments {
+		res = append(res, c.Bytes()...)
+	}
+	return append(res, pkg.message...)
+}
+
+func (pkg *SigningPackage) SetBytes(bytes []byte) error {
+	if len(bytes) < identifierSize {
+		return errors.New("invalid frost signing package size")
+	}
+	n := int(bytes[0])<<8 | int(bytes[1])
+	if len(bytes) < identifierSize+n*SigningCommitmentSize {
+		return errors.New("invalid frost signing package size")
+	}
+	commitments := make([]*SigningCommitment, n)
+	offset := identifierSize
+	for i := range commitments {
+		commitments[i] = new(SigningCommitment)
+		if err := commitments[i].SetBytes(bytes[offset : offset+SigningCommitmentSize]); err != nil {
+			return err
+		}
+		offset += SigningCommitmentSize
+	}
+	res, err := NewSigningPackage(commitments, bytes[offset:])
+	if err != nil {
+		return err
+	}
+	*pkg = *res
+	return nil
+}
+
+func (share SignatureShare) Identifier() Identifier {
+	return share.identifier
+}
+
+// Bytes encodes the share as identifier || z
+func (share SignatureShare) Bytes() []byte {
+	if share.z == nil {
+		return []byte{}
+	}
+	return append(appendIdentifier(make([]byte, 0, SignatureShareSize), share.identifier), share.z.ToBytes()...)
+}
+
+func (share *SignatureShare) SetBytes(bytes []byte) error {
+	if len(bytes) != SignatureShareSize {
+		return errors.New("invalid frost signature share size")
+	}
+	id, err := readIdentifier(bytes)
+	if err != nil {
+		return err
+	}
+	z, err := new(crypto.Scalar).FromBytes(bytes[identifierSize:])
+	if err != nil {
+		return err
+	}
+	share.identifier = id
+	share.z = z
+	return nil
+}
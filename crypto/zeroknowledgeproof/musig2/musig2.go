@@ -0,0 +1,314 @@
+package musig2
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/schnorr"
+)
+
+// MuSig2 n-of-n multisignature, the signature verifies with schnorr.Verify under the aggregated key.
+//
+// Key aggregation: L = H(P_1 || ... || P_n), a_i = H(L || P_i), X = sum a_i*P_i
+// Round 1:         each signer draws k_i1, k_i2 and sends the nonce commitments R_i1 = k_i1*G, R_i2 = k_i2*G
+// Round 2:         R_1 = sum R_i1, R_2 = sum R_i2, b = H(X || R_1 || R_2 || m), R = R_1 + b*R_2,
+//                  e = schnorr.Challenge(R, X, m), each signer sends s_i = k_i1 + b*k_i2 + e*a_i*x_i
+// Combine:         s_i*G == R_i1 + b*R_i2 + (e*a_i)*P_i for each signer, the signature is (R, sum s_i)
+//
+// The secret nonces live only inside a Session and are erased by its first Sign,
+// so a nonce can never sign two messages.
+//
+// See reference: https://eprint.iacr.org/2020/1261.pdf
+
+const (
+	PublicNonceSize      = 2 * crypto.Ed25519KeySize
+	PartialSignatureSize = crypto.Ed25519KeySize
+)
+
+const (
+	cStringKeyAggList  = "musig2/keyagg/list"
+	cStringKeyAggCoeff = "musig2/keyagg/coeff"
+	cStringNonce       = "musig2/nonce"
+	cStringNonceCoeff  = "musig2/noncecoeff"
+)
+
+// KeyAggContext holds the public keys of the signers in their signing order and the aggregated key
+type KeyAggContext struct {
+	pks    []*crypto.Point
+	coeffs []*crypto.Scalar
+	aggPk  *crypto.Point
+}
+
+// PublicNonce is the round 1 message, the commitments to the secret nonces of a signer
+type PublicNonce struct {
+	r1 *crypto.Point
+	r2 *crypto.Point
+}
+
+// PartialSignature is the round 2 message
+type PartialSignature struct {
+	s *crypto.Scalar
+}
+
+// SigningContext is computed by every party from the public nonces of all signers and the message
+type SigningContext struct {
+	keyAgg  *KeyAggContext
+	nonces  []*PublicNonce
+	message []byte
+	b       *crypto.Scalar
+	r       *crypto.Point
+	e       *crypto.Scalar
+}
+
+// secretNonce is shared by all the copies of a Session, so that erasing it erases it everywhere
+type secretNonce struct {
+	k1 *crypto.Scalar
+	k2 *crypto.Scalar
+}
+
+// Session is the state of one signer for one signature
+type Session struct {
+	keyAgg   *KeyAggContext
+	sk       *crypto.Scalar
+	index    int
+	secNonce *secretNonce
+	pubNonce *PublicNonce
+}
+
+// AggregateKeys computes the aggregated key of the signers, the order of pks is the signing order
+func AggregateKeys(pks []*crypto.Point) (*KeyAggContext, error) {
+	n := len(pks)
+	if n == 0 {
+		return nil, errors.New("AggregateKeys public keys must not be empty")
+	}
+	for i := 0; i < n; i++ {
+		if pks[i] == nil || !pks[i].PointValid() || pks[i].IsIdentity() {
+			return nil, errors.New("AggregateKeys invalid public key")
+		}
+		for j := 0; j < i; j++ {
+			if crypto.IsPointEqual(pks[i], pks[j]) {
+				return nil, errors.New("AggregateKeys duplicated public key")
+			}
+		}
+	}
+
+	list := crypto.AppendPointsToBytesArray([]byte(cStringKeyAggList), pks)
+	L := crypto.HashToScalar(list).ToBytes()
+
+	ctx := &KeyAggContext{
+		pks:    make([]*crypto.Point, n),
+		coeffs: make([]*crypto.Scalar, n),
+	}
+	for i := 0; i < n; i++ {
+		ctx.pks[i] = new(crypto.Point).Set(pks[i])
+		data := append([]byte(cStringKeyAggCoeff), L...)
+		ctx.coeffs[i] = crypto.HashToScalar(append(data, pks[i].ToBytes()...))
+	}
+	ctx.aggPk = new(crypto.Point).MultiScalarMult(ctx.coeffs, ctx.pks)
+	return ctx, nil
+}
+
+func (ctx KeyAggContext) AggregatedKey() *crypto.Point {
+	return new(crypto.Point).Set(ctx.aggPk)
+}
+
+func (ctx KeyAggContext) NumSigners() int {
+	return len(ctx.pks)
+}
+
+// indexOf returns the signing index of pk, -1 if pk is not a signer
+func (ctx KeyAggContext) indexOf(pk *crypto.Point) int {
+	for i := range ctx.pks {
+		if crypto.IsPointEqual(ctx.pks[i], pk) {
+			return i
+		}
+	}
+	return -1
+}
+
+// NewSession starts a signing session for the signer with the private key sk, drawing its nonces from crypto/rand
+func NewSession(keyAgg *KeyAggContext, sk *crypto.Scalar) (*Session, error) {
+	return NewSessionWithRand(keyAgg, sk, rand.Reader)
+}
+
+// NewSessionWithRand starts a signing session drawing the nonces from rng mixed with the private key.
+// rng must never repeat its output, a nonce used for two messages reveals the private key
+func NewSessionWithRand(keyAgg *KeyAggContext, sk *crypto.Scalar, rng io.Reader) (*Session, error) {
+	if keyAgg == nil || sk == nil || !sk.ScalarValid() {
+		return nil, errors.New("NewSession invalid input")
+	}
+	if rng == nil {
+		return nil, errors.New("NewSession needs a source of randomness")
+	}
+	pk := new(crypto.Point).ScalarMultBase(sk)
+	index := keyAgg.indexOf(pk)
+	if index < 0 {
+		return nil, errors.New("NewSession private key is not one of the signers")
+	}
+
+	nonces, err := crypto.NewNonceReader(sk.ToBytes(), rng, []byte(cStringNonce), keyAgg.aggPk.ToBytes())
+	if err != nil {
+		return nil, err
+	}
+	k, err := crypto.RandomScalarsFrom(nonces, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		keyAgg:   keyAgg,
+		sk:       new(crypto.Scalar).Set(sk),
+		index:    index,
+		secNonce: &secretNonce{k1: k[0], k2: k[1]},
+		pubNonce: &PublicNonce{
+			r1: new(crypto.Point).ScalarMultBase(k[0]),
+			r2: new(crypto.Point).ScalarMultBase(k[1]),
+		},
+	}, nil
+}
+
+// PublicNonce returns the round 1 message of the signer
+func (session Session) PublicNonce() *PublicNonce {
+	return &PublicNonce{r1: new(crypto.Point).Set(session.pubNonce.r1), r2: new(crypto.Point).Set(session.pubNonce.r2)}
+}
+
+func (session Session) Index() int {
+	return session.index
+}
+
+// Sign returns the round 2 message of the signer for the signing context.
+// It erases the secret nonce, any later call fails
+func (session *Session) Sign(ctx *SigningContext) (*PartialSignature, error) {
+	if ctx == nil || !crypto.IsPointEqual(ctx.keyAgg.aggPk, session.keyAgg.aggPk) {
+		return nil, errors.New("Sign signing context of another key")
+	}
+	if !session.pubNonce.equal(ctx.nonces[session.index]) {
+		return nil, errors.New("Sign signing context does not hold the nonce of this session")
+	}
+	sec := session.secNonce
+	if sec.k1 == nil || sec.k2 == nil {
+		return nil, errors.New("Sign nonce already used")
+	}
+	k1, k2 := sec.k1, sec.k2
+	sec.k1, sec.k2 = nil, nil
+
+	// s_i = k_i1 + b*k_i2 + e*a_i*x_i
+	s := new(crypto.Scalar).Mul(ctx.e, session.keyAgg.coeffs[session.index])
+	s.Mul(s, session.sk)
+	s.Add(s, new(crypto.Scalar).Mul(ctx.b, k2))
+	s.Add(s, k1)
+
+	k1.FromUint64(0)
+	k2.FromUint64(0)
+	return &PartialSignature{s: s}, nil
+}
+
+// NewSigningContext aggregates the public nonces of all the signers, in the signing order, for the message
+func NewSigningContext(keyAgg *KeyAggContext, nonces []*PublicNonce, message []byte) (*SigningContext, error) {
+	if keyAgg == nil || len(nonces) != len(keyAgg.pks) {
+		return nil, errors.New("NewSigningContext need one public nonce per signer")
+	}
+	r1 := new(crypto.Point).Identity()
+	r2 := new(crypto.Point).Identity()
+	for _, nonce := range nonces {
+		if nonce == nil || nonce.r1 == nil || nonce.r2 == nil {
+			return nil, errors.New("NewSigningContext public nonce is nil")
+		}
+		r1.Add(r1, nonce.r1)
+		r2.Add(r2, nonce.r2)
+	}
+
+	data := []byte(cStringNonceCoeff)
+	data = crypto.AppendPointsToBytesArray(data, []*crypto.Point{keyAgg.aggPk, r1, r2})
+	b := crypto.HashToScalar(append(data, message...))
+	r := new(crypto.Point).AddPedersen(new(crypto.Scalar).FromUint64(1), r1, b, r2)
+
+	return &SigningContext{
+		keyAgg:  keyAgg,
+		nonces:  nonces,
+		message: append([]byte{}, message...),
+		b:       b,
+		r:       r,
+		e:       schnorr.Challenge(r, keyAgg.aggPk, message),
+	}, nil
+}
+
+// VerifyPartial checks the partial signature of the signer at index
+func (ctx SigningContext) VerifyPartial(index int, psig *PartialSignature) bool {
+	if index < 0 || index >= len(ctx.nonces) || psig == nil || psig.s == nil || !psig.s.ScalarValid() {
+		return false
+	}
+	// R_i1 + b*R_i2 + (e*a_i)*P_i
+	ea := new(crypto.Scalar).Mul(ctx.e, ctx.keyAgg.coeffs[index])
+	expected := new(crypto.Point).AddPedersen(ctx.b, ctx.nonces[index].r2, ea, ctx.keyAgg.pks[index])
+	expected.Add(expected, ctx.nonces[index].r1)
+	return crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(psig.s), expected)
+}
+
+// Combine verifies the partial signatures of all the signers and sums them into the signature
+func (ctx SigningContext) Combine(psigs []*PartialSignature) (*schnorr.Signature, error) {
+	if len(psigs) != len(ctx.nonces) {
+		return nil, errors.New("Combine need one partial signature per signer")
+	}
+	s := new(crypto.Scalar).FromUint64(0)
+	for i, psig := range psigs {
+		if !ctx.VerifyPartial(i, psig) {
+			return nil, errors.New("Combine invalid partial signature")
+		}
+		s.Add(s, psig.s)
+	}
+	return schnorr.NewSignature(ctx.r, s), nil
+}
+
+func (nonce PublicNonce) equal(other *PublicNonce) bool {
+	return other != nil && other.r1 != nil && other.r2 != nil &&
+		crypto.IsPointEqual(nonce.r1, other.r1) && crypto.IsPointEqual(nonce.r2, other.r2)
+}
+
+func (nonce PublicNonce) Bytes() []byte {
+	if nonce.r1 == nil || nonce.r2 == nil {
+		return []byte{}
+	}
+	res := make([]byte, 0, PublicNonceSize)
+	res = append(res, nonce.r1.ToBytes()...)
+	res = append(res, nonce.r2.ToBytes()...)
+	return res
+}
+
+func (nonce *PublicNonce) SetBytes(bytes []byte) error {
+	if len(bytes) != PublicNonceSize {
+		return errors.New("invalid musig2 public nonce size")
+	}
+	r1, err := new(crypto.Point).FromBytes(bytes[:crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	r2, err := new(crypto.Point).FromBytes(bytes[crypto.Ed25519KeySize:])
+	if err != nil {
+		return err
+	}
+	nonce.r1 = r1
+	nonce.r2 = r2
+	return nil
+}
+
+func (psig PartialSignature) Bytes() []byte {
+	if psig.s == nil {
+		return []byte{}
+	}
+	return psig.s.ToBytes()
+}
+
+func (psig *PartialSignature) SetBytes(bytes []byte) error {
+	if len(bytes) != PartialSignatureSize {
+		return errors.New("invalid musig2 partial signature size")
+	}
+	s, err := new(crypto.Scalar).FromBytes(bytes)
+	if err != nil {
+		return err
+	}
+	psig.s = s
+	return nil
+}
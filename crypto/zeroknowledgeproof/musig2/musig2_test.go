@@ -0,0 +1,153 @@
+package musig2
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/schnorr"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSigners(n int) ([]*crypto.Scalar, *KeyAggContext) {
+	sks := make([]*crypto.Scalar, n)
+	pks := make([]*crypto.Point, n)
+	for i := range sks {
+		sks[i] = crypto.RandomScalar()
+		pks[i] = new(crypto.Point).ScalarMultBase(sks[i])
+	}
+	keyAgg, _ := AggregateKeys(pks)
+	return sks, keyAgg
+}
+
+// runRounds runs both rounds, passing every message through its bytes
+func runRounds(t *testing.T, sks []*crypto.Scalar, keyAgg *KeyAggContext, message []byte) (*SigningContext, []*PartialSignature) {
+	n := len(sks)
+	sessions := make([]*Session, n)
+	nonces := make([]*PublicNonce, n)
+	for i := range sks {
+		var err error
+		sessions[i], err = NewSession(keyAgg, sks[i])
+		assert.Equal(t, nil, err)
+		assert.Equal(t, i, sessions[i].Index())
+
+		nonces[i] = new(PublicNonce)
+		assert.Equal(t, nil, nonces[i].SetBytes(sessions[i].PublicNonce().Bytes()))
+	}
+
+	ctx, err := NewSigningContext(keyAgg, nonces, message)
+	assert.Equal(t, nil, err)
+
+	psigs := make([]*PartialSignature, n)
+	for i := range sessions {
+		psig, err := sessions[i].Sign(ctx)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, PartialSignatureSize, len(psig.Bytes()))
+
+		psigs[i] = new(PartialSignature)
+		assert.Equal(t, nil, psigs[i].SetBytes(psig.Bytes()))
+		assert.Equal(t, true, ctx.VerifyPartial(i, psigs[i]))
+	}
+	return ctx, psigs
+}
+
+func TestMuSig2_SignVerify(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 7} {
+		sks, keyAgg := newSigners(n)
+		message := []byte("shared spend")
+
+		ctx, psigs := runRounds(t, sks, keyAgg, message)
+		sig, err := ctx.Combine(psigs)
+		assert.Equal(t, nil, err)
+
+		// the signature is an ordinary schnorr signature under the aggregated key
+		assert.Equal(t, true, schnorr.Verify(keyAgg.AggregatedKey(), message, sig))
+		assert.Equal(t, false, schnorr.Verify(keyAgg.AggregatedKey(), []byte("other"), sig))
+	}
+}
+
+func TestMuSig2_KeyAggregation(t *testing.T) {
+	_, keyAgg := newSigners(3)
+	pks := keyAgg.pks
+
+	// the aggregated key depends on the order of the keys
+	keyAgg2, err := AggregateKeys([]*crypto.Point{pks[1], pks[0], pks[2]})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, crypto.IsPointEqual(keyAgg.AggregatedKey(), keyAgg2.AggregatedKey()))
+
+	// the aggregated key is not the plain sum of the keys
+	sum := new(crypto.Point).Add(pks[0], pks[1])
+	sum.Add(sum, pks[2])
+	assert.Equal(t, false, crypto.IsPointEqual(keyAgg.AggregatedKey(), sum))
+
+	_, err = AggregateKeys([]*crypto.Point{})
+	assert.NotEqual(t, nil, err)
+	_, err = AggregateKeys([]*crypto.Point{pks[0], pks[0]})
+	assert.NotEqual(t, nil, err)
+	_, err = AggregateKeys([]*crypto.Point{pks[0], new(crypto.Point).Identity()})
+	assert.NotEqual(t, nil, err)
+
+	// a private key of another key cannot join the session
+	_, err = NewSession(keyAgg, crypto.RandomScalar())
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMuSig2_NonceReuse(t *testing.T) {
+	sks, keyAgg := newSigners(2)
+	sessions := make([]*Session, 2)
+	nonces := make([]*PublicNonce, 2)
+	for i := range sks {
+		sessions[i], _ = NewSession(keyAgg, sks[i])
+		nonces[i] = sessions[i].PublicNonce()
+	}
+	ctx1, _ := NewSigningContext(keyAgg, nonces, []byte("message 1"))
+	ctx2, _ := NewSigningContext(keyAgg, nonces, []byte("message 2"))
+
+	_, err := sessions[0].Sign(ctx1)
+	assert.Equal(t, nil, err)
+
+	// the same session cannot sign again, even the same context
+	_, err = sessions[0].Sign(ctx2)
+	assert.NotEqual(t, nil, err)
+	_, err = sessions[0].Sign(ctx1)
+	assert.NotEqual(t, nil, err)
+
+	// a copy of the session shares the erased nonce
+	copied := *sessions[1]
+	_, err = sessions[1].Sign(ctx1)
+	assert.Equal(t, nil, err)
+	_, err = copied.Sign(ctx2)
+	assert.NotEqual(t, nil, err)
+
+	// a context which does not hold the nonce of the session is rejected
+	session, _ := NewSession(keyAgg, sks[0])
+	_, err = session.Sign(ctx1)
+	assert.NotEqual(t, nil, err)
+	_, err = session.Sign(nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMuSig2_InvalidPartial(t *testing.T) {
+	sks, keyAgg := newSigners(3)
+	ctx, psigs := runRounds(t, sks, keyAgg, []byte("message"))
+
+	// swapped partial signatures
+	assert.Equal(t, false, ctx.VerifyPartial(0, psigs[1]))
+	_, err := ctx.Combine([]*PartialSignature{psigs[1], psigs[0], psigs[2]})
+	assert.NotEqual(t, nil, err)
+
+	// tampered partial signature
+	bad := &PartialSignature{s: new(crypto.Scalar).Add(psigs[2].s, new(crypto.Scalar).FromUint64(1))}
+	assert.Equal(t, false, ctx.VerifyPartial(2, bad))
+	_, err = ctx.Combine([]*PartialSignature{psigs[0], psigs[1], bad})
+	assert.NotEqual(t, nil, err)
+
+	// missing partial signature
+	_, err = ctx.Combine(psigs[:2])
+	assert.NotEqual(t, nil, err)
+	assert.Equal(t, false, ctx.VerifyPartial(3, psigs[0]))
+	assert.Equal(t, false, ctx.VerifyPartial(0, nil))
+
+	// bad bytes
+	assert.NotEqual(t, nil, new(PublicNonce).SetBytes(make([]byte, PublicNonceSize-1)))
+	assert.NotEqual(t, nil, new(PartialSignature).SetBytes(make([]byte, PartialSignatureSize+1)))
+}
@@ -0,0 +1,362 @@
+package oneoutofmany
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+/* One-out-of-many proof convinces the verifier that one of the commitments C_0..C_{N-1}
+commits to the same value as the commitment C, without revealing which one.
+The prover knows the index l and the blind r such that C_l - C = r*H.
+Commitments are Pedersen commitments v*G + r*H as made by AddPedersenBase.
+
+The proof has 4m points and 3m+1 scalars with m = log2(N), N is padded to a power of two
+by repeating the last commitment.
+
+See reference: https://eprint.iacr.org/2014/764.pdf (Chapter 3, Figure 2)
+*/
+
+const cStringOneOutOfMany = "oneoutofmany"
+
+// max m, the proof supports up to 2^maxExp commitments
+const maxExp = 16
+
+type OneOutOfManyWitness struct {
+	commitments []*crypto.Point
+	commitment  *crypto.Point
+	index       int
+	rand        *crypto.Scalar
+}
+
+type OneOutOfManyProof struct {
+	cl []*crypto.Point
+	ca []*crypto.Point
+	cb []*crypto.Point
+	cd []*crypto.Point
+
+	f  []*crypto.Scalar
+	za []*crypto.Scalar
+	zb []*crypto.Scalar
+	zd *crypto.Scalar
+}
+
+// Set sets the witness that commitments[index] - commitment = rand * H.
+// commitment can be the identity to prove that one of the commitments is a commitment to zero
+func (wit *OneOutOfManyWitness) Set(commitments []*crypto.Point, commitment *crypto.Point, index int, rand *crypto.Scalar) {
+	wit.commitments = commitments
+	wit.commitment = new(crypto.Point).Set(commitment)
+	wit.index = index
+	wit.rand = new(crypto.Scalar).Set(rand)
+}
+
+// getExp returns m such that 2^m is the smallest power of two which is at least n, with m >= 1
+func getExp(n int) int {
+	m := 1
+	for 1<<uint(m) < n {
+		m++
+	}
+	return m
+}
+
+func generateChallenge(commitments []*crypto.Point, commitment *crypto.Point, proof *OneOutOfManyProof) *crypto.Scalar {
+	bytes := []byte(cStringOneOutOfMany)
+	bytes = crypto.AppendPointsToBytesArray(bytes, commitments)
+	bytes = append(bytes, commitment.ToBytes()...)
+	bytes = crypto.AppendPointsToBytesArray(bytes, proof.cl)
+	bytes = crypto.AppendPointsToBytesArray(bytes, proof.ca)
+	bytes = crypto.AppendPointsToBytesArray(bytes, proof.cb)
+	bytes = crypto.AppendPointsToBytesArray(bytes, proof.cd)
+	return crypto.HashToScalar(bytes)
+}
+
+// foldPadding adds the coefficients of the padded commitments to the last commitment
+func foldPadding(coeffs []*crypto.Scalar, n int) []*crypto.Scalar {
+	res := coeffs[:n]
+	for i := n; i < len(coeffs); i++ {
+		res[n-1].Add(res[n-1], coeffs[i])
+	}
+	return res
+}
+
+func (wit OneOutOfManyWitness) Prove() (*OneOutOfManyProof, error) {
+	return wit.ProveWithRand(rand.Reader)
+}
+
+// ProveWithRand creates the proof, reading its random scalars from rng
+func (wit OneOutOfManyWitness) ProveWithRand(rng io.Reader) (*OneOutOfManyProof, error) {
+	N := len(wit.commitments)
+	if N == 0 || N > 1<<maxExp {
+		return nil, errors.New("invalid number of commitments of one out of many protocol")
+	}
+	if wit.index < 0 || wit.index >= N || wit.commitment == nil || wit.rand == nil {
+		return nil, errors.New("invalid witness of one out of many protocol")
+	}
+
+	m := getExp(N)
+	n := 1 << uint(m)
+	zero := new(crypto.Scalar).FromUint64(0)
+	one := new(crypto.Scalar).FromUint64(1)
+
+	randoms, err := crypto.RandomScalarsFrom(rng, 5*m)
+	if err != nil {
+		return nil, err
+	}
+	r, a, s, t, rho := randoms[:m], randoms[m:2*m], randoms[2*m:3*m], randoms[3*m:4*m], randoms[4*m:]
+
+	proof := &OneOutOfManyProof{
+		cl: make([]*crypto.Point, m),
+		ca: make([]*crypto.Point, m),
+		cb: make([]*crypto.Point, m),
+		cd: make([]*crypto.Point, m),
+		f:  make([]*crypto.Scalar, m),
+		za: make([]*crypto.Scalar, m),
+		zb: make([]*crypto.Scalar, m),
+	}
+
+	// bits of the index, commitments to the bits and to the blinding values a_j
+	bits := make([]*crypto.Scalar, m)
+	for j := 0; j < m; j++ {
+		bits[j] = new(crypto.Scalar).FromUint64(uint64((wit.index >> uint(j)) & 1))
+		proof.cl[j] = new(crypto.Point).AddPedersenBase(bits[j], r[j])
+		proof.ca[j] = new(crypto.Point).AddPedersenBase(a[j], s[j])
+		proof.cb[j] = new(crypto.Point).AddPedersenBase(new(crypto.Scalar).Mul(bits[j], a[j]), t[j])
+	}
+
+	// p_i(x) = prod_j f_{j, i_j}(x) with f_{j,1}(x) = l_j*x + a_j and f_{j,0}(x) = x - f_{j,1}(x)
+	// coefficients are stored from the lowest degree, the degree m coefficient is 1 only for i = l
+	polys := [][]*crypto.Scalar{{one}}
+	for j := 0; j < m; j++ {
+		f1 := []*crypto.Scalar{a[j], bits[j]}
+		f0 := []*crypto.Scalar{new(crypto.Scalar).Sub(zero, a[j]), new(crypto.Scalar).Sub(one, bits[j])}
+		next := make([][]*crypto.Scalar, 2*len(polys))
+		for i := range polys {
+			next[i] = mulLinear(polys[i], f0)
+			next[i+len(polys)] = mulLinear(polys[i], f1)
+		}
+		polys = next
+	}
+
+	// cd_k = sum_i p_{i,k} * (C_i - C) + rho_k * H
+	for k := 0; k < m; k++ {
+		coeffs := make([]*crypto.Scalar, n)
+		sum := new(crypto.Scalar).FromUint64(0)
+		for i := 0; i < n; i++ {
+			coeffs[i] = new(crypto.Scalar).Set(polys[i][k])
+			sum.Add(sum, polys[i][k])
+		}
+		scalars := append(foldPadding(coeffs, N), new(crypto.Scalar).Sub(zero, sum), rho[k])
+		points := append(append([]*crypto.Point{}, wit.commitments...), wit.commitment, crypto.H)
+		proof.cd[k] = new(crypto.Point).MultiScalarMult(scalars, points)
+	}
+
+	x := generateChallenge(wit.commitments, wit.commitment, proof)
+
+	xPow := new(crypto.Scalar).FromUint64(1)
+	proof.zd = new(crypto.Scalar).FromUint64(0)
+	for j := 0; j < m; j++ {
+		// f_j = l_j*x + a_j, za_j = r_j*x + s_j, zb_j = r_j*(x - f_j) + t_j
+		proof.f[j] = new(crypto.Scalar).Mul(bits[j], x)
+		proof.f[j].Add(proof.f[j], a[j])
+		proof.za[j] = new(crypto.Scalar).Mul(r[j], x)
+		proof.za[j].Add(proof.za[j], s[j])
+		proof.zb[j] = new(crypto.Scalar).Mul(r[j], new(crypto.Scalar).Sub(x, proof.f[j]))
+		proof.zb[j].Add(proof.zb[j], t[j])
+
+		// zd = r*x^m - sum_k rho_k*x^k
+		proof.zd.Sub(proof.zd, new(crypto.Scalar).Mul(rho[j], xPow))
+		xPow.Mul(xPow, x)
+	}
+	proof.zd.Add(proof.zd, new(crypto.Scalar).Mul(wit.rand, xPow))
+
+	return proof, nil
+}
+
+// mulLinear returns the product of the polynomial p with the linear polynomial f
+func mulLinear(p []*crypto.Scalar, f []*crypto.Scalar) []*crypto.Scalar {
+	res := make([]*crypto.Scalar, len(p)+1)
+	for i := range res {
+		res[i] = new(crypto.Scalar).FromUint64(0)
+	}
+	for i := range p {
+		res[i].Add(res[i], new(crypto.Scalar).Mul(p[i], f[0]))
+		res[i+1].Add(res[i+1], new(crypto.Scalar).Mul(p[i], f[1]))
+	}
+	return res
+}
+
+func (proof OneOutOfManyProof) ValidateSanity() bool {
+	m := len(proof.cl)
+	if m == 0 || m > maxExp {
+		return false
+	}
+	if len(proof.ca) != m || len(proof.cb) != m || len(proof.cd) != m ||
+		len(proof.f) != m || len(proof.za) != m || len(proof.zb) != m {
+		return false
+	}
+	for _, points := range [][]*crypto.Point{proof.cl, proof.ca, proof.cb, proof.cd} {
+		for _, p := range points {
+			if p == nil || !p.PointValid() {
+				return false
+			}
+		}
+	}
+	for _, scalars := range [][]*crypto.Scalar{proof.f, proof.za, proof.zb} {
+		for _, s := range scalars {
+			if s == nil || !s.ScalarValid() {
+				return false
+			}
+		}
+	}
+	return proof.zd != nil && proof.zd.ScalarValid()
+}
+
+// Verify checks that one of the commitments commits to the same value as commitment.
+// All the equations are checked by a single multi scalar mult, combined with random weights:
+//
+//	x*cl_j + ca_j == f_j*G + za_j*H
+//	(x - f_j)*cl_j + cb_j == zb_j*H
+//	sum_i p_i(x)*(C_i - C) - sum_k x^k*cd_k == zd*H
+func (proof OneOutOfManyProof) Verify(commitments []*crypto.Point, commitment *crypto.Point) (bool, error) {
+	N := len(commitments)
+	if N == 0 || N > 1<<maxExp || commitment == nil || !commitment.PointValid() {
+		return false, errors.New("invalid statement of one out of many protocol")
+	}
+	for _, c := range commitments {
+		if c == nil || !c.PointValid() {
+			return false, errors.New("invalid commitment in the statement of one out of many protocol")
+		}
+	}
+	if !proof.ValidateSanity() {
+		return false, errors.New("invalid one out of many proof")
+	}
+	m := len(proof.cl)
+	if m != getExp(N) {
+		return false, errors.New("one out of many proof does not match the number of commitments")
+	}
+	n := 1 << uint(m)
+	zero := new(crypto.Scalar).FromUint64(0)
+
+	x := generateChallenge(commitments, commitment, &proof)
+
+	// p_i(x) = prod_j f_{j, i_j} with f_{j,1} = f_j and f_{j,0} = x - f_j
+	coeffs := []*crypto.Scalar{new(crypto.Scalar).FromUint64(1)}
+	for j := 0; j < m; j++ {
+		f0 := new(crypto.Scalar).Sub(x, proof.f[j])
+		next := make([]*crypto.Scalar, 2*len(coeffs))
+		for i := range coeffs {
+			next[i] = new(crypto.Scalar).Mul(coeffs[i], f0)
+			next[i+len(coeffs)] = new(crypto.Scalar).Mul(coeffs[i], proof.f[j])
+		}
+		coeffs = next
+	}
+	sum := new(crypto.Scalar).FromUint64(0)
+	for i := 0; i < n; i++ {
+		sum.Add(sum, coeffs[i])
+	}
+
+	scalars := make([]*crypto.Scalar, 0, N+4*m+3)
+	points := make([]*crypto.Point, 0, N+4*m+3)
+	scalars = append(scalars, foldPadding(coeffs, N)...)
+	points = append(points, commitments...)
+	scalars = append(scalars, new(crypto.Scalar).Sub(zero, sum))
+	points = append(points, commitment)
+
+	gScalar := new(crypto.Scalar).FromUint64(0)
+	hScalar := new(crypto.Scalar).Sub(zero, proof.zd)
+
+	xPow := new(crypto.Scalar).FromUint64(1)
+	for j := 0; j < m; j++ {
+		scalars = append(scalars, new(crypto.Scalar).Sub(zero, xPow))
+		points = append(points, proof.cd[j])
+		xPow.Mul(xPow, x)
+
+		// random weights w, v of the bit equations
+		w := randomWeight()
+		v := randomWeight()
+
+		clScalar := new(crypto.Scalar).Mul(w, x)
+		clScalar.Add(clScalar, new(crypto.Scalar).Mul(v, new(crypto.Scalar).Sub(x, proof.f[j])))
+		scalars = append(scalars, clScalar, w, v)
+		points = append(points, proof.cl[j], proof.ca[j], proof.cb[j])
+
+		gScalar.Sub(gScalar, new(crypto.Scalar).Mul(w, proof.f[j]))
+		hScalar.Sub(hScalar, new(crypto.Scalar).Mul(w, proof.za[j]))
+		hScalar.Sub(hScalar, new(crypto.Scalar).Mul(v, proof.zb[j]))
+	}
+	scalars = append(scalars, gScalar, hScalar)
+	points = append(points, crypto.G, crypto.H)
+
+	res, err := new(crypto.Point).CheckedMultiScalarMult(scalars, points)
+	if err != nil {
+		return false, err
+	}
+	if !res.IsIdentity() {
+		return false, errors.New("verify one out of many proof failed")
+	}
+	return true, nil
+}
+
+// randomWeight returns a random 128-bit scalar
+func randomWeight() *crypto.Scalar {
+	weight := make([]byte, crypto.Ed25519KeySize)
+	copy(weight, crypto.RandBytes(16))
+	res, _ := new(crypto.Scalar).FromBytes(weight)
+	return res
+}
+
+// Bytes encodes the proof as m || cl || ca || cb || cd || f || za || zb || zd
+func (proof OneOutOfManyProof) Bytes() []byte {
+	if !proof.ValidateSanity() {
+		return []byte{}
+	}
+	m := len(proof.cl)
+	res := make([]byte, 0, 1+(7*m+1)*crypto.Ed25519KeySize)
+	res = append(res, byte(m))
+	for _, points := range [][]*crypto.Point{proof.cl, proof.ca, proof.cb, proof.cd} {
+		res = crypto.AppendPointsToBytesArray(res, points)
+	}
+	for _, scalars := range [][]*crypto.Scalar{proof.f, proof.za, proof.zb} {
+		for _, s := range scalars {
+			res = append(res, s.ToBytes()...)
+		}
+	}
+	res = append(res, proof.zd.ToBytes()...)
+	return res
+}
+
+func (proof *OneOutOfManyProof) SetBytes(bytes []byte) error {
+	if len(bytes) == 0 {
+		return errors.New("one out of many proof bytes is empty")
+	}
+	m := int(bytes[0])
+	if m == 0 || m > maxExp || len(bytes) != 1+(7*m+1)*crypto.Ed25519KeySize {
+		return errors.New("invalid one out of many proof size")
+	}
+
+	offset := 1
+	var err error
+	points := make([]*crypto.Point, 4*m)
+	for i := range points {
+		points[i], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+		offset += crypto.Ed25519KeySize
+	}
+	scalars := make([]*crypto.Scalar, 3*m+1)
+	for i := range scalars {
+		scalars[i], err = new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+		offset += crypto.Ed25519KeySize
+	}
+
+	proof.cl, proof.ca, proof.cb, proof.cd = points[:m], points[m:2*m], points[2*m:3*m], points[3*m:]
+	proof.f, proof.za, proof.zb = scalars[:m], scalars[m:2*m], scalars[2*m:3*m]
+	proof.zd = scalars[3*m]
+	return nil
+}
@@ -0,0 +1,174 @@
+package oneoutofmany
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// randomCommitments returns n random commitments where commitments[index] commits to value with the blind
+func randomCommitments(n int, index int, value uint64, blind *crypto.Scalar) []*crypto.Point {
+	commitments := make([]*crypto.Point, n)
+	for i := range commitments {
+		commitments[i] = crypto.Commit(rand.Uint64(), crypto.RandomScalar()).Point()
+	}
+	commitments[index] = crypto.Commit(value, blind).Point()
+	return commitments
+}
+
+func TestOneOutOfMany_CommitToZero(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 5, 8, 13, 32, 100} {
+		index := rand.Intn(n)
+		blind := crypto.RandomScalar()
+		commitments := randomCommitments(n, index, 0, blind)
+		identity := new(crypto.Point).Identity()
+
+		wit := new(OneOutOfManyWitness)
+		wit.Set(commitments, identity, index, blind)
+		proof, err := wit.Prove()
+		assert.Equal(t, nil, err)
+
+		res, err := proof.Verify(commitments, identity)
+		assert.Equal(t, true, res)
+		assert.Equal(t, nil, err)
+
+		// convert proof to bytes array
+		bytes := proof.Bytes()
+		m := getExp(n)
+		assert.Equal(t, 1+(7*m+1)*crypto.Ed25519KeySize, len(bytes))
+
+		proof2 := new(OneOutOfManyProof)
+		assert.Equal(t, nil, proof2.SetBytes(bytes))
+		res, err = proof2.Verify(commitments, identity)
+		assert.Equal(t, true, res)
+		assert.Equal(t, nil, err)
+	}
+}
+
+func TestOneOutOfMany_Membership(t *testing.T) {
+	// C commits to the same value as commitments[index] with another blind
+	n := 20
+	index := 7
+	value := rand.Uint64()
+	blind := crypto.RandomScalar()
+	commitments := randomCommitments(n, index, value, blind)
+
+	newBlind := crypto.RandomScalar()
+	commitment := crypto.Commit(value, newBlind).Point()
+
+	wit := new(OneOutOfManyWitness)
+	wit.Set(commitments, commitment, index, new(crypto.Scalar).Sub(blind, newBlind))
+	proof, err := wit.Prove()
+	assert.Equal(t, nil, err)
+
+	res, err := proof.Verify(commitments, commitment)
+	assert.Equal(t, true, res)
+	assert.Equal(t, nil, err)
+
+	// a commitment to another value is rejected
+	other := crypto.Commit(value+1, newBlind).Point()
+	res, err = proof.Verify(commitments, other)
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+
+	// a different set of commitments is rejected
+	commitments2 := append([]*crypto.Point{}, commitments...)
+	commitments2[0] = crypto.RandomPoint()
+	res, _ = proof.Verify(commitments2, commitment)
+	assert.Equal(t, false, res)
+
+	// the set must have the size the proof was made for
+	res, err = proof.Verify(commitments[:8], commitment)
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestOneOutOfMany_Invalid(t *testing.T) {
+	n := 10
+	blind := crypto.RandomScalar()
+	commitments := randomCommitments(n, 3, 0, blind)
+	identity := new(crypto.Point).Identity()
+
+	// wrong index
+	wit := new(OneOutOfManyWitness)
+	wit.Set(commitments, identity, 4, blind)
+	proof, err := wit.Prove()
+	assert.Equal(t, nil, err)
+	res, _ := proof.Verify(commitments, identity)
+	assert.Equal(t, false, res)
+
+	// index out of range
+	wit.Set(commitments, identity, n, blind)
+	_, err = wit.Prove()
+	assert.NotEqual(t, nil, err)
+
+	// tampered proofs
+	wit.Set(commitments, identity, 3, blind)
+	proof, err = wit.Prove()
+	assert.Equal(t, nil, err)
+	bytes := proof.Bytes()
+	tampers := []func(p *OneOutOfManyProof){
+		func(p *OneOutOfManyProof) { p.cl[1] = crypto.RandomPoint() },
+		func(p *OneOutOfManyProof) { p.cb[0] = crypto.RandomPoint() },
+		func(p *OneOutOfManyProof) { p.cd[2] = crypto.RandomPoint() },
+		func(p *OneOutOfManyProof) { p.f[0] = crypto.RandomScalar() },
+		func(p *OneOutOfManyProof) { p.za[3] = crypto.RandomScalar() },
+		func(p *OneOutOfManyProof) { p.zd = crypto.RandomScalar() },
+	}
+	for _, tamper := range tampers {
+		proof2 := new(OneOutOfManyProof)
+		assert.Equal(t, nil, proof2.SetBytes(bytes))
+		tamper(proof2)
+		res, _ = proof2.Verify(commitments, identity)
+		assert.Equal(t, false, res)
+	}
+
+	// nil and invalid commitments in the statement are rejected
+	proof, _ = wit.Prove()
+	invalid, _ := new(crypto.Point).UnmarshalText([]byte("02" + strings.Repeat("00", 31)))
+	assert.Equal(t, false, invalid.PointValid())
+	for _, c := range []*crypto.Point{nil, invalid} {
+		statement := append([]*crypto.Point{}, commitments...)
+		statement[1] = c
+		res, err = proof.Verify(statement, identity)
+		assert.Equal(t, false, res)
+		assert.NotEqual(t, nil, err)
+	}
+	res, err = proof.Verify(commitments, invalid)
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+
+	// bad bytes
+	proof2 := new(OneOutOfManyProof)
+	assert.NotEqual(t, nil, proof2.SetBytes([]byte{}))
+	assert.NotEqual(t, nil, proof2.SetBytes(bytes[:len(bytes)-1]))
+	bad := append([]byte{}, bytes...)
+	bad[0] = 0
+	assert.NotEqual(t, nil, proof2.SetBytes(bad))
+}
+
+func TestOneOutOfMany_Deterministic(t *testing.T) {
+	n := 6
+	blind := crypto.RandomScalar()
+	commitments := randomCommitments(n, 2, 0, blind)
+	identity := new(crypto.Point).Identity()
+
+	wit := new(OneOutOfManyWitness)
+	wit.Set(commitments, identity, 2, blind)
+
+	seed := []byte("one out of many seed")
+	rng1, _ := crypto.NewNonceReader(seed, nil)
+	rng2, _ := crypto.NewNonceReader(seed, nil)
+	proof1, err := wit.ProveWithRand(rng1)
+	assert.Equal(t, nil, err)
+	proof2, err := wit.ProveWithRand(rng2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, proof1.Bytes(), proof2.Bytes())
+
+	res, err := proof1.Verify(commitments, identity)
+	assert.Equal(t, true, res)
+	assert.Equal(t, nil, err)
+}
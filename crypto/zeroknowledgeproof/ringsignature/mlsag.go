@@ -1,9 +1,11 @@
 package ringsignature
 
 import (
+	"crypto/rand"
 	"errors"
 	"fmt"
 	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"io"
 )
 
 // Multilayer Linkable Spontaneous Anonymous Group (mlsag)
@@ -11,6 +13,9 @@ import (
 
 const RingSize = 8
 
+// MaxColumns is the max number of columns, m and dsCols are encoded in one byte
+const MaxColumns = 255
+
 type Mlsag_Witness struct {
 	privateKey []*crypto.Scalar
 	index      int
@@ -36,7 +41,79 @@ func key_image(private *crypto.Scalar, public *crypto.Point) *crypto.Point {
 	return res
 }
 
+// mlsag_hash returns H(message || P_0 || L_0 || R_0 || ... || P_m-1 || L_m-1) of one row,
+// the first len(R) columns are checked for double spending and also hash R
+func mlsag_hash(messageBytes []byte, publicKey []*crypto.Point, L []*crypto.Point, R []*crypto.Point) *crypto.Scalar {
+	toHashBytes := append([]byte{}, messageBytes...)
+	for j := range publicKey {
+		toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{publicKey[j], L[j]})
+		if j < len(R) {
+			toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{R[j]})
+		}
+	}
+	return crypto.HashToScalar(toHashBytes)
+}
+
+// mlsag_ring walks the ring from the row after index, whose challenge is cNext, up to index.
+// It returns the challenges c0 of row 0 and c of row index
+func mlsag_ring(publicKey [][]*crypto.Point, keyImage []*crypto.Point, messageBytes []byte, r [][]*crypto.Scalar, index int, cNext *crypto.Scalar) (*crypto.Scalar, *crypto.Scalar) {
+	n := len(publicKey)
+	c := new(crypto.Scalar).Set(cNext)
+	c0 := new(crypto.Scalar)
+	for i := (index + 1) % n; ; i = (i + 1) % n {
+		if i == 0 {
+			c0.Set(c)
+		}
+		if i == index {
+			return c0, c
+		}
+		L, R := mlsag_row(publicKey[i], keyImage, r[i], c)
+		c = mlsag_hash(messageBytes, publicKey[i], L, R)
+	}
+}
+
+// mlsag_row returns L_j = r_j*G + c*P_j for all columns and R_j = r_j*Hp(P_j) + c*I_j for the double spending columns
+func mlsag_row(publicKey []*crypto.Point, keyImage []*crypto.Point, r []*crypto.Scalar, c *crypto.Scalar) ([]*crypto.Point, []*crypto.Point) {
+	L := make([]*crypto.Point, len(publicKey))
+	R := make([]*crypto.Point, len(keyImage))
+	for j := range publicKey {
+		L[j] = new(crypto.Point).DoubleScalarMultBaseVartime(r[j], publicKey[j], c)
+		if j < len(keyImage) {
+			Hi := crypto.HashToPoint(publicKey[j].ToBytes())
+			R[j] = new(crypto.Point).AddPedersen(r[j], Hi, c, keyImage[j])
+		}
+	}
+	return L, R
+}
+
+// NonceReader returns a reader of nonces derived from the private keys, the ring,
+// the message and entropy, RFC 6979 style, to be given to Mlsag_ProveWithRand.
+// With a nil entropy the signature is fully deterministic, which is meant for test vectors
+func (wit Mlsag_Witness) NonceReader(entropy io.Reader) (io.Reader, error) {
+	secret := make([]byte, 0, len(wit.privateKey)*crypto.Ed25519KeySize)
+	for _, privateKey := range wit.privateKey {
+		secret = append(secret, privateKey.ToBytes()...)
+	}
+	public := []byte{byte(wit.index), byte(wit.dsCols)}
+	for i := range wit.publicKey {
+		public = crypto.AppendPointsToBytesArray(public, wit.publicKey[i])
+	}
+	public = append(public, wit.message.ToBytes()...)
+	return crypto.NewNonceReader(secret, entropy, []byte("mlsag"), public)
+}
+
 func (wit Mlsag_Witness) Mlsag_Prove() (*Mlsag_Proof, error) {
+	return wit.Mlsag_ProveWithRand(rand.Reader)
+}
+
+// Mlsag_ProveWithRand signs the message, reading the random scalars from rng
+func (wit Mlsag_Witness) Mlsag_ProveWithRand(rng io.Reader) (*Mlsag_Proof, error) {
+	return wit.mlsag_prove(rng, nil, nil)
+}
+
+// mlsag_prove signs the message, if the adaptor points T, THp are not nil they are added to
+// alpha*G and alpha*Hp(P) of the first column, which makes a pre-signature
+func (wit Mlsag_Witness) mlsag_prove(rng io.Reader, T *crypto.Point, THp *crypto.Point) (*Mlsag_Proof, error) {
 	//startProve := time.Now()
 	n := RingSize            // number of rows, Ring Size
 	m := len(wit.privateKey) // number of columns, number of private keys
@@ -48,6 +125,9 @@ func (wit Mlsag_Witness) Mlsag_Prove() (*Mlsag_Proof, error) {
 	if m < 2 {
 		return nil, errors.New("Mlsag_Prove length of private list must be at least 2")
 	}
+	if m > MaxColumns {
+		return nil, errors.New("Mlsag_Prove length of private list must be at most MaxColumns")
+	}
 	if index >= n {
 		return nil, errors.New("Mlsag_Prove Index out of range")
 	}
@@ -64,79 +144,47 @@ func (wit Mlsag_Witness) Mlsag_Prove() (*Mlsag_Proof, error) {
 	}
 
 	// Step 1: Calculate key images for dsCols private keys
-	Hi := new(crypto.Point)
+	var err error
 	keyImage := make([]*crypto.Point, dsCols)
 	alpha := make([]*crypto.Scalar, m)
-	aG := new(crypto.Point)
-	aHP := new(crypto.Point)
-
-	toHashBytes := make([]byte, 0)
-	toHashBytes = messageBytes
-
-	for j := 0; j < dsCols; j++ {
-		alpha[j] = crypto.RandomScalar()
-		aG = new(crypto.Point).ScalarMultBase(alpha[j])
-
-		Hi = crypto.HashToPoint(wit.publicKey[index][j].ToBytes())
-		aHP = new(crypto.Point).ScalarMult(Hi, alpha[j])
-
-		toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{wit.publicKey[index][j], aG, aHP})
-
-		// Calculate key images for private key j
-		keyImage[j] = key_image(wit.privateKey[j], Hi)
-	}
+	aG := make([]*crypto.Point, m)
+	aHP := make([]*crypto.Point, dsCols)
 
-	for j, j2 := dsCols, 0; j < m; j, j2 = j+1, j2+1 {
-		alpha[j] = crypto.RandomScalar()
-		aG = new(crypto.Point).ScalarMultBase(alpha[j])
+	for j := 0; j < m; j++ {
+		alpha[j], err = crypto.RandomScalarFrom(rng)
+		if err != nil {
+			return nil, err
+		}
+		aG[j] = new(crypto.Point).ScalarMultBase(alpha[j])
+		if j < dsCols {
+			Hi := crypto.HashToPoint(wit.publicKey[index][j].ToBytes())
+			aHP[j] = new(crypto.Point).ScalarMult(Hi, alpha[j])
 
-		toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{wit.publicKey[index][j], aG})
+			// Calculate key images for private key j
+			keyImage[j] = key_image(wit.privateKey[j], wit.publicKey[index][j])
+		}
 	}
 
-	c_old := crypto.HashToScalar(toHashBytes)
-	c0 := new(crypto.Scalar)
-	c := new(crypto.Scalar)
-	L := new(crypto.Point)
-	R := new(crypto.Point)
+	// Step 2: Random r of the other rows, then close the ring at index
 	r := make([][]*crypto.Scalar, n)
 	for i := 0; i < n; i++ {
-		r[i] = make([]*crypto.Scalar, m)
-	}
-
-	i := (index + 1) % n
-	if i == 0 {
-		c0 = c_old
-	}
-
-	for i != index {
-		for j := 0; j < m; j++ {
-			r[i][j] = crypto.RandomScalar()
-		}
-
-		toHashBytes = messageBytes
-
-		for j := 0; j < dsCols; j++ {
-			L = new(crypto.Point).AddPedersen(r[i][j], crypto.G, c_old, wit.publicKey[i][j])
-			Hi = crypto.HashToPoint(wit.publicKey[i][j].ToBytes())
-			R = new(crypto.Point).AddPedersen(r[i][j], Hi, c_old, keyImage[j])
-
-			toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{wit.publicKey[index][j], L, R})
+		if i == index {
+			continue
 		}
-
-		for j, j2 := dsCols, 0; j < m; j, j2 = j+1, j2+1 {
-			L = new(crypto.Point).AddPedersen(r[i][j], crypto.G, c_old, wit.publicKey[i][j])
-			toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{wit.publicKey[index][j], L})
+		r[i], err = crypto.RandomScalarsFrom(rng, m)
+		if err != nil {
+			return nil, err
 		}
+	}
 
-		c = crypto.HashToScalar(toHashBytes)
-		c_old.Set(c)
-
-		i = (i + 1) % n
-		if i == 0 {
-			c0 = c_old
-		}
+	if T != nil {
+		aG[0].Add(aG[0], T)
+		aHP[0].Add(aHP[0], THp)
 	}
+	cNext := mlsag_hash(messageBytes, wit.publicKey[index], aG, aHP)
+	c0, c := mlsag_ring(wit.publicKey, keyImage, messageBytes, r, index, cNext)
 
+	r[index] = make([]*crypto.Scalar, m)
 	for j := 0; j < m; j++ {
 		r[index][j] = new(crypto.Scalar).Sub(alpha[j], new(crypto.Scalar).Mul(c, wit.privateKey[j]))
 	}
@@ -158,6 +206,12 @@ func (wit Mlsag_Witness) Mlsag_Prove() (*Mlsag_Proof, error) {
 }
 
 func (proof Mlsag_Proof) Mlsag_Verify() (bool, error) {
+	return proof.mlsag_verify(-1, nil, nil)
+}
+
+// mlsag_verify checks the ring, adding the adaptor points T, THp to L and R of the first column
+// of the row at index to check a pre-signature
+func (proof Mlsag_Proof) mlsag_verify(index int, T *crypto.Point, THp *crypto.Point) (bool, error) {
 	//startVerify := time.Now()
 	n := RingSize                // number of rows
 	m := len(proof.publicKey[0]) // number of columns
@@ -202,32 +256,14 @@ func (proof Mlsag_Proof) Mlsag_Verify() (bool, error) {
 		return false, fmt.Errorf("Mlsag_Verify c0 is invalid %v\n", proof.c0)
 	}
 
-	toHashBytes := make([]byte, 0)
-
-	c_old := proof.c0
-	c := new(crypto.Scalar)
-	L := new(crypto.Point)
-	R := new(crypto.Point)
-	Hi := new(crypto.Point)
+	c := new(crypto.Scalar).Set(proof.c0)
 	for i := 0; i < n; i++ {
-		toHashBytes = messageBytes
-
-		for j := 0; j < dsCols; j++ {
-			L = new(crypto.Point).AddPedersen(proof.r[i][j], crypto.G, c_old, proof.publicKey[i][j])
-			Hi = crypto.HashToPoint(proof.publicKey[i][j].ToBytes())
-			R = new(crypto.Point).AddPedersen(proof.r[i][j], Hi, c_old, proof.keyImage[j])
-
-			toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{proof.publicKey[i][j], L, R})
-		}
-
-		for j, j2 := dsCols, 0; j < m; j, j2 = j+1, j2+1 {
-			L = new(crypto.Point).AddPedersen(proof.r[i][j], crypto.G, c_old, proof.publicKey[i][j])
-
-			toHashBytes = crypto.AppendPointsToBytesArray(toHashBytes, []*crypto.Point{proof.publicKey[i][j], L})
+		L, R := mlsag_row(proof.publicKey[i], proof.keyImage, proof.r[i], c)
+		if i == index {
+			L[0].Add(L[0], T)
+			R[0].Add(R[0], THp)
 		}
-
-		c = crypto.HashToScalar(toHashBytes)
-		c_old.Set(c)
+		c = mlsag_hash(messageBytes, proof.publicKey[i], L, R)
 	}
 
 	res := crypto.CompareScalar(c, proof.c0) == 0
@@ -236,3 +272,91 @@ func (proof Mlsag_Proof) Mlsag_Verify() (bool, error) {
 	//fmt.Printf("verifyTime: %v - len private key %v: \n", verifyTime, m)
 	return res, nil
 }
+
+// Bytes encodes the proof as m || dsCols || message || c0 || key images || public keys || r
+func (proof Mlsag_Proof) Bytes() []byte {
+	if len(proof.publicKey) != RingSize || len(proof.r) != RingSize || len(proof.publicKey[0]) == 0 ||
+		len(proof.publicKey[0]) > MaxColumns || len(proof.keyImage) != proof.dsCols || proof.c0 == nil || proof.message == nil {
+		return []byte{}
+	}
+	m := len(proof.publicKey[0])
+	res := make([]byte, 0, mlsagProofSize(m, proof.dsCols))
+	res = append(res, byte(m), byte(proof.dsCols))
+	res = append(res, proof.message.ToBytes()...)
+	res = append(res, proof.c0.ToBytes()...)
+	res = crypto.AppendPointsToBytesArray(res, proof.keyImage)
+	for i := range proof.publicKey {
+		res = crypto.AppendPointsToBytesArray(res, proof.publicKey[i])
+	}
+	for i := range proof.r {
+		for _, r := range proof.r[i] {
+			res = append(res, r.ToBytes()...)
+		}
+	}
+	return res
+}
+
+func mlsagProofSize(m int, dsCols int) int {
+	return 2 + (2+dsCols+2*RingSize*m)*crypto.Ed25519KeySize
+}
+
+func (proof *Mlsag_Proof) SetBytes(bytes []byte) error {
+	if len(bytes) < 2 {
+		return errors.New("invalid mlsag proof size")
+	}
+	m, dsCols := int(bytes[0]), int(bytes[1])
+	if m == 0 || dsCols > m || len(bytes) != mlsagProofSize(m, dsCols) {
+		return errors.New("invalid mlsag proof size")
+	}
+
+	var err error
+	offset := 2
+	message, err := new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	offset += crypto.Ed25519KeySize
+	c0, err := new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	offset += crypto.Ed25519KeySize
+	keyImage := make([]*crypto.Point, dsCols)
+	for j := range keyImage {
+		keyImage[j], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+		offset += crypto.Ed25519KeySize
+	}
+	publicKey := make([][]*crypto.Point, RingSize)
+	for i := range publicKey {
+		publicKey[i] = make([]*crypto.Point, m)
+		for j := range publicKey[i] {
+			publicKey[i][j], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+			if err != nil {
+				return err
+			}
+			offset += crypto.Ed25519KeySize
+		}
+	}
+	r := make([][]*crypto.Scalar, RingSize)
+	for i := range r {
+		r[i] = make([]*crypto.Scalar, m)
+		for j := range r[i] {
+			r[i][j], err = new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+			if err != nil {
+				return err
+			}
+			offset += crypto.Ed25519KeySize
+		}
+	}
+
+	proof.c0 = c0
+	proof.r = r
+	proof.keyImage = keyImage
+	proof.dsCols = dsCols
+	proof.publicKey = publicKey
+	proof.message = message
+	return nil
+}
@@ -0,0 +1,194 @@
+package ringsignature
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/dleq"
+)
+
+// Mlsag adaptor signature, the ring counterpart of the schnorr adaptor signature.
+// The adaptor secret t is hidden in r[index][0] of the signing row. Both L and R of that column
+// use r, so the adaptor is the pair T = t*G, THp = t*Hp(P) where P is the first public key of the
+// signing row, with a dleq proof that they have the same discrete log.
+//
+// Pre-signing:      alpha*G + T and alpha*Hp(P) + THp start the ring, r'[index][0] = alpha - c*x
+// Pre-verification: the ring closes when T and THp are added to L and R at the signing row
+// Adapt:            r[index][0] = r'[index][0] + t gives a standard Mlsag_Proof
+// Extract:          t = r[index][0] - r'[index][0]
+//
+// The adaptor is bound to the signing row, so the counterparty learns the real input of the ring.
+
+type Mlsag_PreSignature struct {
+	proof        *Mlsag_Proof
+	index        int
+	adaptorProof *dleq.DLEQProof
+}
+
+// Mlsag_AdaptorProof returns the adaptor of the secret t for a signer whose first public key is publicKey
+func Mlsag_AdaptorProof(t *crypto.Scalar, publicKey *crypto.Point) (*dleq.DLEQProof, error) {
+	wit := new(dleq.DLEQWitness)
+	wit.Set(t, []*crypto.Point{crypto.G, crypto.HashToPoint(publicKey.ToBytes())})
+	return wit.Prove()
+}
+
+// checkAdaptor checks that the adaptor proof is about the bases G and Hp(publicKey)
+func checkAdaptor(adaptorProof *dleq.DLEQProof, publicKey *crypto.Point) error {
+	if adaptorProof == nil {
+		return errors.New("Mlsag adaptor proof is nil")
+	}
+	bases := adaptorProof.GetBases()
+	if len(bases) != 2 || !crypto.IsPointEqual(bases[0], crypto.G) ||
+		!crypto.IsPointEqual(bases[1], crypto.HashToPoint(publicKey.ToBytes())) {
+		return errors.New("Mlsag adaptor proof is not about the signing key")
+	}
+	res, err := adaptorProof.Verify()
+	if err != nil {
+		return err
+	}
+	if !res {
+		return errors.New("Mlsag invalid adaptor proof")
+	}
+	return nil
+}
+
+func (wit Mlsag_Witness) Mlsag_PreSign(adaptorProof *dleq.DLEQProof) (*Mlsag_PreSignature, error) {
+	return wit.Mlsag_PreSignWithRand(adaptorProof, rand.Reader)
+}
+
+// Mlsag_PreSignWithRand makes a pre-signature for the adaptor, reading the random scalars from rng
+func (wit Mlsag_Witness) Mlsag_PreSignWithRand(adaptorProof *dleq.DLEQProof, rng io.Reader) (*Mlsag_PreSignature, error) {
+	if wit.dsCols < 1 {
+		return nil, errors.New("Mlsag_PreSign the first column must be checked for double spending")
+	}
+	if wit.index < 0 || wit.index >= len(wit.publicKey) || len(wit.publicKey[wit.index]) == 0 {
+		return nil, errors.New("Mlsag_PreSign Index out of range")
+	}
+	if err := checkAdaptor(adaptorProof, wit.publicKey[wit.index][0]); err != nil {
+		return nil, err
+	}
+	points := adaptorProof.GetPoints()
+	proof, err := wit.mlsag_prove(rng, points[0], points[1])
+	if err != nil {
+		return nil, err
+	}
+	return &Mlsag_PreSignature{proof: proof, index: wit.index, adaptorProof: adaptorProof}, nil
+}
+
+// AdaptorPoint returns T = t*G, a cross group proof of the counterparty is checked against it
+func (presig Mlsag_PreSignature) AdaptorPoint() *crypto.Point {
+	return presig.adaptorProof.GetPoints()[0]
+}
+
+// Mlsag_PreVerify checks that the pre-signature becomes a valid mlsag proof once adapted
+// with the discrete log of the adaptor point
+func (presig Mlsag_PreSignature) Mlsag_PreVerify() (bool, error) {
+	if presig.proof == nil || presig.index < 0 || presig.index >= RingSize || len(presig.proof.publicKey) != RingSize {
+		return false, errors.New("Mlsag_PreVerify invalid pre-signature")
+	}
+	if presig.proof.dsCols < 1 || len(presig.proof.publicKey[presig.index]) == 0 {
+		return false, errors.New("Mlsag_PreVerify the first column must be checked for double spending")
+	}
+	if err := checkAdaptor(presig.adaptorProof, presig.proof.publicKey[presig.index][0]); err != nil {
+		return false, err
+	}
+	points := presig.adaptorProof.GetPoints()
+	if err := presig.checkPoints(points[0], points[1]); err != nil {
+		return false, err
+	}
+	return presig.proof.mlsag_verify(presig.index, points[0], points[1])
+}
+
+// checkPoints checks that the adaptor points T, THp and the key images have no component of
+// small order, which the counterparty could otherwise hide in them, and that the ring decodes
+func (presig Mlsag_PreSignature) checkPoints(T *crypto.Point, THp *crypto.Point) error {
+	if T == nil || !T.InPrimeOrderSubgroup() || THp == nil || !THp.InPrimeOrderSubgroup() {
+		return errors.New("Mlsag_PreVerify invalid adaptor point")
+	}
+	for _, keyImage := range presig.proof.keyImage {
+		if keyImage == nil || !keyImage.InPrimeOrderSubgroup() {
+			return errors.New("Mlsag_PreVerify invalid key image")
+		}
+	}
+	for i := range presig.proof.publicKey {
+		for _, publicKey := range presig.proof.publicKey[i] {
+			if publicKey == nil || !publicKey.PointValid() {
+				return errors.New("Mlsag_PreVerify invalid public key")
+			}
+		}
+	}
+	if presig.proof.message == nil || presig.proof.c0 == nil {
+		return errors.New("Mlsag_PreVerify invalid pre-signature")
+	}
+	return nil
+}
+
+// Mlsag_Adapt completes the pre-signature with the adaptor secret t
+func (presig Mlsag_PreSignature) Mlsag_Adapt(t *crypto.Scalar) (*Mlsag_Proof, error) {
+	if t == nil || !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(t), presig.AdaptorPoint()) {
+		return nil, errors.New("Mlsag_Adapt secret does not match the adaptor point")
+	}
+	r := make([][]*crypto.Scalar, len(presig.proof.r))
+	copy(r, presig.proof.r)
+	r[presig.index] = append([]*crypto.Scalar{}, r[presig.index]...)
+	r[presig.index][0] = new(crypto.Scalar).Add(r[presig.index][0], t)
+
+	proof := *presig.proof
+	proof.r = r
+	return &proof, nil
+}
+
+// Mlsag_Extract returns the adaptor secret t from the pre-signature and the proof made by Mlsag_Adapt
+func (presig Mlsag_PreSignature) Mlsag_Extract(proof *Mlsag_Proof) (*crypto.Scalar, error) {
+	if proof == nil || len(proof.r) != len(presig.proof.r) || len(proof.r[presig.index]) == 0 {
+		return nil, errors.New("Mlsag_Extract invalid proof")
+	}
+	t := new(crypto.Scalar).Sub(proof.r[presig.index][0], presig.proof.r[presig.index][0])
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(t), presig.AdaptorPoint()) {
+		return nil, errors.New("Mlsag_Extract secret does not match the adaptor point")
+	}
+	return t, nil
+}
+
+// Bytes encodes the pre-signature as index || proof || adaptor proof, to be sent to the counterparty
+func (presig Mlsag_PreSignature) Bytes() []byte {
+	if presig.proof == nil || presig.adaptorProof == nil || presig.index < 0 || presig.index >= RingSize {
+		return []byte{}
+	}
+	proofBytes := presig.proof.Bytes()
+	adaptorBytes := presig.adaptorProof.Bytes()
+	if len(proofBytes) == 0 || len(adaptorBytes) == 0 {
+		return []byte{}
+	}
+	res := make([]byte, 0, 1+len(proofBytes)+len(adaptorBytes))
+	res = append(res, byte(presig.index))
+	res = append(res, proofBytes...)
+	return append(res, adaptorBytes...)
+}
+
+// SetBytes parses a pre-signature, the counterparty checks it with Mlsag_PreVerify
+func (presig *Mlsag_PreSignature) SetBytes(bytes []byte) error {
+	if len(bytes) < 3 {
+		return errors.New("invalid mlsag pre-signature size")
+	}
+	index, m, dsCols := int(bytes[0]), int(bytes[1]), int(bytes[2])
+	size := 1 + mlsagProofSize(m, dsCols)
+	if index >= RingSize || len(bytes) <= size {
+		return errors.New("invalid mlsag pre-signature size")
+	}
+	proof := new(Mlsag_Proof)
+	if err := proof.SetBytes(bytes[1:size]); err != nil {
+		return err
+	}
+	adaptorProof := new(dleq.DLEQProof)
+	if err := adaptorProof.SetBytes(bytes[size:]); err != nil {
+		return err
+	}
+
+	presig.proof = proof
+	presig.index = index
+	presig.adaptorProof = adaptorProof
+	return nil
+}
@@ -0,0 +1,179 @@
+package ringsignature
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/dleq"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMlsagWitness(m int, index int, dsCols int) *Mlsag_Witness {
+	wit := new(Mlsag_Witness)
+	wit.message = crypto.RandomPoint()
+	wit.index = index
+	wit.dsCols = dsCols
+	wit.privateKey, wit.publicKey = newMultisigRing(m, index)
+	return wit
+}
+
+func TestMlsagAdaptor_Swap(t *testing.T) {
+	for _, index := range []int{0, 4, RingSize - 1} {
+		wit := newMlsagWitness(2, index, 1)
+
+		// the counterparty makes the adaptor for the signing key
+		secret := crypto.RandomScalar()
+		adaptorProof, err := Mlsag_AdaptorProof(secret, wit.publicKey[index][0])
+		assert.Equal(t, nil, err)
+
+		presig, err := wit.Mlsag_PreSign(adaptorProof)
+		assert.Equal(t, nil, err)
+		res, err := presig.Mlsag_PreVerify()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, res)
+		assert.Equal(t, nil, dleq.VerifyCrossGroup(new(crypto.Point).ScalarMultBase(secret), adaptorProof))
+
+		// the pre-signature is not a valid proof
+		res, _ = presig.proof.Mlsag_Verify()
+		assert.Equal(t, false, res)
+
+		// adapting with another secret fails
+		_, err = presig.Mlsag_Adapt(crypto.RandomScalar())
+		assert.NotEqual(t, nil, err)
+
+		proof, err := presig.Mlsag_Adapt(secret)
+		assert.Equal(t, nil, err)
+		res, err = proof.Mlsag_Verify()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, res)
+
+		// the pre-signature is not modified by adapting
+		res, _ = presig.Mlsag_PreVerify()
+		assert.Equal(t, true, res)
+
+		extracted, err := presig.Mlsag_Extract(proof)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, 0, crypto.CompareScalar(secret, extracted))
+
+		_, err = presig.Mlsag_Extract(presig.proof)
+		assert.NotEqual(t, nil, err)
+	}
+}
+
+func TestMlsagAdaptor_Invalid(t *testing.T) {
+	wit := newMlsagWitness(2, 2, 1)
+	secret := crypto.RandomScalar()
+
+	// an adaptor made for another key is rejected
+	adaptorProof, _ := Mlsag_AdaptorProof(secret, wit.publicKey[3][0])
+	_, err := wit.Mlsag_PreSign(adaptorProof)
+	assert.NotEqual(t, nil, err)
+
+	// the first column must be checked for double spending
+	adaptorProof, _ = Mlsag_AdaptorProof(secret, wit.publicKey[2][0])
+	wit.dsCols = 0
+	_, err = wit.Mlsag_PreSign(adaptorProof)
+	assert.NotEqual(t, nil, err)
+	wit.dsCols = 1
+
+	// a pre-signature with the adaptor of another secret does not verify
+	presig, err := wit.Mlsag_PreSign(adaptorProof)
+	assert.Equal(t, nil, err)
+	presig.adaptorProof, _ = Mlsag_AdaptorProof(crypto.RandomScalar(), wit.publicKey[2][0])
+	res, _ := presig.Mlsag_PreVerify()
+	assert.Equal(t, false, res)
+
+	// a pre-signature claiming another row does not verify
+	presig.adaptorProof = adaptorProof
+	presig.index = 3
+	res, _ = presig.Mlsag_PreVerify()
+	assert.Equal(t, false, res)
+}
+
+func TestMlsagAdaptor_Bytes(t *testing.T) {
+	wit := newMlsagWitness(3, 5, 2)
+	secret := crypto.RandomScalar()
+	adaptorProof, _ := Mlsag_AdaptorProof(secret, wit.publicKey[5][0])
+	presig, err := wit.Mlsag_PreSign(adaptorProof)
+	assert.Equal(t, nil, err)
+
+	// the counterparty receives the pre-signature
+	bytes := presig.Bytes()
+	received := new(Mlsag_PreSignature)
+	assert.Equal(t, nil, received.SetBytes(bytes))
+	assert.Equal(t, bytes, received.Bytes())
+	res, err := received.Mlsag_PreVerify()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	// the signer adapts and publishes the proof, the counterparty extracts the secret from it
+	proof, err := presig.Mlsag_Adapt(secret)
+	assert.Equal(t, nil, err)
+	published := new(Mlsag_Proof)
+	assert.Equal(t, nil, published.SetBytes(proof.Bytes()))
+	assert.Equal(t, proof.Bytes(), published.Bytes())
+	res, err = published.Mlsag_Verify()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+	extracted, err := received.Mlsag_Extract(published)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, crypto.CompareScalar(secret, extracted))
+
+	// a tampered pre-signature is rejected
+	tampered := append([]byte{}, bytes...)
+	tampered[0] = 3
+	assert.Equal(t, nil, received.SetBytes(tampered))
+	res, _ = received.Mlsag_PreVerify()
+	assert.Equal(t, false, res)
+
+	invalid := [][]byte{
+		nil,
+		bytes[:len(bytes)-1],
+		append([]byte{RingSize}, bytes[1:]...),
+		bytes[:1+mlsagProofSize(3, 2)],
+	}
+	for _, data := range invalid {
+		assert.NotEqual(t, nil, new(Mlsag_PreSignature).SetBytes(data))
+	}
+	proofBytes := proof.Bytes()
+	for _, data := range [][]byte{nil, proofBytes[:len(proofBytes)-1], append([]byte{0}, proofBytes[1:]...), append([]byte{3, 4}, proofBytes[2:]...)} {
+		assert.NotEqual(t, nil, new(Mlsag_Proof).SetBytes(data))
+	}
+
+	// m and dsCols are one byte each, wider proofs have no encoding and are never made
+	wide := newMlsagWitness(MaxColumns+1, 0, 1)
+	_, err = wide.Mlsag_Prove()
+	assert.NotEqual(t, nil, err)
+	for i := range published.publicKey {
+		for len(published.publicKey[i]) <= MaxColumns {
+			published.publicKey[i] = append(published.publicKey[i], crypto.RandomPoint())
+		}
+	}
+	assert.Equal(t, []byte{}, published.Bytes())
+}
+
+func TestMlsagAdaptor_Torsion(t *testing.T) {
+	wit := newMlsagWitness(2, 1, 1)
+	secret := crypto.RandomScalar()
+	adaptorProof, _ := Mlsag_AdaptorProof(secret, wit.publicKey[1][0])
+	presig, err := wit.Mlsag_PreSign(adaptorProof)
+	assert.Equal(t, nil, err)
+	res, _ := presig.Mlsag_PreVerify()
+	assert.Equal(t, true, res)
+
+	key := C25519.HexToKey("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	small, _ := new(crypto.Point).SetKey(&key)
+	points := adaptorProof.GetPoints()
+
+	// the adaptor points and the key images must not have a component of small order
+	assert.NotEqual(t, nil, presig.checkPoints(new(crypto.Point).Add(points[0], small), points[1]))
+	assert.NotEqual(t, nil, presig.checkPoints(points[0], new(crypto.Point).Add(points[1], small)))
+
+	keyImage := presig.proof.keyImage[0]
+	presig.proof.keyImage[0] = new(crypto.Point).Add(keyImage, small)
+	res, err = presig.Mlsag_PreVerify()
+	assert.Equal(t, false, res)
+	assert.NotEqual(t, nil, err)
+	presig.proof.keyImage[0] = keyImage
+}
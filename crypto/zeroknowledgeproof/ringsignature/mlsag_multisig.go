@@ -0,0 +1,746 @@
+package ringsignature
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/secretsharing"
+	"github.com/incognitochain/incognito-chain-privacy/crypto/zeroknowledgeproof/dleq"
+)
+
+// Threshold mlsag signing of a shared stealth address.
+// The private key x_j of each column is split into Shamir shares x_kj of the parties,
+// any threshold of them sign together and the coordinator assembles a standard Mlsag_Proof.
+//
+// Round 1:  party k draws the nonces d_kj, e_kj and sends D_kj = d_kj*G, E_kj = e_kj*G,
+//           D'_kj = d_kj*Hp(P_j), E'_kj = e_kj*Hp(P_j), and for the double spending columns
+//           the partial key image I_kj = x_kj*Hp(P_j) with a dleq proof against X_kj = x_kj*G
+// Package:  the coordinator picks the ring, the random r of the other rows and sends them
+//           with the round 1 messages of the signers. Everyone computes the key images
+//           I_j = sum lambda_k*I_kj, the binding factors rho_k over the whole package including r,
+//           the partial alpha commitments
+//           alpha_j*G = sum D_kj + rho_k*E_kj, alpha_j*Hp(P_j) = sum D'_kj + rho_k*E'_kj,
+//           and closes the ring up to the challenge c of the signing row
+// Round 2:  party k sends s_kj = d_kj + rho_k*e_kj - c*lambda_k*x_kj
+// Combine:  the coordinator checks each s_kj and sets r[index][j] = sum s_kj
+//
+// The nonces live only inside a Mlsag_MultisigSession and are erased by its first Sign.
+
+const (
+	cStringMlsagMultisigNonce = "mlsag/multisig/nonce"
+	cStringMlsagMultisigRho   = "mlsag/multisig/rho"
+)
+
+// size of a dleq proof with two bases
+const mlsagDLEQProofSize = 1 + 6*crypto.Ed25519KeySize
+
+// Mlsag_MultisigKey is the secret of a party, its shares of the private keys of all the columns
+type Mlsag_MultisigKey struct {
+	identifier int
+	shares     []*crypto.Scalar
+	public     *Mlsag_MultisigPublic
+}
+
+// Mlsag_MultisigPublic is known to every party, the public keys of the shared address
+// and the verifying shares X_kj = x_kj*G of each party
+type Mlsag_MultisigPublic struct {
+	threshold       int
+	publicKey       []*crypto.Point
+	verifyingShares map[int][]*crypto.Point
+}
+
+// Mlsag_MultisigRound1 is the round 1 message of a party
+type Mlsag_MultisigRound1 struct {
+	identifier   int
+	d            []*crypto.Point
+	e            []*crypto.Point
+	dHP          []*crypto.Point
+	eHP          []*crypto.Point
+	keyImageDLEQ []*dleq.DLEQProof
+}
+
+// Mlsag_MultisigSigningPackage is sent by the coordinator to the signers
+type Mlsag_MultisigSigningPackage struct {
+	message   *crypto.Point
+	publicKey [][]*crypto.Point
+	index     int
+	r         [][]*crypto.Scalar
+	round1    []*Mlsag_MultisigRound1
+}
+
+// Mlsag_MultisigRound2 is the round 2 message of a party
+type Mlsag_MultisigRound2 struct {
+	identifier int
+	s          []*crypto.Scalar
+}
+
+type mlsagMultisigNonces struct {
+	d []*crypto.Scalar
+	e []*crypto.Scalar
+}
+
+// Mlsag_MultisigSession is the state of a party for one signature
+type Mlsag_MultisigSession struct {
+	key    *Mlsag_MultisigKey
+	nonces *mlsagMultisigNonces
+	round1 *Mlsag_MultisigRound1
+}
+
+type Mlsag_MultisigCoordinator struct {
+	public *Mlsag_MultisigPublic
+}
+
+// mlsagMultisigState is what both the parties and the coordinator compute from a signing package
+type mlsagMultisigState struct {
+	lambda   map[int]*crypto.Scalar
+	rho      map[int]*crypto.Scalar
+	keyImage []*crypto.Point
+	c0       *crypto.Scalar
+	c        *crypto.Scalar
+}
+
+// Mlsag_MultisigKeygen splits the private keys of the columns of a shared address into shares
+// of n parties, any threshold of them can sign. The dealer must erase the private keys
+func Mlsag_MultisigKeygen(privateKey []*crypto.Scalar, threshold int, n int, rng io.Reader) ([]*Mlsag_MultisigKey, *Mlsag_MultisigPublic, error) {
+	m := len(privateKey)
+	if m < 2 {
+		return nil, nil, errors.New("Mlsag_MultisigKeygen length of private list must be at least 2")
+	}
+	if threshold < 1 || n < threshold || n > 1<<16-1 {
+		return nil, nil, errors.New("Mlsag_MultisigKeygen invalid threshold or number of parties")
+	}
+	if rng == nil {
+		rng = rand.Reader
+	}
+
+	public := &Mlsag_MultisigPublic{
+		threshold:       threshold,
+		publicKey:       make([]*crypto.Point, m),
+		verifyingShares: make(map[int][]*crypto.Point, n),
+	}
+	keys := make([]*Mlsag_MultisigKey, n)
+	for k := range keys {
+		keys[k] = &Mlsag_MultisigKey{identifier: k + 1, shares: make([]*crypto.Scalar, m), public: public}
+		public.verifyingShares[k+1] = make([]*crypto.Point, m)
+	}
+
+	for j := 0; j < m; j++ {
+		public.publicKey[j] = new(crypto.Point).ScalarMultBase(privateKey[j])
+		coeffs, err := crypto.RandomScalarsFrom(rng, threshold)
+		if err != nil {
+			return nil, nil, err
+		}
+		coeffs[0].Set(privateKey[j])
+		for k := range keys {
+			// share f(k+1) by Horner's rule
+			x := new(crypto.Scalar).FromUint64(uint64(k + 1))
+			share := new(crypto.Scalar).FromUint64(0)
+			for i := threshold - 1; i >= 0; i-- {
+				share.MulAdd(share, x, coeffs[i])
+			}
+			keys[k].shares[j] = share
+			public.verifyingShares[k+1][j] = new(crypto.Point).ScalarMultBase(share)
+		}
+		for _, c := range coeffs {
+			c.FromUint64(0)
+		}
+	}
+	return keys, public, nil
+}
+
+func (key Mlsag_MultisigKey) Identifier() int {
+	return key.identifier
+}
+
+func (key Mlsag_MultisigKey) Public() *Mlsag_MultisigPublic {
+	return key.public
+}
+
+// PublicKey returns the public keys of the columns of the shared address
+func (public Mlsag_MultisigPublic) PublicKey() []*crypto.Point {
+	return public.publicKey
+}
+
+// mlsagLagrange returns the coefficient of the share of id for the interpolation at zero over ids,
+// see secretsharing.LagrangeCoefficient
+func mlsagLagrange(id int, ids []int) (*crypto.Scalar, error) {
+	indices := make([]uint16, len(ids))
+	for i, j := range ids {
+		if j < 1 || j > secretsharing.MaxShares {
+			return nil, fmt.Errorf("Mlsag_Multisig invalid identifier %v", j)
+		}
+		indices[i] = uint16(j)
+	}
+	return secretsharing.LagrangeCoefficient(uint16(id), indices)
+}
+
+// NewMlsag_MultisigSession draws the nonces of the party for a signature whose first dsCols
+// columns are checked for double spending, crypto/rand is used if rng is nil
+func NewMlsag_MultisigSession(key *Mlsag_MultisigKey, dsCols int, rng io.Reader) (*Mlsag_MultisigSession, error) {
+	if key == nil {
+		return nil, errors.New("NewMlsag_MultisigSession key is nil")
+	}
+	m := len(key.shares)
+	if dsCols > m || dsCols < 0 {
+		return nil, errors.New("NewMlsag_MultisigSession dsCols must not be greater than length of private key list")
+	}
+	if rng == nil {
+		rng = rand.Reader
+	}
+	secret := make([]byte, 0, m*crypto.Ed25519KeySize)
+	for _, share := range key.shares {
+		secret = append(secret, share.ToBytes()...)
+	}
+	nonceReader, err := crypto.NewNonceReader(secret, rng, []byte(cStringMlsagMultisigNonce))
+	if err != nil {
+		return nil, err
+	}
+	k, err := crypto.RandomScalarsFrom(nonceReader, 2*m)
+	if err != nil {
+		return nil, err
+	}
+
+	nonces := &mlsagMultisigNonces{d: k[:m], e: k[m:]}
+	round1 := &Mlsag_MultisigRound1{
+		identifier:   key.identifier,
+		d:            make([]*crypto.Point, m),
+		e:            make([]*crypto.Point, m),
+		dHP:          make([]*crypto.Point, dsCols),
+		eHP:          make([]*crypto.Point, dsCols),
+		keyImageDLEQ: make([]*dleq.DLEQProof, dsCols),
+	}
+	for j := 0; j < m; j++ {
+		round1.d[j] = new(crypto.Point).ScalarMultBase(nonces.d[j])
+		round1.e[j] = new(crypto.Point).ScalarMultBase(nonces.e[j])
+		if j < dsCols {
+			Hp := crypto.HashToPoint(key.public.publicKey[j].ToBytes())
+			round1.dHP[j] = new(crypto.Point).ScalarMult(Hp, nonces.d[j])
+			round1.eHP[j] = new(crypto.Point).ScalarMult(Hp, nonces.e[j])
+
+			wit := new(dleq.DLEQWitness)
+			wit.Set(key.shares[j], []*crypto.Point{crypto.G, Hp})
+			round1.keyImageDLEQ[j], err = wit.ProveWithRand(nonceReader)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Mlsag_MultisigSession{key: key, nonces: nonces, round1: round1}, nil
+}
+
+// Round1 returns the round 1 message of the party
+func (session Mlsag_MultisigSession) Round1() *Mlsag_MultisigRound1 {
+	return session.round1
+}
+
+// verify checks the shape of the message and the partial key images against the verifying shares
+func (msg Mlsag_MultisigRound1) verify(public *Mlsag_MultisigPublic) error {
+	verifyingShares, ok := public.verifyingShares[msg.identifier]
+	if !ok {
+		return fmt.Errorf("Mlsag_Multisig unknown party %v", msg.identifier)
+	}
+	m := len(public.publicKey)
+	dsCols := len(msg.keyImageDLEQ)
+	if len(msg.d) != m || len(msg.e) != m || len(msg.dHP) != dsCols || len(msg.eHP) != dsCols || dsCols > m {
+		return fmt.Errorf("Mlsag_Multisig invalid round 1 message of party %v", msg.identifier)
+	}
+	for j := 0; j < dsCols; j++ {
+		proof := msg.keyImageDLEQ[j]
+		if proof == nil || len(proof.GetBases()) != 2 {
+			return fmt.Errorf("Mlsag_Multisig invalid partial key image of party %v", msg.identifier)
+		}
+		Hp := crypto.HashToPoint(public.publicKey[j].ToBytes())
+		bases, points := proof.GetBases(), proof.GetPoints()
+		if !crypto.IsPointEqual(bases[0], crypto.G) || !crypto.IsPointEqual(bases[1], Hp) ||
+			!crypto.IsPointEqual(points[0], verifyingShares[j]) {
+			return fmt.Errorf("Mlsag_Multisig invalid partial key image of party %v", msg.identifier)
+		}
+		if res, _ := proof.Verify(); !res {
+			return fmt.Errorf("Mlsag_Multisig invalid partial key image of party %v", msg.identifier)
+		}
+	}
+	return nil
+}
+
+// partialKeyImage returns I_kj
+func (msg Mlsag_MultisigRound1) partialKeyImage(j int) *crypto.Point {
+	return msg.keyImageDLEQ[j].GetPoints()[1]
+}
+
+func (pkg Mlsag_MultisigSigningPackage) identifiers() []int {
+	ids := make([]int, len(pkg.round1))
+	for i, msg := range pkg.round1 {
+		ids[i] = msg.identifier
+	}
+	return ids
+}
+
+func (pkg Mlsag_MultisigSigningPackage) dsCols() int {
+	return len(pkg.round1[0].keyImageDLEQ)
+}
+
+// validate checks the signing package against the public keys of the shared address
+func (pkg Mlsag_MultisigSigningPackage) validate(public *Mlsag_MultisigPublic) error {
+	n := RingSize
+	m := len(public.publicKey)
+	if len(pkg.round1) < public.threshold {
+		return errors.New("Mlsag_Multisig signing package needs the round 1 messages of at least threshold parties")
+	}
+	if pkg.message == nil || pkg.index < 0 || pkg.index >= n || len(pkg.publicKey) != n || len(pkg.r) != n {
+		return errors.New("Mlsag_Multisig invalid signing package")
+	}
+	for i := 0; i < n; i++ {
+		if len(pkg.publicKey[i]) != m {
+			return errors.New("Mlsag_Multisig rows of public key matrix must be equal number of cols")
+		}
+		if i != pkg.index && len(pkg.r[i]) != m {
+			return errors.New("Mlsag_Multisig rows of r matrix must be equal number of cols")
+		}
+	}
+	for j := 0; j < m; j++ {
+		if !crypto.IsPointEqual(pkg.publicKey[pkg.index][j], public.publicKey[j]) {
+			return errors.New("Mlsag_Multisig the signing row is not the shared address")
+		}
+	}
+	dsCols := pkg.dsCols()
+	for i, msg := range pkg.round1 {
+		if i > 0 && msg.identifier <= pkg.round1[i-1].identifier {
+			return errors.New("Mlsag_Multisig round 1 messages must be sorted by identifier without duplicate")
+		}
+		if len(msg.keyImageDLEQ) != dsCols {
+			return fmt.Errorf("Mlsag_Multisig round 1 message of party %v has another dsCols", msg.identifier)
+		}
+		if err := msg.verify(public); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// state computes the key images, the binding factors and the challenges of the signing package
+func (pkg Mlsag_MultisigSigningPackage) state(public *Mlsag_MultisigPublic) (*mlsagMultisigState, error) {
+	m := len(public.publicKey)
+	dsCols := pkg.dsCols()
+	ids := pkg.identifiers()
+	st := &mlsagMultisigState{
+		lambda:   make(map[int]*crypto.Scalar, len(ids)),
+		rho:      make(map[int]*crypto.Scalar, len(ids)),
+		keyImage: make([]*crypto.Point, dsCols),
+	}
+	for _, id := range ids {
+		lambda, err := mlsagLagrange(id, ids)
+		if err != nil {
+			return nil, err
+		}
+		st.lambda[id] = lambda
+	}
+
+	// I_j = sum lambda_k * I_kj
+	for j := 0; j < dsCols; j++ {
+		scalars := make([]*crypto.Scalar, len(ids))
+		points := make([]*crypto.Point, len(ids))
+		for k, msg := range pkg.round1 {
+			scalars[k] = st.lambda[msg.identifier]
+			points[k] = msg.partialKeyImage(j)
+		}
+		st.keyImage[j] = new(crypto.Point).MultiScalarMult(scalars, points)
+	}
+
+	// rho_k = H(message || m || dsCols || ring || index || r of the other rows || key images ||
+	// round 1 messages || k). The ring challenge depends on r, which the coordinator picks freely,
+	// so r is bound as well: otherwise the coordinator could draw many challenges for the same
+	// alpha commitments across concurrent sessions and forge with a ROS / Wagner attack
+	prefix := []byte(cStringMlsagMultisigRho)
+	prefix = append(prefix, pkg.message.ToBytes()...)
+	prefix = append(prefix, byte(m), byte(dsCols))
+	for i := range pkg.publicKey {
+		prefix = crypto.AppendPointsToBytesArray(prefix, pkg.publicKey[i])
+	}
+	prefix = append(prefix, byte(pkg.index))
+	for i := range pkg.r {
+		if i == pkg.index {
+			continue
+		}
+		for _, r := range pkg.r[i] {
+			prefix = append(prefix, r.ToBytes()...)
+		}
+	}
+	prefix = crypto.AppendPointsToBytesArray(prefix, st.keyImage)
+	for _, msg := range pkg.round1 {
+		prefix = append(prefix, msg.Bytes()...)
+	}
+	for _, id := range ids {
+		st.rho[id] = crypto.HashToScalar(append(append([]byte{}, prefix...), byte(id>>8), byte(id)))
+	}
+
+	// alpha_j*G and alpha_j*Hp(P_j)
+	aG := make([]*crypto.Point, m)
+	aHP := make([]*crypto.Point, dsCols)
+	one := new(crypto.Scalar).FromUint64(1)
+	for j := 0; j < m; j++ {
+		aG[j] = new(crypto.Point).Identity()
+		if j < dsCols {
+			aHP[j] = new(crypto.Point).Identity()
+		}
+		for _, msg := range pkg.round1 {
+			rho := st.rho[msg.identifier]
+			aG[j].Add(aG[j], new(crypto.Point).AddPedersen(one, msg.d[j], rho, msg.e[j]))
+			if j < dsCols {
+				aHP[j].Add(aHP[j], new(crypto.Point).AddPedersen(one, msg.dHP[j], rho, msg.eHP[j]))
+			}
+		}
+	}
+
+	messageBytes := pkg.message.ToBytes()
+	cNext := mlsag_hash(messageBytes, pkg.publicKey[pkg.index], aG, aHP)
+	st.c0, st.c = mlsag_ring(pkg.publicKey, st.keyImage, messageBytes, pkg.r, pkg.index, cNext)
+	return st, nil
+}
+
+// Sign returns the round 2 message of the party for the signing package.
+// It erases the nonces, any later call fails
+func (session *Mlsag_MultisigSession) Sign(pkg *Mlsag_MultisigSigningPackage) (*Mlsag_MultisigRound2, error) {
+	if pkg == nil || len(pkg.round1) == 0 {
+		return nil, errors.New("Mlsag_Multisig signing package is empty")
+	}
+	var own *Mlsag_MultisigRound1
+	for _, msg := range pkg.round1 {
+		if msg.identifier == session.key.identifier {
+			own = msg
+		}
+	}
+	if own == nil || !bytes.Equal(own.Bytes(), session.round1.Bytes()) {
+		return nil, errors.New("Mlsag_Multisig signing package does not hold the round 1 message of this session")
+	}
+	if err := pkg.validate(session.key.public); err != nil {
+		return nil, err
+	}
+	nonces := session.nonces
+	if nonces.d == nil || nonces.e == nil {
+		return nil, errors.New("Mlsag_Multisig nonce already used")
+	}
+	d, e := nonces.d, nonces.e
+	nonces.d, nonces.e = nil, nil
+
+	st, err := pkg.state(session.key.public)
+	if err != nil {
+		return nil, err
+	}
+	id := session.key.identifier
+	cLambda := new(crypto.Scalar).Mul(st.c, st.lambda[id])
+
+	// s_kj = d_kj + rho_k*e_kj - c*lambda_k*x_kj
+	s := make([]*crypto.Scalar, len(d))
+	for j := range s {
+		s[j] = new(crypto.Scalar).MulAdd(e[j], st.rho[id], d[j])
+		s[j].Sub(s[j], new(crypto.Scalar).Mul(cLambda, session.key.shares[j]))
+		d[j].FromUint64(0)
+		e[j].FromUint64(0)
+	}
+	return &Mlsag_MultisigRound2{identifier: id, s: s}, nil
+}
+
+func NewMlsag_MultisigCoordinator(public *Mlsag_MultisigPublic) *Mlsag_MultisigCoordinator {
+	return &Mlsag_MultisigCoordinator{public: public}
+}
+
+// NewSigningPackage puts the shared address at row index of the ring, the other rows are decoys,
+// and draws the r of the decoy rows from rng, crypto/rand if rng is nil
+func (coord Mlsag_MultisigCoordinator) NewSigningPackage(round1 []*Mlsag_MultisigRound1, publicKey [][]*crypto.Point, index int, message *crypto.Point, rng io.Reader) (*Mlsag_MultisigSigningPackage, error) {
+	if len(round1) == 0 {
+		return nil, errors.New("Mlsag_Multisig round 1 messages are empty")
+	}
+	if len(publicKey) != RingSize || index < 0 || index >= RingSize {
+		return nil, errors.New("Mlsag_Multisig cols of public key matrix must be equal RingSize")
+	}
+	if rng == nil {
+		rng = rand.Reader
+	}
+	sorted := make([]*Mlsag_MultisigRound1, len(round1))
+	for i, msg := range round1 {
+		if msg == nil {
+			return nil, errors.New("Mlsag_Multisig round 1 message is nil")
+		}
+		sorted[i] = msg
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].identifier < sorted[j].identifier
+	})
+
+	r := make([][]*crypto.Scalar, RingSize)
+	for i := range r {
+		if i == index {
+			continue
+		}
+		var err error
+		r[i], err = crypto.RandomScalarsFrom(rng, len(coord.public.publicKey))
+		if err != nil {
+			return nil, err
+		}
+	}
+	pkg := &Mlsag_MultisigSigningPackage{
+		message:   message,
+		publicKey: publicKey,
+		index:     index,
+		r:         r,
+		round1:    sorted,
+	}
+	if err := pkg.validate(coord.public); err != nil {
+		return nil, err
+	}
+	return pkg, nil
+}
+
+// Combine checks the round 2 messages of all the signers and assembles the mlsag proof.
+// An invalid message is reported with the identifier of its party
+func (coord Mlsag_MultisigCoordinator) Combine(pkg *Mlsag_MultisigSigningPackage, round2 []*Mlsag_MultisigRound2) (*Mlsag_Proof, error) {
+	if pkg == nil || len(round2) != len(pkg.round1) {
+		return nil, errors.New("Mlsag_Multisig combine needs one round 2 message per signer")
+	}
+	if err := pkg.validate(coord.public); err != nil {
+		return nil, err
+	}
+	st, err := pkg.state(coord.public)
+	if err != nil {
+		return nil, err
+	}
+	m := len(coord.public.publicKey)
+	dsCols := pkg.dsCols()
+
+	rIndex := make([]*crypto.Scalar, m)
+	for j := range rIndex {
+		rIndex[j] = new(crypto.Scalar).FromUint64(0)
+	}
+	seen := make(map[int]bool, len(round2))
+	for _, msg := range round2 {
+		if msg == nil {
+			return nil, errors.New("Mlsag_Multisig round 2 message is nil")
+		}
+		var r1 *Mlsag_MultisigRound1
+		for _, r := range pkg.round1 {
+			if r.identifier == msg.identifier {
+				r1 = r
+			}
+		}
+		if r1 == nil || seen[msg.identifier] || len(msg.s) != m {
+			return nil, fmt.Errorf("Mlsag_Multisig unexpected round 2 message of party %v", msg.identifier)
+		}
+		seen[msg.identifier] = true
+
+		// s_kj*G == D_kj + rho_k*E_kj - c*lambda_k*X_kj and the same over Hp(P_j) with I_kj
+		rho := st.rho[msg.identifier]
+		cLambda := new(crypto.Scalar).Mul(st.c, st.lambda[msg.identifier])
+		cLambdaNeg := new(crypto.Scalar).Sub(new(crypto.Scalar).FromUint64(0), cLambda)
+		verifyingShares := coord.public.verifyingShares[msg.identifier]
+		for j := 0; j < m; j++ {
+			if msg.s[j] == nil || !msg.s[j].ScalarValid() {
+				return nil, fmt.Errorf("Mlsag_Multisig invalid round 2 message of party %v", msg.identifier)
+			}
+			expected := new(crypto.Point).AddPedersen(rho, r1.e[j], cLambdaNeg, verifyingShares[j])
+			expected.Add(expected, r1.d[j])
+			if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(msg.s[j]), expected) {
+				return nil, fmt.Errorf("Mlsag_Multisig invalid round 2 message of party %v", msg.identifier)
+			}
+			if j < dsCols {
+				Hp := crypto.HashToPoint(coord.public.publicKey[j].ToBytes())
+				expected = new(crypto.Point).AddPedersen(rho, r1.eHP[j], cLambdaNeg, r1.partialKeyImage(j))
+				expected.Add(expected, r1.dHP[j])
+				if !crypto.IsPointEqual(new(crypto.Point).ScalarMult(Hp, msg.s[j]), expected) {
+					return nil, fmt.Errorf("Mlsag_Multisig invalid round 2 message of party %v", msg.identifier)
+				}
+			}
+			rIndex[j].Add(rIndex[j], msg.s[j])
+		}
+	}
+
+	r := make([][]*crypto.Scalar, RingSize)
+	copy(r, pkg.r)
+	r[pkg.index] = rIndex
+	return &Mlsag_Proof{
+		c0:        st.c0,
+		r:         r,
+		keyImage:  st.keyImage,
+		dsCols:    dsCols,
+		publicKey: pkg.publicKey,
+		message:   pkg.message,
+	}, nil
+}
+
+// Bytes encodes the message as identifier || m || dsCols || D || E || D' || E' || dleq proofs
+func (msg Mlsag_MultisigRound1) Bytes() []byte {
+	m, dsCols := len(msg.d), len(msg.keyImageDLEQ)
+	res := make([]byte, 0, mlsagRound1Size(m, dsCols))
+	res = append(res, byte(msg.identifier>>8), byte(msg.identifier), byte(m), byte(dsCols))
+	res = crypto.AppendPointsToBytesArray(res, msg.d)
+	res = crypto.AppendPointsToBytesArray(res, msg.e)
+	res = crypto.AppendPointsToBytesArray(res, msg.dHP)
+	res = crypto.AppendPointsToBytesArray(res, msg.eHP)
+	for _, proof := range msg.keyImageDLEQ {
+		res = append(res, proof.Bytes()...)
+	}
+	return res
+}
+
+func mlsagRound1Size(m int, dsCols int) int {
+	return 4 + 2*(m+dsCols)*crypto.Ed25519KeySize + dsCols*mlsagDLEQProofSize
+}
+
+func (msg *Mlsag_MultisigRound1) SetBytes(bytes []byte) error {
+	if len(bytes) < 4 {
+		return errors.New("invalid mlsag multisig round 1 message size")
+	}
+	id, m, dsCols := int(bytes[0])<<8|int(bytes[1]), int(bytes[2]), int(bytes[3])
+	if id == 0 || dsCols > m || len(bytes) != mlsagRound1Size(m, dsCols) {
+		return errors.New("invalid mlsag multisig round 1 message size")
+	}
+	offset := 4
+	points := make([]*crypto.Point, 2*(m+dsCols))
+	for i := range points {
+		var err error
+		points[i], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+		offset += crypto.Ed25519KeySize
+	}
+	proofs := make([]*dleq.DLEQProof, dsCols)
+	for j := range proofs {
+		proofs[j] = new(dleq.DLEQProof)
+		if err := proofs[j].SetBytes(bytes[offset : offset+mlsagDLEQProofSize]); err != nil {
+			return err
+		}
+		offset += mlsagDLEQProofSize
+	}
+
+	msg.identifier = id
+	msg.d, msg.e = points[:m], points[m:2*m]
+	msg.dHP, msg.eHP = points[2*m:2*m+dsCols], points[2*m+dsCols:]
+	msg.keyImageDLEQ = proofs
+	return nil
+}
+
+// Bytes encodes the package as index || m || number of signers || message || public keys ||
+// r of the other rows || round 1 messages
+func (pkg Mlsag_MultisigSigningPackage) Bytes() []byte {
+	if len(pkg.publicKey) != RingSize || len(pkg.round1) == 0 {
+		return []byte{}
+	}
+	m := len(pkg.publicKey[0])
+	res := []byte{byte(pkg.index), byte(m), byte(len(pkg.round1) >> 8), byte(len(pkg.round1))}
+	res = append(res, pkg.message.ToBytes()...)
+	for i := range pkg.publicKey {
+		res = crypto.AppendPointsToBytesArray(res, pkg.publicKey[i])
+	}
+	for i := range pkg.r {
+		if i == pkg.index {
+			continue
+		}
+		for _, r := range pkg.r[i] {
+			res = append(res, r.ToBytes()...)
+		}
+	}
+	for _, msg := range pkg.round1 {
+		res = append(res, msg.Bytes()...)
+	}
+	return res
+}
+
+func (pkg *Mlsag_MultisigSigningPackage) SetBytes(bytes []byte) error {
+	if len(bytes) < 4+crypto.Ed25519KeySize {
+		return errors.New("invalid mlsag multisig signing package size")
+	}
+	index, m, count := int(bytes[0]), int(bytes[1]), int(bytes[2])<<8|int(bytes[3])
+	fixed := 4 + (1+RingSize*m+(RingSize-1)*m)*crypto.Ed25519KeySize
+	if index >= RingSize || m == 0 || count == 0 || len(bytes) < fixed+4 {
+		return errors.New("invalid mlsag multisig signing package size")
+	}
+	dsCols := int(bytes[fixed+3])
+	if len(bytes) != fixed+count*mlsagRound1Size(m, dsCols) {
+		return errors.New("invalid mlsag multisig signing package size")
+	}
+
+	var err error
+	offset := 4
+	message, err := new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	offset += crypto.Ed25519KeySize
+	publicKey := make([][]*crypto.Point, RingSize)
+	for i := range publicKey {
+		publicKey[i] = make([]*crypto.Point, m)
+		for j := range publicKey[i] {
+			publicKey[i][j], err = new(crypto.Point).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+			if err != nil {
+				return err
+			}
+			offset += crypto.Ed25519KeySize
+		}
+	}
+	r := make([][]*crypto.Scalar, RingSize)
+	for i := range r {
+		if i == index {
+			continue
+		}
+		r[i] = make([]*crypto.Scalar, m)
+		for j := range r[i] {
+			r[i][j], err = new(crypto.Scalar).FromBytes(bytes[offset : offset+crypto.Ed25519KeySize])
+			if err != nil {
+				return err
+			}
+			offset += crypto.Ed25519KeySize
+		}
+	}
+	round1 := make([]*Mlsag_MultisigRound1, count)
+	size := mlsagRound1Size(m, dsCols)
+	for k := range round1 {
+		round1[k] = new(Mlsag_MultisigRound1)
+		if err := round1[k].SetBytes(bytes[offset : offset+size]); err != nil {
+			return err
+		}
+		offset += size
+	}
+
+	pkg.index = index
+	pkg.message = message
+	pkg.publicKey = publicKey
+	pkg.r = r
+	pkg.round1 = round1
+	return nil
+}
+
+// Bytes encodes the message as identifier || m || s
+func (msg Mlsag_MultisigRound2) Bytes() []byte {
+	res := make([]byte, 0, 3+len(msg.s)*crypto.Ed25519KeySize)
+	res = append(res, byte(msg.identifier>>8), byte(msg.identifier), byte(len(msg.s)))
+	for _, s := range msg.s {
+		res = append(res, s.ToBytes()...)
+	}
+	return res
+}
+
+func (msg *Mlsag_MultisigRound2) SetBytes(bytes []byte) error {
+	if len(bytes) < 3 {
+		return errors.New("invalid mlsag multisig round 2 message size")
+	}
+	id, m := int(bytes[0])<<8|int(bytes[1]), int(bytes[2])
+	if id == 0 || m == 0 || len(bytes) != 3+m*crypto.Ed25519KeySize {
+		return errors.New("invalid mlsag multisig round 2 message size")
+	}
+	s := make([]*crypto.Scalar, m)
+	for j := range s {
+		var err error
+		s[j], err = new(crypto.Scalar).FromBytes(bytes[3+j*crypto.Ed25519KeySize : 3+(j+1)*crypto.Ed25519KeySize])
+		if err != nil {
+			return err
+		}
+	}
+	msg.identifier = id
+	msg.s = s
+	return nil
+}
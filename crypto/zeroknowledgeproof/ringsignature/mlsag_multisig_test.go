@@ -0,0 +1,210 @@
+package ringsignature
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMultisigRing returns the private keys of a shared address and a ring with the address at index
+func newMultisigRing(m int, index int) ([]*crypto.Scalar, [][]*crypto.Point) {
+	privateKey := make([]*crypto.Scalar, m)
+	publicKey := make([][]*crypto.Point, RingSize)
+	for i := range publicKey {
+		publicKey[i] = make([]*crypto.Point, m)
+		for j := 0; j < m; j++ {
+			publicKey[i][j] = crypto.RandomPoint()
+		}
+	}
+	for j := 0; j < m; j++ {
+		privateKey[j] = crypto.RandomScalar()
+		publicKey[index][j] = new(crypto.Point).ScalarMultBase(privateKey[j])
+	}
+	return privateKey, publicKey
+}
+
+// runMultisig signs with the keys of the signers, passing every message through its bytes
+func runMultisig(t *testing.T, coord *Mlsag_MultisigCoordinator, signers []*Mlsag_MultisigKey, dsCols int, publicKey [][]*crypto.Point, index int, message *crypto.Point) (*Mlsag_MultisigSigningPackage, []*Mlsag_MultisigRound2) {
+	sessions := make([]*Mlsag_MultisigSession, len(signers))
+	round1 := make([]*Mlsag_MultisigRound1, len(signers))
+	for k, key := range signers {
+		var err error
+		sessions[k], err = NewMlsag_MultisigSession(key, dsCols, nil)
+		assert.Equal(t, nil, err)
+		round1[k] = new(Mlsag_MultisigRound1)
+		assert.Equal(t, nil, round1[k].SetBytes(sessions[k].Round1().Bytes()))
+	}
+
+	pkg, err := coord.NewSigningPackage(round1, publicKey, index, message, nil)
+	assert.Equal(t, nil, err)
+	received := new(Mlsag_MultisigSigningPackage)
+	assert.Equal(t, nil, received.SetBytes(pkg.Bytes()))
+	assert.Equal(t, pkg.Bytes(), received.Bytes())
+
+	round2 := make([]*Mlsag_MultisigRound2, len(signers))
+	for k, session := range sessions {
+		msg, err := session.Sign(received)
+		assert.Equal(t, nil, err)
+		round2[k] = new(Mlsag_MultisigRound2)
+		assert.Equal(t, nil, round2[k].SetBytes(msg.Bytes()))
+	}
+	return pkg, round2
+}
+
+func TestMlsagMultisig_2of2(t *testing.T) {
+	index := 3
+	privateKey, publicKey := newMultisigRing(2, index)
+	keys, public, err := Mlsag_MultisigKeygen(privateKey, 2, 2, nil)
+	assert.Equal(t, nil, err)
+
+	coord := NewMlsag_MultisigCoordinator(public)
+	message := crypto.RandomPoint()
+	pkg, round2 := runMultisig(t, coord, keys, 1, publicKey, index, message)
+	proof, err := coord.Combine(pkg, round2)
+	assert.Equal(t, nil, err)
+
+	res, err := proof.Mlsag_Verify()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	// the key image is the one of a single signer with the full private key
+	Hp := crypto.HashToPoint(publicKey[index][0].ToBytes())
+	assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMult(Hp, privateKey[0]), proof.keyImage[0]))
+
+	// one signer alone cannot sign
+	session, _ := NewMlsag_MultisigSession(keys[0], 1, nil)
+	_, err = coord.NewSigningPackage([]*Mlsag_MultisigRound1{session.Round1()}, publicKey, index, message, nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMlsagMultisig_2of3(t *testing.T) {
+	for index := 0; index < RingSize; index += 3 {
+		privateKey, publicKey := newMultisigRing(3, index)
+		keys, public, err := Mlsag_MultisigKeygen(privateKey, 2, 3, nil)
+		assert.Equal(t, nil, err)
+		coord := NewMlsag_MultisigCoordinator(public)
+
+		// every pair of parties and all three together sign with the same key images
+		Hp := crypto.HashToPoint(publicKey[index][0].ToBytes())
+		keyImage := new(crypto.Point).ScalarMult(Hp, privateKey[0])
+		for _, subset := range [][]int{{0, 1}, {0, 2}, {2, 1}, {0, 1, 2}} {
+			signers := make([]*Mlsag_MultisigKey, len(subset))
+			for i, k := range subset {
+				signers[i] = keys[k]
+			}
+			pkg, round2 := runMultisig(t, coord, signers, 1, publicKey, index, crypto.RandomPoint())
+			proof, err := coord.Combine(pkg, round2)
+			assert.Equal(t, nil, err)
+
+			res, err := proof.Mlsag_Verify()
+			assert.Equal(t, nil, err)
+			assert.Equal(t, true, res)
+			assert.Equal(t, true, crypto.IsPointEqual(keyImage, proof.keyImage[0]))
+		}
+	}
+}
+
+func TestMlsagMultisig_BindingFactors(t *testing.T) {
+	index := 2
+	privateKey, publicKey := newMultisigRing(2, index)
+	keys, public, err := Mlsag_MultisigKeygen(privateKey, 3, 3, nil)
+	assert.Equal(t, nil, err)
+	coord := NewMlsag_MultisigCoordinator(public)
+
+	round1 := make([]*Mlsag_MultisigRound1, len(keys))
+	for k, key := range keys {
+		session, err := NewMlsag_MultisigSession(key, 1, nil)
+		assert.Equal(t, nil, err)
+		round1[k] = session.Round1()
+	}
+	pkg, err := coord.NewSigningPackage(round1, publicKey, index, crypto.RandomPoint(), nil)
+	assert.Equal(t, nil, err)
+	st, err := pkg.state(public)
+	assert.Equal(t, nil, err)
+
+	// a coordinator changing one decoy response changes every binding factor
+	for _, i := range []int{0, index + 1, RingSize - 1} {
+		tampered := *pkg
+		tampered.r = append([][]*crypto.Scalar{}, pkg.r...)
+		tampered.r[i] = append([]*crypto.Scalar{}, pkg.r[i]...)
+		tampered.r[i][1] = crypto.RandomScalar()
+		st2, err := tampered.state(public)
+		assert.Equal(t, nil, err)
+		for id, rho := range st.rho {
+			assert.NotEqual(t, 0, crypto.CompareScalar(rho, st2.rho[id]), "row %d party %d", i, id)
+		}
+	}
+}
+
+func TestMlsagMultisig_InvalidShares(t *testing.T) {
+	index := 6
+	privateKey, publicKey := newMultisigRing(2, index)
+	keys, public, _ := Mlsag_MultisigKeygen(privateKey, 2, 3, nil)
+	coord := NewMlsag_MultisigCoordinator(public)
+	pkg, round2 := runMultisig(t, coord, keys[1:], 2, publicKey, index, crypto.RandomPoint())
+
+	// a wrong response names its party
+	bad := &Mlsag_MultisigRound2{identifier: round2[1].identifier, s: []*crypto.Scalar{round2[1].s[0], crypto.RandomScalar()}}
+	_, err := coord.Combine(pkg, []*Mlsag_MultisigRound2{round2[0], bad})
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "party 3")
+
+	// missing and duplicated responses
+	_, err = coord.Combine(pkg, round2[:1])
+	assert.NotEqual(t, nil, err)
+	_, err = coord.Combine(pkg, []*Mlsag_MultisigRound2{round2[0], round2[0]})
+	assert.NotEqual(t, nil, err)
+
+	// a partial key image which does not match the verifying share names its party
+	session, _ := NewMlsag_MultisigSession(keys[0], 1, nil)
+	forged := *session.Round1()
+	other, _ := NewMlsag_MultisigSession(keys[2], 1, nil)
+	forged.keyImageDLEQ = other.Round1().keyImageDLEQ
+	session2, _ := NewMlsag_MultisigSession(keys[1], 1, nil)
+	_, err = coord.NewSigningPackage([]*Mlsag_MultisigRound1{&forged, session2.Round1()}, publicKey, index, crypto.RandomPoint(), nil)
+	assert.NotEqual(t, nil, err)
+	assert.Contains(t, err.Error(), "party 1")
+
+	// the signing row must be the shared address
+	_, otherRing := newMultisigRing(2, index)
+	_, err = coord.NewSigningPackage([]*Mlsag_MultisigRound1{session.Round1(), session2.Round1()}, otherRing, index, crypto.RandomPoint(), nil)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMlsagMultisig_NonceReuse(t *testing.T) {
+	index := 1
+	privateKey, publicKey := newMultisigRing(2, index)
+	keys, public, _ := Mlsag_MultisigKeygen(privateKey, 2, 2, nil)
+	coord := NewMlsag_MultisigCoordinator(public)
+
+	sessions := make([]*Mlsag_MultisigSession, 2)
+	round1 := make([]*Mlsag_MultisigRound1, 2)
+	for k := range keys {
+		sessions[k], _ = NewMlsag_MultisigSession(keys[k], 1, nil)
+		round1[k] = sessions[k].Round1()
+	}
+	pkg1, err := coord.NewSigningPackage(round1, publicKey, index, crypto.RandomPoint(), nil)
+	assert.Equal(t, nil, err)
+	pkg2, err := coord.NewSigningPackage(round1, publicKey, index, crypto.RandomPoint(), nil)
+	assert.Equal(t, nil, err)
+
+	_, err = sessions[0].Sign(pkg1)
+	assert.Equal(t, nil, err)
+	_, err = sessions[0].Sign(pkg2)
+	assert.NotEqual(t, nil, err)
+
+	// a copy of the session shares the erased nonces
+	copied := *sessions[1]
+	_, err = sessions[1].Sign(pkg2)
+	assert.Equal(t, nil, err)
+	_, err = copied.Sign(pkg1)
+	assert.NotEqual(t, nil, err)
+}
+
+func TestMlsagMultisig_Bytes(t *testing.T) {
+	assert.NotEqual(t, nil, new(Mlsag_MultisigRound1).SetBytes([]byte{0, 1, 2}))
+	assert.NotEqual(t, nil, new(Mlsag_MultisigRound1).SetBytes([]byte{0, 1, 2, 1}))
+	assert.NotEqual(t, nil, new(Mlsag_MultisigRound2).SetBytes([]byte{0, 1, 1}))
+	assert.NotEqual(t, nil, new(Mlsag_MultisigSigningPackage).SetBytes(make([]byte, 40)))
+}
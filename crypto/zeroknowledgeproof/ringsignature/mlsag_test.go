@@ -1,9 +1,12 @@
 package ringsignature
 
 import (
+	"crypto/rand"
+	"errors"
 	"github.com/incognitochain/incognito-chain-privacy/crypto"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"testing/iotest"
 )
 
 func TestMlsag2(t *testing.T) {
@@ -34,6 +37,138 @@ func TestMlsag2(t *testing.T) {
 	resVerify, err := proof.Mlsag_Verify()
 	assert.Equal(t, nil, err)
 	assert.Equal(t, true, resVerify)
+
+	// verifying does not modify the proof
+	c0 := new(crypto.Scalar).Set(proof.c0)
+	proof.Mlsag_Verify()
+	assert.Equal(t, c0, proof.c0)
+
+	// the key image is x * HashToPoint(P)
+	Hp := crypto.HashToPoint(wit.publicKey[wit.index][0].ToBytes())
+	assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMult(Hp, wit.privateKey[0]), proof.keyImage[0]))
+
+	// tampered proofs are rejected
+	r := proof.r[0][1]
+	proof.r[0][1] = crypto.RandomScalar()
+	resVerify, _ = proof.Mlsag_Verify()
+	assert.Equal(t, false, resVerify)
+	proof.r[0][1] = r
+
+	keyImage := proof.keyImage[0]
+	proof.keyImage[0] = crypto.RandomPoint()
+	resVerify, _ = proof.Mlsag_Verify()
+	assert.Equal(t, false, resVerify)
+	proof.keyImage[0] = keyImage
+
+	message := proof.message
+	proof.message = crypto.RandomPoint()
+	resVerify, _ = proof.Mlsag_Verify()
+	assert.Equal(t, false, resVerify)
+	proof.message = message
+
+	resVerify, _ = proof.Mlsag_Verify()
+	assert.Equal(t, true, resVerify)
+}
+
+func TestMlsag_AllIndexes(t *testing.T) {
+	for index := 0; index < RingSize; index++ {
+		wit := new(Mlsag_Witness)
+		m := 3
+		wit.message = crypto.RandomPoint()
+		wit.index = index
+		wit.dsCols = 2
+
+		wit.publicKey = make([][]*crypto.Point, RingSize)
+		for i := range wit.publicKey {
+			wit.publicKey[i] = make([]*crypto.Point, m)
+			for j := 0; j < m; j++ {
+				wit.publicKey[i][j] = crypto.RandomPoint()
+			}
+		}
+		wit.privateKey = make([]*crypto.Scalar, m)
+		for j := 0; j < m; j++ {
+			wit.privateKey[j] = crypto.RandomScalar()
+			wit.publicKey[wit.index][j] = new(crypto.Point).ScalarMultBase(wit.privateKey[j])
+		}
+
+		proof, err := wit.Mlsag_Prove()
+		assert.Equal(t, nil, err)
+		resVerify, err := proof.Mlsag_Verify()
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, resVerify)
+
+		// a private key of another row does not sign
+		wit.privateKey[1] = crypto.RandomScalar()
+		proof, _ = wit.Mlsag_Prove()
+		resVerify, _ = proof.Mlsag_Verify()
+		assert.Equal(t, false, resVerify)
+	}
+}
+
+// TestMlsag_Forged checks that a ring signature made without any private key of the ring is rejected
+func TestMlsag_Forged(t *testing.T) {
+	m := 2
+	for try := 0; try < 16; try++ {
+		proof := Mlsag_Proof{
+			c0:       crypto.RandomScalar(),
+			keyImage: []*crypto.Point{crypto.RandomPoint()},
+			dsCols:   1,
+			message:  crypto.RandomPoint(),
+		}
+		proof.publicKey = make([][]*crypto.Point, RingSize)
+		proof.r = make([][]*crypto.Scalar, RingSize)
+		for i := 0; i < RingSize; i++ {
+			proof.publicKey[i] = make([]*crypto.Point, m)
+			proof.r[i] = make([]*crypto.Scalar, m)
+			for j := 0; j < m; j++ {
+				proof.publicKey[i][j] = crypto.RandomPoint()
+				proof.r[i][j] = crypto.RandomScalar()
+			}
+		}
+		resVerify, _ := proof.Mlsag_Verify()
+		assert.Equal(t, false, resVerify)
+	}
+}
+
+func TestMlsag_Deterministic(t *testing.T) {
+	wit := new(Mlsag_Witness)
+	m := 2
+	n := RingSize
+	wit.message = crypto.RandomPoint()
+	wit.index = 5
+	wit.dsCols = 1
+
+	wit.publicKey = make([][]*crypto.Point, n)
+	for i := 0; i < n; i++ {
+		wit.publicKey[i] = make([]*crypto.Point, m)
+		for j := 0; j < m; j++ {
+			wit.publicKey[i][j] = crypto.RandomPoint()
+		}
+	}
+	wit.privateKey = make([]*crypto.Scalar, m)
+	for j := 0; j < m; j++ {
+		wit.privateKey[j] = crypto.RandomScalar()
+		wit.publicKey[wit.index][j] = new(crypto.Point).ScalarMultBase(wit.privateKey[j])
+	}
+
+	rng1, err := wit.NonceReader(nil)
+	assert.Equal(t, nil, err)
+	proof1, err := wit.Mlsag_ProveWithRand(rng1)
+	assert.Equal(t, nil, err)
+	rng2, _ := wit.NonceReader(nil)
+	proof2, err := wit.Mlsag_ProveWithRand(rng2)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, proof1.c0, proof2.c0)
+	assert.Equal(t, proof1.r, proof2.r)
+
+	rng3, _ := wit.NonceReader(rand.Reader)
+	proof3, err := wit.Mlsag_ProveWithRand(rng3)
+	assert.Equal(t, nil, err)
+	assert.NotEqual(t, proof1.r, proof3.r)
+
+	rngErr := errors.New("rng failure")
+	_, err = wit.Mlsag_ProveWithRand(iotest.ErrReader(rngErr))
+	assert.Equal(t, rngErr, err)
 }
 
 func benchmarkMlsag_Prove(b *testing.B, mParam int) {
@@ -0,0 +1,105 @@
+package schnorr
+
+import (
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Schnorr adaptor signature for the adaptor point T = t*G, as used by atomic swaps.
+// Pre-signing:      R = k*G + T, e = H(R || P || m), s' = k + e*x, the pre-signature is R || s'
+// Pre-verification: 8*(s'*G - e*P - (R - T)) == identity
+// Adapt:            whoever knows t makes the signature (R, s' + t)
+// Extract:          from the pre-signature and the published signature, t = s - s'
+
+type PreSignature struct {
+	r *crypto.Point
+	s *crypto.Scalar
+}
+
+// PreSign makes a pre-signature of message for the adaptor point, the nonce is deterministic as in Sign
+func (priv PrivateKey) PreSign(message []byte, adaptor *crypto.Point) (*PreSignature, error) {
+	return priv.PreSignWithRand(message, adaptor, nil)
+}
+
+// PreSignWithRand makes a pre-signature of message for the adaptor point, mixing entropy from rng into the nonce
+func (priv PrivateKey) PreSignWithRand(message []byte, adaptor *crypto.Point, rng io.Reader) (*PreSignature, error) {
+	if adaptor == nil || !adaptor.InPrimeOrderSubgroup() {
+		return nil, errors.New("PreSign invalid adaptor point")
+	}
+	nonces, err := crypto.NewNonceReader(priv.sk.ToBytes(), rng, []byte(cStringSchnorr), priv.pk.ToBytes(), adaptor.ToBytes(), message)
+	if err != nil {
+		return nil, err
+	}
+	k, err := crypto.RandomScalarFrom(nonces)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(crypto.Point).ScalarMultBase(k)
+	r.Add(r, adaptor)
+	e := Challenge(r, priv.pk, message)
+	s := new(crypto.Scalar).MulAdd(e, priv.sk, k)
+
+	return &PreSignature{r: r, s: s}, nil
+}
+
+// PreVerify checks that the pre-signature becomes a valid signature of message by pk
+// once adapted with the discrete log of the adaptor point
+func PreVerify(pk *crypto.Point, message []byte, adaptor *crypto.Point, presig *PreSignature) bool {
+	if pk == nil || adaptor == nil || presig == nil || presig.r == nil || presig.s == nil {
+		return false
+	}
+	if !presig.s.ScalarValid() || !pk.PointValid() {
+		return false
+	}
+	// a component of small order in T or R would be cleared by the cofactor below,
+	// so the adapted signature would not give t*G == T
+	if !adaptor.InPrimeOrderSubgroup() || !presig.r.InPrimeOrderSubgroup() {
+		return false
+	}
+
+	// s'*G - e*P - (R - T)
+	e := Challenge(presig.r, pk, message)
+	eNeg := new(crypto.Scalar).Sub(new(crypto.Scalar).FromUint64(0), e)
+	res := new(crypto.Point).DoubleScalarMultBaseVartime(presig.s, pk, eNeg)
+	res.Sub(res, presig.r)
+	res.Add(res, adaptor)
+
+	return new(crypto.Point).MulCofactor(res).IsIdentity()
+}
+
+// Adapt completes the pre-signature with the adaptor secret t
+func (presig PreSignature) Adapt(t *crypto.Scalar) *Signature {
+	return NewSignature(presig.r, new(crypto.Scalar).Add(presig.s, t))
+}
+
+// Extract returns the adaptor secret t from the pre-signature and the signature made by Adapt
+func (presig PreSignature) Extract(sig *Signature, adaptor *crypto.Point) (*crypto.Scalar, error) {
+	if sig == nil || sig.isNil() || adaptor == nil {
+		return nil, errors.New("Extract input is nil")
+	}
+	if !crypto.IsPointEqual(sig.r, presig.r) {
+		return nil, errors.New("Extract signature is not adapted from the pre-signature")
+	}
+	t := new(crypto.Scalar).Sub(sig.s, presig.s)
+	if !crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(t), adaptor) {
+		return nil, errors.New("Extract secret does not match the adaptor point")
+	}
+	return t, nil
+}
+
+func (presig PreSignature) Bytes() []byte {
+	return Signature{r: presig.r, s: presig.s}.Bytes()
+}
+
+func (presig *PreSignature) SetBytes(bytes []byte) error {
+	sig := new(Signature)
+	if err := sig.SetBytes(bytes); err != nil {
+		return err
+	}
+	presig.r = sig.r
+	presig.s = sig.s
+	return nil
+}
@@ -0,0 +1,98 @@
+package schnorr
+
+import (
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	C25519 "github.com/incognitochain/incognito-chain-privacy/crypto/curve25519"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdaptor_Swap(t *testing.T) {
+	priv := NewPrivateKey(crypto.RandomScalar())
+	message := []byte("swap output")
+
+	// the counterparty knows t, the signer only T
+	secret := crypto.RandomScalar()
+	adaptor := new(crypto.Point).ScalarMultBase(secret)
+
+	presig, err := priv.PreSign(message, adaptor)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, PreVerify(priv.PublicKey(), message, adaptor, presig))
+
+	// the pre-signature is not a valid signature
+	assert.Equal(t, false, Verify(priv.PublicKey(), message, NewSignature(presig.r, presig.s)))
+
+	// wrong statements
+	assert.Equal(t, false, PreVerify(priv.PublicKey(), []byte("other"), adaptor, presig))
+	assert.Equal(t, false, PreVerify(priv.PublicKey(), message, crypto.RandomPoint(), presig))
+	assert.Equal(t, false, PreVerify(crypto.RandomPoint(), message, adaptor, presig))
+
+	// the counterparty adapts and publishes the signature
+	sig := presig.Adapt(secret)
+	assert.Equal(t, true, Verify(priv.PublicKey(), message, sig))
+
+	// the signer learns t
+	extracted, err := presig.Extract(sig, adaptor)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 0, crypto.CompareScalar(secret, extracted))
+
+	// a signature not adapted from the pre-signature gives nothing
+	other, _ := priv.Sign(message)
+	_, err = presig.Extract(other, adaptor)
+	assert.NotEqual(t, nil, err)
+	_, err = presig.Extract(presig.Adapt(crypto.RandomScalar()), adaptor)
+	assert.NotEqual(t, nil, err)
+}
+
+// smallOrderPoint returns a point of order 8
+func smallOrderPoint() *crypto.Point {
+	key := C25519.HexToKey("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	p, _ := new(crypto.Point).SetKey(&key)
+	return p
+}
+
+func TestAdaptor_Torsion(t *testing.T) {
+	priv := NewPrivateKey(crypto.RandomScalar())
+	message := []byte("swap output")
+	secret := crypto.RandomScalar()
+	adaptor := new(crypto.Point).ScalarMultBase(secret)
+	torsioned := new(crypto.Point).Add(adaptor, smallOrderPoint())
+
+	_, err := priv.PreSign(message, torsioned)
+	assert.NotEqual(t, nil, err)
+
+	// a pre-signature for T + E passes the cofactor equation, but the secret of the adapted
+	// signature would not match T + E, so it is rejected
+	k := crypto.RandomScalar()
+	r := new(crypto.Point).Add(new(crypto.Point).ScalarMultBase(k), torsioned)
+	s := new(crypto.Scalar).MulAdd(Challenge(r, priv.PublicKey(), message), priv.sk, k)
+	presig := &PreSignature{r: r, s: s}
+	assert.Equal(t, false, PreVerify(priv.PublicKey(), message, torsioned, presig))
+
+	// and so is a torsioned R with a clean T
+	presig, _ = priv.PreSign(message, adaptor)
+	assert.Equal(t, true, PreVerify(priv.PublicKey(), message, adaptor, presig))
+	presig.r = new(crypto.Point).Add(presig.r, smallOrderPoint())
+	assert.Equal(t, false, PreVerify(priv.PublicKey(), message, adaptor, presig))
+	assert.Equal(t, false, PreVerify(invalidPoint(), message, adaptor, presig))
+}
+
+func TestAdaptor_Bytes(t *testing.T) {
+	priv := NewPrivateKey(crypto.RandomScalar())
+	adaptor := crypto.RandomPoint()
+	presig, err := priv.PreSign([]byte("message"), adaptor)
+	assert.Equal(t, nil, err)
+
+	// pre-signing is deterministic
+	presig2, _ := priv.PreSign([]byte("message"), adaptor)
+	assert.Equal(t, presig.Bytes(), presig2.Bytes())
+
+	decoded := new(PreSignature)
+	assert.Equal(t, nil, decoded.SetBytes(presig.Bytes()))
+	assert.Equal(t, true, PreVerify(priv.PublicKey(), []byte("message"), adaptor, decoded))
+	assert.NotEqual(t, nil, decoded.SetBytes(presig.Bytes()[1:]))
+
+	_, err = priv.PreSign([]byte("message"), nil)
+	assert.NotEqual(t, nil, err)
+}
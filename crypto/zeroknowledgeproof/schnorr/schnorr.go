@@ -0,0 +1,181 @@
+package schnorr
+
+import (
+	"errors"
+	"io"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// Schnorr signature over the curve basepoint G
+// The private key is a scalar x and the public key is P = x*G, the same as a spend key
+// Signing:      R = k*G, e = H(R || P || m), s = k + e*x, the signature is R || s
+// Verification: 8*(s*G - e*P - R) == identity
+// The cofactored equation is used both by Verify and BatchVerify,
+// so that a signature accepted by one is accepted by the other
+
+const SignatureSize = 2 * crypto.Ed25519KeySize
+
+const cStringSchnorr = "schnorr"
+
+type PrivateKey struct {
+	sk *crypto.Scalar
+	pk *crypto.Point
+}
+
+type Signature struct {
+	r *crypto.Point
+	s *crypto.Scalar
+}
+
+func NewPrivateKey(sk *crypto.Scalar) *PrivateKey {
+	return &PrivateKey{
+		sk: new(crypto.Scalar).Set(sk),
+		pk: new(crypto.Point).ScalarMultBase(sk),
+	}
+}
+
+func (priv PrivateKey) PublicKey() *crypto.Point {
+	return new(crypto.Point).Set(priv.pk)
+}
+
+// Challenge returns e = H(R || P || m). The multi-party signers use it to build
+// signatures which verify as ordinary schnorr signatures under the aggregated key
+func Challenge(r *crypto.Point, pk *crypto.Point, message []byte) *crypto.Scalar {
+	msg := []byte(cStringSchnorr)
+	msg = crypto.AppendPointsToBytesArray(msg, []*crypto.Point{r, pk})
+	msg = append(msg, message...)
+	return crypto.HashToScalar(msg)
+}
+
+// Sign signs the message with a nonce derived from the private key and the message,
+// so the same message always gets the same signature
+func (priv PrivateKey) Sign(message []byte) (*Signature, error) {
+	return priv.SignWithRand(message, nil)
+}
+
+// SignWithRand signs the message with a nonce derived from the private key, the message
+// and entropy read from rng. The nonce stays secret if either rng or the private key is good
+func (priv PrivateKey) SignWithRand(message []byte, rng io.Reader) (*Signature, error) {
+	nonces, err := crypto.NewNonceReader(priv.sk.ToBytes(), rng, []byte(cStringSchnorr), priv.pk.ToBytes(), message)
+	if err != nil {
+		return nil, err
+	}
+	k, err := crypto.RandomScalarFrom(nonces)
+	if err != nil {
+		return nil, err
+	}
+
+	r := new(crypto.Point).ScalarMultBase(k)
+	e := Challenge(r, priv.pk, message)
+	s := new(crypto.Scalar).Mul(e, priv.sk)
+	s.Add(s, k)
+
+	return &Signature{r: r, s: s}, nil
+}
+
+// NewSignature returns the signature (r, s)
+func NewSignature(r *crypto.Point, s *crypto.Scalar) *Signature {
+	return &Signature{r: new(crypto.Point).Set(r), s: new(crypto.Scalar).Set(s)}
+}
+
+func (sig Signature) Bytes() []byte {
+	res := make([]byte, 0, SignatureSize)
+	res = append(res, sig.r.ToBytes()...)
+	res = append(res, sig.s.ToBytes()...)
+	return res
+}
+
+func (sig *Signature) SetBytes(bytes []byte) error {
+	if len(bytes) != SignatureSize {
+		return errors.New("invalid schnorr signature size")
+	}
+	r, err := new(crypto.Point).FromBytes(bytes[:crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	s, err := new(crypto.Scalar).FromBytes(bytes[crypto.Ed25519KeySize:])
+	if err != nil {
+		return err
+	}
+	sig.r = r
+	sig.s = s
+	return nil
+}
+
+func (sig Signature) isNil() bool {
+	return sig.r == nil || sig.s == nil
+}
+
+// Verify checks the signature of message by the public key pk
+func Verify(pk *crypto.Point, message []byte, sig *Signature) bool {
+	if pk == nil || sig == nil || sig.isNil() {
+		return false
+	}
+	if !sig.s.ScalarValid() || !pk.PointValid() || !sig.r.PointValid() {
+		return false
+	}
+
+	// s*G - e*P
+	e := Challenge(sig.r, pk, message)
+	eNeg := new(crypto.Scalar).Sub(new(crypto.Scalar).FromUint64(0), e)
+	res := new(crypto.Point).DoubleScalarMultBaseVartime(sig.s, pk, eNeg)
+	res.Sub(res, sig.r)
+
+	return new(crypto.Point).MulCofactor(res).IsIdentity()
+}
+
+// BatchVerify checks all the signatures at once, which is much faster than verifying them one by one.
+// The equations are combined with random weights z_i, checking
+// 8*((sum z_i*s_i)*G - sum z_i*R_i - sum (z_i*e_i)*P_i) == identity.
+// It returns false if any signature is invalid, without telling which one
+func BatchVerify(pks []*crypto.Point, messages [][]byte, sigs []*Signature) (bool, error) {
+	n := len(sigs)
+	if len(pks) != n || len(messages) != n {
+		return false, errors.New("BatchVerify number of public keys, messages and signatures must be equal")
+	}
+	if n == 0 {
+		return true, nil
+	}
+
+	scalars := make([]*crypto.Scalar, 0, 2*n+1)
+	points := make([]*crypto.Point, 0, 2*n+1)
+	sSum := new(crypto.Scalar).FromUint64(0)
+	zero := new(crypto.Scalar).FromUint64(0)
+
+	for i := 0; i < n; i++ {
+		if pks[i] == nil || sigs[i] == nil || sigs[i].isNil() {
+			return false, errors.New("BatchVerify input is nil")
+		}
+		if !sigs[i].s.ScalarValid() || !pks[i].PointValid() || !sigs[i].r.PointValid() {
+			return false, nil
+		}
+
+		// 128-bit weights are enough for a soundness error of 2^-128
+		var z *crypto.Scalar
+		if i == 0 {
+			z = new(crypto.Scalar).FromUint64(1)
+		} else {
+			weight := make([]byte, crypto.Ed25519KeySize)
+			copy(weight, crypto.RandBytes(16))
+			z, _ = new(crypto.Scalar).FromBytes(weight)
+		}
+
+		e := Challenge(sigs[i].r, pks[i], messages[i])
+		sSum.Add(sSum, new(crypto.Scalar).Mul(z, sigs[i].s))
+
+		scalars = append(scalars, new(crypto.Scalar).Sub(zero, z))
+		points = append(points, sigs[i].r)
+		scalars = append(scalars, new(crypto.Scalar).Sub(zero, new(crypto.Scalar).Mul(z, e)))
+		points = append(points, pks[i])
+	}
+
+	scalars = append(scalars, sSum)
+	points = append(points, crypto.G)
+
+	res, err := new(crypto.Point).CheckedMultiScalarMult(scalars, points)
+	if err != nil {
+		return false, err
+	}
+	return new(crypto.Point).MulCofactor(res).IsIdentity(), nil
+}
@@ -0,0 +1,168 @@
+package schnorr
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchnorr_SignVerify(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		// a spend key pair is used directly
+		sk := crypto.RandomScalar()
+		priv := NewPrivateKey(sk)
+		assert.Equal(t, true, crypto.IsPointEqual(new(crypto.Point).ScalarMultBase(sk), priv.PublicKey()))
+
+		message := crypto.RandBytes(100)
+		sig, err := priv.Sign(message)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, Verify(priv.PublicKey(), message, sig))
+
+		// deterministic nonces
+		sig2, _ := priv.Sign(message)
+		assert.Equal(t, sig.Bytes(), sig2.Bytes())
+
+		// hedged nonces
+		sig3, err := priv.SignWithRand(message, rand.Reader)
+		assert.Equal(t, nil, err)
+		assert.NotEqual(t, sig.Bytes(), sig3.Bytes())
+		assert.Equal(t, true, Verify(priv.PublicKey(), message, sig3))
+
+		// wrong message, key or signature
+		assert.Equal(t, false, Verify(priv.PublicKey(), crypto.RandBytes(100), sig))
+		assert.Equal(t, false, Verify(crypto.RandomPoint(), message, sig))
+		wrong := &Signature{r: sig.r, s: crypto.RandomScalar()}
+		assert.Equal(t, false, Verify(priv.PublicKey(), message, wrong))
+		assert.Equal(t, false, Verify(priv.PublicKey(), message, &Signature{}))
+	}
+}
+
+func TestSchnorr_Bytes(t *testing.T) {
+	priv := NewPrivateKey(crypto.RandomScalar())
+	message := []byte("transaction metadata")
+	sig, err := priv.Sign(message)
+	assert.Equal(t, nil, err)
+
+	bytes := sig.Bytes()
+	assert.Equal(t, SignatureSize, len(bytes))
+
+	sig2 := new(Signature)
+	assert.Equal(t, nil, sig2.SetBytes(bytes))
+	assert.Equal(t, true, Verify(priv.PublicKey(), message, sig2))
+
+	assert.NotEqual(t, nil, sig2.SetBytes(bytes[:SignatureSize-1]))
+	// s not reduced
+	invalid := append([]byte{}, bytes...)
+	for i := crypto.Ed25519KeySize; i < SignatureSize; i++ {
+		invalid[i] = 0xff
+	}
+	assert.NotEqual(t, nil, sig2.SetBytes(invalid))
+}
+
+func TestSchnorr_BatchVerify(t *testing.T) {
+	n := 16
+	pks := make([]*crypto.Point, n)
+	messages := make([][]byte, n)
+	sigs := make([]*Signature, n)
+	for i := 0; i < n; i++ {
+		priv := NewPrivateKey(crypto.RandomScalar())
+		pks[i] = priv.PublicKey()
+		messages[i] = []byte(fmt.Sprintf("message %v", i))
+		sigs[i], _ = priv.Sign(messages[i])
+	}
+
+	res, err := BatchVerify(pks, messages, sigs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	res, err = BatchVerify(nil, nil, nil)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	_, err = BatchVerify(pks[1:], messages, sigs)
+	assert.NotEqual(t, nil, err)
+
+	// one wrong message makes the whole batch fail
+	messages[5] = []byte("another message")
+	res, err = BatchVerify(pks, messages, sigs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, res)
+	messages[5] = []byte("message 5")
+
+	// two signatures whose errors cancel in an unweighted sum are rejected
+	delta := crypto.RandomScalar()
+	sigs[1] = &Signature{r: sigs[1].r, s: new(crypto.Scalar).Add(sigs[1].s, delta)}
+	sigs[2] = &Signature{r: sigs[2].r, s: new(crypto.Scalar).Sub(sigs[2].s, delta)}
+	res, err = BatchVerify(pks, messages, sigs)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, res)
+}
+
+// invalidPoint returns a point that does not decode, as UnmarshalText accepts it
+func invalidPoint() *crypto.Point {
+	for i := 2; ; i++ {
+		p, _ := new(crypto.Point).UnmarshalText([]byte(fmt.Sprintf("%02x%062x", i, 0)))
+		if !p.PointValid() {
+			return p
+		}
+	}
+}
+
+func TestSchnorr_BatchVerifyInvalidPoint(t *testing.T) {
+	n := 4
+	pks := make([]*crypto.Point, n)
+	messages := make([][]byte, n)
+	sigs := make([]*Signature, n)
+	for i := 0; i < n; i++ {
+		priv := NewPrivateKey(crypto.RandomScalar())
+		pks[i] = priv.PublicKey()
+		messages[i] = []byte(fmt.Sprintf("message %v", i))
+		sigs[i], _ = priv.Sign(messages[i])
+	}
+
+	// points that do not decode are rejected instead of panicking
+	pk := pks[3]
+	pks[3] = invalidPoint()
+	res, _ := BatchVerify(pks, messages, sigs)
+	assert.Equal(t, false, res)
+	assert.Equal(t, false, Verify(pks[3], messages[3], sigs[3]))
+	pks[3] = pk
+
+	sigs[2] = &Signature{r: invalidPoint(), s: sigs[2].s}
+	res, _ = BatchVerify(pks, messages, sigs)
+	assert.Equal(t, false, res)
+	assert.Equal(t, false, Verify(pks[2], messages[2], sigs[2]))
+}
+
+func BenchmarkSchnorr_Verify(b *testing.B) {
+	priv := NewPrivateKey(crypto.RandomScalar())
+	message := []byte("message")
+	sig, _ := priv.Sign(message)
+	pk := priv.PublicKey()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pk, message, sig)
+	}
+}
+
+func BenchmarkSchnorr_BatchVerify64(b *testing.B) {
+	n := 64
+	pks := make([]*crypto.Point, n)
+	messages := make([][]byte, n)
+	sigs := make([]*Signature, n)
+	for i := 0; i < n; i++ {
+		priv := NewPrivateKey(crypto.RandomScalar())
+		pks[i] = priv.PublicKey()
+		messages[i] = []byte(fmt.Sprintf("message %v", i))
+		sigs[i], _ = priv.Sign(messages[i])
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BatchVerify(pks, messages, sigs)
+	}
+}
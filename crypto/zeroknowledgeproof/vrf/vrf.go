@@ -0,0 +1,194 @@
+package vrf
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+)
+
+// ECVRF of RFC 9381, suite ECVRF-EDWARDS25519-SHA512-ELL2
+// The secret scalar x and the nonce prefix are expanded from a 32 bytes seed as in ed25519, Y = x*G
+// Proving:      H = encode_to_curve(Y || alpha), Gamma = x*H, k = SHA512(prefix || H),
+//               c = challenge(Y, H, Gamma, k*G, k*H), s = k + c*x, the proof is Gamma || c || s
+// Verification: U = s*G - c*Y, V = s*H - c*Gamma, c == challenge(Y, H, Gamma, U, V)
+// Output:       beta = SHA512(suite || 0x03 || 8*Gamma || 0x00)
+// The challenge c is the first 16 bytes of SHA512(suite || 0x02 || Y || H || Gamma || U || V || 0x00)
+
+const (
+	SeedSize   = 32
+	ProofSize  = 80
+	OutputSize = sha512.Size
+
+	challengeSize = 16
+
+	suiteString        = 0x04
+	challengeSeparator = 0x02
+	outputSeparator    = 0x03
+	endSeparator       = 0x00
+)
+
+// encode_to_curve tag "ECVRF_" || h2c_suite_ID_string || suite_string
+var h2cDST = append([]byte("ECVRF_edwards25519_XMD:SHA-512_ELL2_NU_"), suiteString)
+
+type PrivateKey struct {
+	x      *crypto.Scalar
+	prefix []byte
+	pk     *crypto.Point
+}
+
+type Proof struct {
+	gamma *crypto.Point
+	c     *crypto.Scalar
+	s     *crypto.Scalar
+}
+
+// NewPrivateKey expands a 32 bytes seed as in RFC 8032, which is the secret key SK of RFC 9381
+func NewPrivateKey(seed []byte) (*PrivateKey, error) {
+	if len(seed) != SeedSize {
+		return nil, errors.New("NewPrivateKey invalid seed size")
+	}
+	digest := sha512.Sum512(seed)
+	digest[0] &= 248
+	digest[31] &= 127
+	digest[31] |= 64
+	x, err := new(crypto.Scalar).FromBytesModOrder(digest[:crypto.Ed25519KeySize])
+	if err != nil {
+		return nil, err
+	}
+	return &PrivateKey{
+		x:      x,
+		prefix: append([]byte{}, digest[crypto.Ed25519KeySize:]...),
+		pk:     new(crypto.Point).ScalarMultBase(x),
+	}, nil
+}
+
+// NewPrivateKeyFromScalar uses an existing scalar as the VRF secret, the nonce prefix is derived
+// from the scalar. Proofs verify as usual but do not match those of a seed based key
+func NewPrivateKeyFromScalar(x *crypto.Scalar) *PrivateKey {
+	digest := sha512.Sum512(append([]byte("ECVRF_nonce_prefix"), x.ToBytes()...))
+	return &PrivateKey{
+		x:      new(crypto.Scalar).Set(x),
+		prefix: digest[crypto.Ed25519KeySize:],
+		pk:     new(crypto.Point).ScalarMultBase(x),
+	}
+}
+
+func (priv PrivateKey) PublicKey() *crypto.Point {
+	return new(crypto.Point).Set(priv.pk)
+}
+
+// encodeToCurve returns H = encode_to_curve(Y || alpha)
+func encodeToCurve(pk *crypto.Point, alpha []byte) (*crypto.Point, error) {
+	return crypto.EncodeToCurve(append(pk.ToBytes(), alpha...), h2cDST)
+}
+
+// challenge returns the 16 bytes challenge of the points as a scalar
+func challenge(points ...*crypto.Point) *crypto.Scalar {
+	msg := []byte{suiteString, challengeSeparator}
+	msg = crypto.AppendPointsToBytesArray(msg, points)
+	msg = append(msg, endSeparator)
+	digest := sha512.Sum512(msg)
+	c, _ := new(crypto.Scalar).FromBytesModOrder(digest[:challengeSize])
+	return c
+}
+
+// Prove returns the proof for the input alpha, proofs are deterministic
+func (priv PrivateKey) Prove(alpha []byte) (*Proof, error) {
+	h, err := encodeToCurve(priv.pk, alpha)
+	if err != nil {
+		return nil, err
+	}
+	gamma := new(crypto.Point).ScalarMult(h, priv.x)
+
+	digest := sha512.Sum512(append(append([]byte{}, priv.prefix...), h.ToBytes()...))
+	k, _ := new(crypto.Scalar).FromBytesModOrder(digest[:])
+	kG := new(crypto.Point).ScalarMultBase(k)
+	kH := new(crypto.Point).ScalarMult(h, k)
+
+	c := challenge(priv.pk, h, gamma, kG, kH)
+	s := new(crypto.Scalar).MulAdd(c, priv.x, k)
+	return &Proof{gamma: gamma, c: c, s: s}, nil
+}
+
+// isCanonical checks that the point is in the unique encoding produced by the group operations
+func isCanonical(p *crypto.Point) bool {
+	encoded := new(crypto.Point).Add(p, new(crypto.Point).Identity())
+	return bytes.Equal(encoded.ToBytes(), p.ToBytes())
+}
+
+// Verify checks the proof for the input alpha under the public key pk.
+// Public keys of small order are rejected, as done by ECVRF_validate_key
+func (proof Proof) Verify(pk *crypto.Point, alpha []byte) (bool, error) {
+	if proof.gamma == nil || proof.c == nil || proof.s == nil {
+		return false, errors.New("VRF proof is nil")
+	}
+	if pk == nil || !pk.PointValid() || !isCanonical(pk) {
+		return false, errors.New("VRF invalid public key")
+	}
+	if new(crypto.Point).MulCofactor(pk).IsIdentity() {
+		return false, errors.New("VRF public key has small order")
+	}
+	if !proof.gamma.PointValid() || !isCanonical(proof.gamma) || !proof.s.ScalarValid() {
+		return false, errors.New("VRF invalid proof")
+	}
+
+	h, err := encodeToCurve(pk, alpha)
+	if err != nil {
+		return false, err
+	}
+	cNeg := new(crypto.Scalar).Sub(new(crypto.Scalar).FromUint64(0), proof.c)
+	u := new(crypto.Point).DoubleScalarMultBaseVartime(proof.s, pk, cNeg)
+	v, err := new(crypto.Point).CheckedMultiScalarMult([]*crypto.Scalar{proof.s, cNeg}, []*crypto.Point{h, proof.gamma})
+	if err != nil {
+		return false, err
+	}
+
+	c := challenge(pk, h, proof.gamma, u, v)
+	return crypto.CompareScalar(c, proof.c) == 0, nil
+}
+
+// Hash returns the VRF output beta of the proof, it is only meaningful once Verify accepted the proof
+func (proof Proof) Hash() []byte {
+	msg := []byte{suiteString, outputSeparator}
+	msg = append(msg, new(crypto.Point).MulCofactor(proof.gamma).ToBytes()...)
+	msg = append(msg, endSeparator)
+	digest := sha512.Sum512(msg)
+	return digest[:]
+}
+
+// Gamma returns x*H, the point which determines the VRF output
+func (proof Proof) Gamma() *crypto.Point {
+	return new(crypto.Point).Set(proof.gamma)
+}
+
+// Bytes returns pi_string = Gamma || c || s, with c in 16 bytes
+func (proof Proof) Bytes() []byte {
+	res := make([]byte, 0, ProofSize)
+	res = append(res, proof.gamma.ToBytes()...)
+	res = append(res, proof.c.ToBytes()[:challengeSize]...)
+	return append(res, proof.s.ToBytes()...)
+}
+
+func (proof *Proof) SetBytes(bytes []byte) error {
+	if len(bytes) != ProofSize {
+		return errors.New("VRF proof invalid size")
+	}
+	gamma, err := new(crypto.Point).FromBytes(bytes[:crypto.Ed25519KeySize])
+	if err != nil {
+		return err
+	}
+	c, err := new(crypto.Scalar).FromBytes(bytes[crypto.Ed25519KeySize : crypto.Ed25519KeySize+challengeSize])
+	if err != nil {
+		return err
+	}
+	s, err := new(crypto.Scalar).FromBytes(bytes[crypto.Ed25519KeySize+challengeSize:])
+	if err != nil {
+		return err
+	}
+	proof.gamma = gamma
+	proof.c = c
+	proof.s = s
+	return nil
+}
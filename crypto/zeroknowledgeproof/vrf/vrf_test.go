@@ -0,0 +1,130 @@
+package vrf
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/incognitochain/incognito-chain-privacy/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecodeHex(s string) []byte {
+	res, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// test vectors of RFC 9381 appendix B.3, ECVRF-EDWARDS25519-SHA512-ELL2
+func TestVRF_RFCVectors(t *testing.T) {
+	vectors := []struct {
+		sk, pk, alpha, pi, beta string
+	}{
+		{
+			sk:    "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60",
+			pk:    "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a",
+			alpha: "",
+			pi:    "7d9c633ffeee27349264cf5c667579fc583b4bda63ab71d001f89c10003ab46f14adf9a3cd8b8412d9038531e865c341cafa73589b023d14311c331a9ad15ff2fb37831e00f0acaa6d73bc9997b06501",
+			beta:  "9d574bf9b8302ec0fc1e21c3ec5368269527b87b462ce36dab2d14ccf80c53cccf6758f058c5b1c856b116388152bbe509ee3b9ecfe63d93c3b4346c1fbc6c54",
+		},
+		{
+			sk:    "4ccd089b28ff96da9db6c346ec114e0f5b8a319f35aba624da8cf6ed4fb8a6fb",
+			pk:    "3d4017c3e843895a92b70aa74d1b7ebc9c982ccf2ec4968cc0cd55f12af4660c",
+			alpha: "72",
+			pi:    "47b327393ff2dd81336f8a2ef10339112401253b3c714eeda879f12c509072ef055b48372bb82efbdce8e10c8cb9a2f9d60e93908f93df1623ad78a86a028d6bc064dbfc75a6a57379ef855dc6733801",
+			beta:  "38561d6b77b71d30eb97a062168ae12b667ce5c28caccdf76bc88e093e4635987cd96814ce55b4689b3dd2947f80e59aac7b7675f8083865b46c89b2ce9cc735",
+		},
+		{
+			sk:    "c5aa8df43f9f837bedb7442f31dcb7b166d38535076f094b85ce3a2e0b4458f7",
+			pk:    "fc51cd8e6218a1a38da47ed00230f0580816ed13ba3303ac5deb911548908025",
+			alpha: "af82",
+			pi:    "926e895d308f5e328e7aa159c06eddbe56d06846abf5d98c2512235eaa57fdce35b46edfc655bc828d44ad09d1150f31374e7ef73027e14760d42e77341fe05467bb286cc2c9d7fde29120a0b2320d04",
+			beta:  "121b7f9b9aaaa29099fc04a94ba52784d44eac976dd1a3cca458733be5cd090a7b5fbd148444f17f8daf1fb55cb04b1ae85a626e30a54b4b0f8abf4a43314a58",
+		},
+	}
+
+	for _, vector := range vectors {
+		priv, err := NewPrivateKey(mustDecodeHex(vector.sk))
+		assert.Equal(t, nil, err)
+		assert.Equal(t, mustDecodeHex(vector.pk), priv.PublicKey().ToBytes())
+
+		alpha := mustDecodeHex(vector.alpha)
+		proof, err := priv.Prove(alpha)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, mustDecodeHex(vector.pi), proof.Bytes())
+
+		received := new(Proof)
+		assert.Equal(t, nil, received.SetBytes(mustDecodeHex(vector.pi)))
+		pk, _ := new(crypto.Point).FromBytes(mustDecodeHex(vector.pk))
+		res, err := received.Verify(pk, alpha)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, true, res)
+		assert.Equal(t, mustDecodeHex(vector.beta), received.Hash())
+	}
+}
+
+func TestVRF_Invalid(t *testing.T) {
+	priv, _ := NewPrivateKey(crypto.RandBytes(SeedSize))
+	alpha := []byte("committee epoch 1")
+	proof, _ := priv.Prove(alpha)
+
+	// wrong input and wrong key
+	res, err := proof.Verify(priv.PublicKey(), []byte("committee epoch 2"))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, false, res)
+	res, _ = proof.Verify(crypto.RandomPoint(), alpha)
+	assert.Equal(t, false, res)
+
+	// tampered proofs
+	for _, i := range []int{0, 40, 70} {
+		b := proof.Bytes()
+		b[i] ^= 1
+		tampered := new(Proof)
+		if tampered.SetBytes(b) != nil {
+			continue
+		}
+		res, _ = tampered.Verify(priv.PublicKey(), alpha)
+		assert.Equal(t, false, res)
+	}
+
+	// a small order public key is rejected
+	_, err = proof.Verify(new(crypto.Point).Identity(), alpha)
+	assert.NotEqual(t, nil, err)
+
+	// s must be reduced
+	b := proof.Bytes()
+	for i := range b[48:] {
+		b[48+i] = 0xff
+	}
+	assert.NotEqual(t, nil, new(Proof).SetBytes(b))
+	assert.NotEqual(t, nil, new(Proof).SetBytes(b[:ProofSize-1]))
+
+	_, err = NewPrivateKey(make([]byte, 31))
+	assert.NotEqual(t, nil, err)
+}
+
+func TestVRF_FromScalar(t *testing.T) {
+	sk := crypto.RandomScalar()
+	priv := NewPrivateKeyFromScalar(sk)
+	alpha := []byte("round 7")
+	proof1, err := priv.Prove(alpha)
+	assert.Equal(t, nil, err)
+	proof2, _ := priv.Prove(alpha)
+	assert.Equal(t, proof1.Bytes(), proof2.Bytes())
+
+	res, err := proof1.Verify(new(crypto.Point).ScalarMultBase(sk), alpha)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, true, res)
+
+	// the output only depends on the key and the input
+	gamma := new(crypto.Point).ScalarMult(encodeToCurveMust(t, priv.PublicKey(), alpha), sk)
+	assert.Equal(t, true, crypto.IsPointEqual(gamma, proof1.Gamma()))
+	assert.Equal(t, OutputSize, len(proof1.Hash()))
+}
+
+func encodeToCurveMust(t *testing.T, pk *crypto.Point, alpha []byte) *crypto.Point {
+	h, err := encodeToCurve(pk, alpha)
+	assert.Equal(t, nil, err)
+	return h
+}